@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/config"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+)
+
+// Перевод рецептов, сохраненных в Markdown до появления колонки document, в JSON-документы.
+// Запускается один раз после обновления; повторный запуск обрабатывает только оставшиеся рецепты:
+//
+//	backfill-recipes
+func main() {
+	migrations := flag.String("migrations", "migrations", "каталог миграций")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	var logger *zap.Logger
+	if cfg.AppEnvironment == "development" {
+		logger, _ = zap.NewDevelopment()
+	} else {
+		logger, _ = zap.NewProduction()
+	}
+	defer logger.Sync()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	dbManager, err := database.NewDBManager(ctx, cfg.PostgresURI, logger)
+	if err != nil {
+		logger.Fatal("Database connection failed", zap.Error(err))
+	}
+	defer dbManager.Close()
+
+	if err := dbManager.RunMigrations(*migrations); err != nil {
+		logger.Fatal("Migration failed", zap.Error(err))
+	}
+
+	converted, err := dbManager.BackfillRecipeDocuments(ctx, func(title, content string) ([]byte, error) {
+		return recipes.EncodeDocument(recipes.ParseMarkdown(title, content))
+	}, recipes.SchemaVersion)
+	if err != nil {
+		logger.Fatal("Recipe document backfill failed", zap.Int("converted", converted), zap.Error(err))
+	}
+
+	logger.Info("Recipe document backfill finished", zap.Int("recipes", converted))
+}
@@ -6,13 +6,18 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/TelegramBot/recipe-recognition-bot/internal/bot"
 	"github.com/TelegramBot/recipe-recognition-bot/internal/config"
 	"github.com/TelegramBot/recipe-recognition-bot/internal/database"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/imageprep"
 	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/reminders"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/resilience"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/speech"
 	"github.com/TelegramBot/recipe-recognition-bot/internal/vision"
 )
 
@@ -47,14 +52,132 @@ func main() {
 		logger.Fatal("Migration failed", zap.Error(err))
 	}
 
+	llmPolicy := resilience.Policy{
+		MaxAttempts:      cfg.LLMMaxAttempts,
+		BaseDelay:        cfg.LLMBaseDelay,
+		MaxDelay:         cfg.LLMMaxDelay,
+		CallTimeout:      cfg.LLMCallTimeout,
+		FailureThreshold: cfg.LLMBreakerThreshold,
+		OpenTimeout:      cfg.LLMBreakerCooldown,
+	}
+
+	var visionService vision.Recognizer
+	visionService, err = vision.New(cfg.VisionProvider, vision.Options{
+		APIKey:           cfg.VisionAPIKey,
+		BaseURL:          cfg.VisionBaseURL,
+		Model:            cfg.VisionModel,
+		MaxTokens:        cfg.VisionMaxTokens,
+		FixtureItems:     cfg.VisionFixtureItems,
+		Resilience:       llmPolicy,
+		StructuredOutput: cfg.VisionStructured,
+	}, logger)
+	if err != nil {
+		logger.Fatal("Vision backend creation failed", zap.Error(err))
+	}
+	logger.Info("Vision backend selected", zap.String("provider", cfg.VisionProvider))
+
+	// Повторно присланные фото распознаются по кэшу без обращения к модели
+	if cfg.RecognitionCacheEnabled {
+		cachingVision := vision.NewCachingRecognizer(visionService, dbManager, vision.CacheOptions{
+			TTL:         cfg.RecognitionCacheTTL,
+			MaxDistance: cfg.RecognitionCacheMaxDistance,
+		}, logger)
+		go cachingVision.Run(ctx, time.Hour)
+		visionService = cachingVision
+	}
+
+	// Изображения поворачиваются по EXIF, уменьшаются и очищаются от метаданных до отправки модели
+	imageOptions := imageprep.Options{
+		MaxDimension: cfg.ImageMaxDimension,
+		Quality:      cfg.ImageJPEGQuality,
+	}
+	visionService = vision.NewPreprocessingRecognizer(visionService, imageOptions, logger)
+
+	// Штрихкоды ищутся на исходном изображении: после уменьшения тонкие полосы сливаются
+	if cfg.BarcodeScanEnabled {
+		visionService = vision.NewBarcodeRecognizer(visionService, dbManager, logger)
+	}
+
+	transcriber, err := speech.New(cfg.SpeechProvider, speech.Options{
+		APIKey:      cfg.SpeechAPIKey,
+		BaseURL:     cfg.SpeechBaseURL,
+		Model:       cfg.SpeechModel,
+		Language:    cfg.SpeechLanguage,
+		FixtureText: cfg.SpeechFixtureText,
+		Resilience:  llmPolicy,
+	}, logger)
+	if err != nil {
+		logger.Fatal("Speech backend creation failed", zap.Error(err))
+	}
+	logger.Info("Speech backend selected", zap.String("provider", cfg.SpeechProvider))
+
+	recipeOptions := recipes.Options{
+		APIKey:           cfg.RecipeAPIKey,
+		BaseURL:          cfg.RecipeBaseURL,
+		Model:            cfg.RecipeModel,
+		Temperature:      cfg.RecipeTemperature,
+		MaxTokens:        cfg.RecipeMaxTokens,
+		Resilience:       llmPolicy,
+		StructuredOutput: cfg.RecipeStructured,
+	}
+
+	var recipeGenerator recipes.Generator
+	if len(cfg.RecipeModelChain) > 0 {
+		specs, err := recipes.ParseModelSpecs(cfg.RecipeModelChain)
+		if err != nil {
+			logger.Fatal("Invalid recipe model chain", zap.Error(err))
+		}
+		recipeGenerator, err = recipes.NewChainGenerator(cfg.RecipeProvider, recipeOptions, specs, logger)
+		if err != nil {
+			logger.Fatal("Recipe backend creation failed", zap.Error(err))
+		}
+		logger.Info("Recipe model chain configured", zap.Strings("chain", cfg.RecipeModelChain))
+	} else {
+		recipeGenerator, err = recipes.New(cfg.RecipeProvider, recipeOptions, logger)
+		if err != nil {
+			logger.Fatal("Recipe backend creation failed", zap.Error(err))
+		}
+	}
+	logger.Info("Recipe backend selected", zap.String("provider", cfg.RecipeProvider))
+
+	// Рецепты для уже встречавшихся наборов продуктов отдаются из кэша
+	if cfg.RecipeCacheEnabled {
+		cachingGenerator := recipes.NewCachingGenerator(recipeGenerator, dbManager, recipes.CacheOptions{
+			TTL:         cfg.RecipeCacheTTL,
+			MaxVariants: cfg.RecipeCacheMaxVariants,
+		}, logger)
+		go cachingGenerator.Run(ctx, time.Hour)
+		recipeGenerator = cachingGenerator
+	}
+
+	// Рецепты с продуктами, запрещенными диетой или аллергией пользователя, генерируются заново,
+	// в том числе взятые из кэша
+	recipeGenerator = recipes.NewValidatingGenerator(recipeGenerator, cfg.RecipeMaxRegenerations, logger)
+
 	// Запуск бота
 	b, err := bot.NewBot(
 		cfg.TelegramToken,
 		logger,
 		dbManager,
-		vision.NewOpenAIVision(cfg.OpenAIAPIKey, logger),
-		recipes.NewRecipeGenerator(cfg.OpenAIAPIKey, logger),
-		cfg.MaxRecipesPerUser,
+		visionService,
+		recipeGenerator,
+		transcriber,
+		bot.Options{
+			MaxRecipes:       cfg.MaxRecipesPerUser,
+			DraftTTL:         cfg.DraftTTL,
+			DialogTimeout:    cfg.DialogTimeout,
+			MaxVoiceDuration: cfg.VoiceMaxDuration,
+			AlbumWindow:      cfg.AlbumWindow,
+			MaxImageSize:     cfg.ImageMaxSize,
+			LowConfidence:    cfg.VisionLowConfidence,
+			AnnotateImages:   cfg.VisionAnnotate,
+			ImageOptions:     imageOptions,
+			ExpiryReminders:  cfg.ExpiryRemindersEnabled,
+			Reminders: reminders.Options{
+				At:   cfg.ExpiryReminderTime,
+				Days: cfg.ExpiryReminderDays,
+			},
+		},
 	)
 	if err != nil {
 		logger.Fatal("Bot creation failed", zap.Error(err))
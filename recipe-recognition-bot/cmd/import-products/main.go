@@ -0,0 +1,143 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/catalog"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/config"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database"
+)
+
+// Импорт справочника товаров по штрихкодам из CSV-выгрузки Open Food Facts:
+//
+//	import-products -file en.openfoodfacts.org.products.csv.gz -country en:russia
+func main() {
+	file := flag.String("file", "", "путь к выгрузке Open Food Facts (.csv или .csv.gz); - для стандартного ввода")
+	country := flag.String("country", "", "импортировать только товары, продающиеся в стране, например en:russia")
+	batchSize := flag.Int("batch", 1000, "сколько товаров сохранять одним запросом")
+	migrations := flag.String("migrations", "migrations", "каталог миграций")
+	flag.Parse()
+
+	if *file == "" || *batchSize <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	var logger *zap.Logger
+	if cfg.AppEnvironment == "development" {
+		logger, _ = zap.NewDevelopment()
+	} else {
+		logger, _ = zap.NewProduction()
+	}
+	defer logger.Sync()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	dbManager, err := database.NewDBManager(ctx, cfg.PostgresURI, logger)
+	if err != nil {
+		logger.Fatal("Database connection failed", zap.Error(err))
+	}
+	defer dbManager.Close()
+
+	if err := dbManager.RunMigrations(*migrations); err != nil {
+		logger.Fatal("Migration failed", zap.Error(err))
+	}
+
+	input, err := openDump(*file)
+	if err != nil {
+		logger.Fatal("Failed to open dump", zap.String("file", *file), zap.Error(err))
+	}
+	defer input.Close()
+
+	reader, err := catalog.NewOpenFoodFactsReader(input, catalog.OpenFoodFactsOptions{Country: *country})
+	if err != nil {
+		logger.Fatal("Failed to read dump header", zap.Error(err))
+	}
+
+	var (
+		batch    = make([]catalog.Product, 0, *batchSize)
+		imported int64
+	)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		count, err := dbManager.ImportProducts(ctx, batch)
+		if err != nil {
+			logger.Fatal("Failed to import products", zap.Int64("imported", imported), zap.Error(err))
+		}
+		imported += count
+		batch = batch[:0]
+		logger.Info("Products imported", zap.Int64("total", imported))
+	}
+
+	for {
+		product, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			logger.Fatal("Failed to read dump", zap.Int64("imported", imported), zap.Error(err))
+		}
+
+		batch = append(batch, product)
+		if len(batch) == *batchSize {
+			flush()
+		}
+	}
+	flush()
+
+	logger.Info("Import finished", zap.Int64("products", imported))
+}
+
+// openDump открывает выгрузку, при необходимости распаковывая gzip
+func openDump(path string) (io.ReadCloser, error) {
+	var file io.ReadCloser = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		file = f
+	}
+
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return readCloser{Reader: gz, close: func() error {
+		gz.Close()
+		return file.Close()
+	}}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r readCloser) Close() error {
+	return r.close()
+}
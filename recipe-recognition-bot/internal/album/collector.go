@@ -0,0 +1,75 @@
+package album
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxMessages - Telegram не присылает в одном альбоме больше 10 вложений
+const maxMessages = 10
+
+// FlushFunc получает все сообщения альбома в порядке поступления
+type FlushFunc func(ctx context.Context, messages []*tgbotapi.Message)
+
+// Collector собирает сообщения с общим MediaGroupID.
+// Telegram присылает каждое вложение альбома отдельным обновлением, поэтому альбом
+// считается полным, когда в течение window не пришло новых вложений.
+type Collector struct {
+	mu     sync.Mutex
+	window time.Duration
+	flush  FlushFunc
+	groups map[string]*group
+}
+
+type group struct {
+	ctx      context.Context
+	messages []*tgbotapi.Message
+	timer    *time.Timer
+}
+
+// NewCollector создает сборщик альбомов
+func NewCollector(window time.Duration, flush FlushFunc) *Collector {
+	return &Collector{
+		window: window,
+		flush:  flush,
+		groups: make(map[string]*group),
+	}
+}
+
+// Add добавляет сообщение в его альбом и откладывает обработку альбома еще на window
+func (c *Collector) Add(ctx context.Context, msg *tgbotapi.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := msg.MediaGroupID
+	g, ok := c.groups[id]
+	if !ok {
+		g = &group{ctx: ctx}
+		g.timer = time.AfterFunc(c.window, func() { c.complete(id) })
+		c.groups[id] = g
+	} else {
+		g.timer.Reset(c.window)
+	}
+
+	g.messages = append(g.messages, msg)
+	if len(g.messages) == maxMessages {
+		// Больше вложений не будет, ждать не нужно
+		g.timer.Stop()
+		delete(c.groups, id)
+		go c.flush(g.ctx, g.messages)
+	}
+}
+
+func (c *Collector) complete(id string) {
+	c.mu.Lock()
+	g, ok := c.groups[id]
+	delete(c.groups, id)
+	c.mu.Unlock()
+
+	if ok {
+		c.flush(g.ctx, g.messages)
+	}
+}
@@ -0,0 +1,176 @@
+package barcode
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	// eanRuns - число полос и промежутков в EAN-13 от начального до конечного ограничителя:
+	// 3 + 6 цифр по 4 + 5 + 6 цифр по 4 + 3
+	eanRuns = 59
+
+	// Допустимые отклонения ширин полос от образца в долях ширины модуля
+	maxAvgVariance        = 0.48
+	maxIndividualVariance = 0.7
+)
+
+var (
+	guardPattern  = []int{1, 1, 1}
+	middlePattern = []int{1, 1, 1, 1, 1}
+
+	// digitPatterns - ширины полос цифр набора L в модулях. Набор R имеет те же ширины
+	// (начинается с полосы, а не с промежутка), набор G - те же ширины в обратном порядке.
+	digitPatterns = [10][]int{
+		{3, 2, 1, 1},
+		{2, 2, 2, 1},
+		{2, 1, 2, 2},
+		{1, 4, 1, 1},
+		{1, 1, 3, 2},
+		{1, 2, 3, 1},
+		{1, 1, 1, 4},
+		{1, 3, 1, 2},
+		{1, 2, 1, 3},
+		{3, 1, 1, 2},
+	}
+
+	// firstDigitParity - по какому набору закодированы цифры левой половины (бит 1 - G, старший бит - первая цифра).
+	// Так в EAN-13 кодируется первая цифра, у которой нет своих полос; UPC-A - это EAN-13 с первой цифрой 0.
+	firstDigitParity = [10]int{
+		0b000000, 0b001011, 0b001101, 0b001110, 0b010011,
+		0b011001, 0b011100, 0b010101, 0b010110, 0b011010,
+	}
+)
+
+// Normalize приводит код к 13 цифрам EAN-13: UPC-A дополняется ведущим нулем.
+// false - строка не является корректным кодом EAN-13 или UPC-A.
+func Normalize(code string) (string, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) == 12 {
+		code = "0" + code
+	}
+	if len(code) != 13 || strings.Trim(code, "0123456789") != "" {
+		return "", false
+	}
+	return code, validChecksum(code)
+}
+
+// validChecksum проверяет контрольную цифру: цифры на четных позициях берутся с весом 3
+func validChecksum(code string) bool {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		digit := int(code[i] - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return (10-sum%10)%10 == int(code[12]-'0')
+}
+
+// decodeEAN13 пытается прочитать код, начальный ограничитель которого - полоса runs[start].
+// runs[start-1] - светлая зона перед кодом.
+func decodeEAN13(runs []int, start int) (string, bool) {
+	if start < 1 || start+eanRuns > len(runs) {
+		return "", false
+	}
+	if patternVariance(runs[start:], guardPattern) > maxAvgVariance {
+		return "", false
+	}
+	// Перед кодом должно быть свободное поле; настоящее - 11 модулей, но край фото может его обрезать
+	module := float64(runs[start]+runs[start+1]+runs[start+2]) / 3
+	if float64(runs[start-1]) < 3*module {
+		return "", false
+	}
+
+	code := make([]byte, 13)
+	parity := 0
+	for i := 0; i < 6; i++ {
+		digit, g, ok := decodeDigit(runs[start+3+i*4:], true)
+		if !ok {
+			return "", false
+		}
+		code[i+1] = byte('0' + digit)
+		parity <<= 1
+		if g {
+			parity |= 1
+		}
+	}
+
+	if patternVariance(runs[start+27:], middlePattern) > maxAvgVariance {
+		return "", false
+	}
+
+	for i := 0; i < 6; i++ {
+		digit, _, ok := decodeDigit(runs[start+32+i*4:], false)
+		if !ok {
+			return "", false
+		}
+		code[i+7] = byte('0' + digit)
+	}
+
+	if patternVariance(runs[start+56:], guardPattern) > maxAvgVariance {
+		return "", false
+	}
+
+	first := -1
+	for digit, p := range firstDigitParity {
+		if p == parity {
+			first = digit
+			break
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+	code[0] = byte('0' + first)
+
+	if !validChecksum(string(code)) {
+		return "", false
+	}
+	return string(code), true
+}
+
+// decodeDigit находит цифру, ширины полос которой ближе всего к образцу.
+// В левой половине допускается и набор G; g сообщает, что цифра закодирована им.
+func decodeDigit(runs []int, left bool) (digit int, g bool, ok bool) {
+	best := maxAvgVariance
+	digit = -1
+	for d, pattern := range digitPatterns {
+		if v := patternVariance(runs, pattern); v < best {
+			best, digit, g = v, d, false
+		}
+		if !left {
+			continue
+		}
+		reversed := []int{pattern[3], pattern[2], pattern[1], pattern[0]}
+		if v := patternVariance(runs, reversed); v < best {
+			best, digit, g = v, d, true
+		}
+	}
+	return digit, g, digit >= 0
+}
+
+// patternVariance - насколько ширины полос отличаются от образца: 0 - точное совпадение.
+// Ширина модуля оценивается по самим полосам, поэтому масштаб и перспектива не мешают сравнению.
+func patternVariance(runs []int, pattern []int) float64 {
+	total, modules := 0, 0
+	for i, p := range pattern {
+		total += runs[i]
+		modules += p
+	}
+	if total == 0 {
+		return math.Inf(1)
+	}
+
+	unit := float64(total) / float64(modules)
+	variance := 0.0
+	for i, p := range pattern {
+		diff := math.Abs(float64(runs[i]) - float64(p)*unit)
+		if diff > maxIndividualVariance*unit {
+			return math.Inf(1)
+		}
+		variance += diff
+	}
+	return variance / float64(total)
+}
@@ -0,0 +1,52 @@
+package barcode
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		want   string
+		wantOK bool
+	}{
+		{"EAN-13", "4006381333931", "4006381333931", true},
+		{"EAN-13 с пробелами", " 4600682000389\n", "4600682000389", true},
+		{"UPC-A дополняется до EAN-13", "036000291452", "0036000291452", true},
+		{"неверная контрольная цифра", "4006381333932", "", false},
+		{"неверная контрольная цифра UPC-A", "036000291453", "", false},
+		{"буквы", "40063813339X1", "", false},
+		{"короткий код", "40063813", "", false},
+		{"длинный код", "40063813339310", "", false},
+		{"пустая строка", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Normalize(tt.code)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("Normalize(%q) = %q, %v; want %q, %v", tt.code, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidChecksum(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"4006381333931", true},
+		{"0036000291452", true},
+		{"5901234123457", true},
+		// Контрольная цифра 0: сумма кратна 10
+		{"4007817327340", true},
+		{"5901234123458", false},
+		{"4006381333930", false},
+	}
+
+	for _, tt := range tests {
+		if got := validChecksum(tt.code); got != tt.want {
+			t.Errorf("validChecksum(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"slices"
+)
+
+const (
+	// scanLines - сколько строк и столбцов изображения просматривается
+	scanLines = 64
+	// minHits - в скольких линиях код должен прочитаться одинаково, чтобы считаться найденным
+	minHits = 2
+)
+
+// Scan ищет на изображении штрихкоды EAN-13 и UPC-A и возвращает их в виде 13 цифр
+// в порядке обнаружения. Изображение просматривается по строкам и столбцам в обе стороны,
+// поэтому поворот фото на 90 или 180 градусов не мешает.
+func Scan(img image.Image) []string {
+	bounds := img.Bounds()
+	hits := make(map[string]int)
+	var found []string
+
+	scan := func(line []uint8) {
+		for _, code := range scanLine(line) {
+			hits[code]++
+			if hits[code] == minHits {
+				found = append(found, code)
+			}
+		}
+	}
+
+	for i := 1; i <= scanLines; i++ {
+		y := bounds.Min.Y + bounds.Dy()*i/(scanLines+1)
+		scan(luminanceLine(img, image.Pt(bounds.Min.X, y), image.Pt(1, 0), bounds.Dx()))
+
+		x := bounds.Min.X + bounds.Dx()*i/(scanLines+1)
+		scan(luminanceLine(img, image.Pt(x, bounds.Min.Y), image.Pt(0, 1), bounds.Dy()))
+	}
+	return found
+}
+
+// scanLine читает коды вдоль одной линии в прямом и обратном направлении
+func scanLine(line []uint8) []string {
+	dark := binarize(line)
+	codes := decodeRuns(dark)
+
+	slices.Reverse(dark)
+	return append(codes, decodeRuns(dark)...)
+}
+
+// decodeRuns разбивает линию на полосы и промежутки и ищет коды, начинающиеся с каждой полосы
+func decodeRuns(dark []bool) []string {
+	if len(dark) == 0 {
+		return nil
+	}
+
+	var runs []int
+	length := 1
+	for i := 1; i < len(dark); i++ {
+		if dark[i] == dark[i-1] {
+			length++
+			continue
+		}
+		runs = append(runs, length)
+		length = 1
+	}
+	runs = append(runs, length)
+
+	// Полосы - нечетные или четные элементы runs в зависимости от цвета первого пикселя
+	first := 1
+	if dark[0] {
+		first = 2
+	}
+
+	var codes []string
+	for start := first; start+eanRuns <= len(runs); start += 2 {
+		if code, ok := decodeEAN13(runs, start); ok {
+			codes = append(codes, code)
+			start += eanRuns - 1
+		}
+	}
+	return codes
+}
+
+// binarize отделяет темные пиксели от светлых по средней яркости окрестности:
+// порог следует за освещением, которое на фото редко бывает равномерным
+func binarize(line []uint8) []bool {
+	n := len(line)
+	radius := max(8, n/32)
+
+	prefix := make([]int, n+1)
+	for i, v := range line {
+		prefix[i+1] = prefix[i] + int(v)
+	}
+
+	dark := make([]bool, n)
+	for i, v := range line {
+		lo, hi := max(0, i-radius), min(n, i+radius+1)
+		mean := (prefix[hi] - prefix[lo]) / (hi - lo)
+		dark[i] = int(v) < mean
+	}
+	return dark
+}
+
+// luminanceLine возвращает яркость length пикселей, начиная с from, с шагом step
+func luminanceLine(img image.Image, from, step image.Point, length int) []uint8 {
+	line := make([]uint8, length)
+
+	// JPEG декодируется в YCbCr: яркость берется напрямую, без преобразования цвета
+	if ycbcr, ok := img.(*image.YCbCr); ok {
+		for i := range line {
+			p := from.Add(step.Mul(i))
+			line[i] = ycbcr.Y[ycbcr.YOffset(p.X, p.Y)]
+		}
+		return line
+	}
+
+	for i := range line {
+		p := from.Add(step.Mul(i))
+		line[i] = color.GrayModel.Convert(img.At(p.X, p.Y)).(color.Gray).Y
+	}
+	return line
+}
@@ -0,0 +1,145 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+// eanModules кодирует EAN-13 в последовательность модулей (true - полоса) со свободными полями по 11 модулей
+func eanModules(code string) []bool {
+	var modules []bool
+	add := func(dark bool, widths ...int) {
+		for _, w := range widths {
+			for i := 0; i < w; i++ {
+				modules = append(modules, dark)
+			}
+			dark = !dark
+		}
+	}
+
+	add(false, 11)
+	add(true, guardPattern...)
+	parity := firstDigitParity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		p := digitPatterns[code[i]-'0']
+		if parity>>(6-i)&1 == 1 {
+			p = []int{p[3], p[2], p[1], p[0]}
+		}
+		add(false, p...)
+	}
+	add(false, middlePattern...)
+	for i := 7; i <= 12; i++ {
+		add(true, digitPatterns[code[i]-'0']...)
+	}
+	add(true, guardPattern...)
+	add(false, 11)
+	return modules
+}
+
+// renderEAN13 рисует код черными полосами на белом фоне
+func renderEAN13(code string, moduleWidth, height int) *image.Gray {
+	modules := eanModules(code)
+	img := image.NewGray(image.Rect(0, 0, len(modules)*moduleWidth, height))
+	for x := range img.Bounds().Dx() {
+		c := uint8(255)
+		if modules[x/moduleWidth] {
+			c = 0
+		}
+		for y := range height {
+			img.SetGray(x, y, color.Gray{Y: c})
+		}
+	}
+	return img
+}
+
+// rotate90 поворачивает изображение на 90 градусов по часовой стрелке
+func rotate90(src *image.Gray) *image.Gray {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewGray(image.Rect(0, 0, h, w))
+	for y := range h {
+		for x := range w {
+			dst.SetGray(h-1-y, x, src.GrayAt(x, y))
+		}
+	}
+	return dst
+}
+
+// rotate180 поворачивает изображение на 180 градусов
+func rotate180(src *image.Gray) *image.Gray {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewGray(src.Bounds())
+	for y := range h {
+		for x := range w {
+			dst.SetGray(w-1-x, h-1-y, src.GrayAt(x, y))
+		}
+	}
+	return dst
+}
+
+// degrade добавляет шум и неравномерное освещение: яркость падает к правому краю
+func degrade(src *image.Gray) *image.Gray {
+	rng := rand.New(rand.NewPCG(1, 2))
+	w := src.Bounds().Dx()
+	dst := image.NewGray(src.Bounds())
+	for y := range src.Bounds().Dy() {
+		for x := range w {
+			v := float64(src.GrayAt(x, y).Y)*0.6 + 40 - float64(x)*30/float64(w)
+			v += rng.NormFloat64() * 12
+			dst.SetGray(x, y, color.Gray{Y: uint8(min(255, max(0, v)))})
+		}
+	}
+	return dst
+}
+
+// onCanvas помещает изображение в середину большего серого поля
+func onCanvas(src *image.Gray, margin int) *image.Gray {
+	b := src.Bounds()
+	dst := image.NewGray(image.Rect(0, 0, b.Dx()+2*margin, b.Dy()+2*margin))
+	for i := range dst.Pix {
+		dst.Pix[i] = 200
+	}
+	for y := range b.Dy() {
+		for x := range b.Dx() {
+			dst.SetGray(x+margin, y+margin, src.GrayAt(x, y))
+		}
+	}
+	return dst
+}
+
+func TestScan(t *testing.T) {
+	const ean = "4006381333931"
+
+	tests := []struct {
+		name string
+		img  image.Image
+		want []string
+	}{
+		{"EAN-13", renderEAN13(ean, 3, 60), []string{ean}},
+		{"UPC-A", renderEAN13("0036000291452", 2, 40), []string{"0036000291452"}},
+		{"первая цифра 9", renderEAN13("9780201379624", 3, 40), []string{"9780201379624"}},
+		{"повернут на 90 градусов", rotate90(renderEAN13(ean, 3, 60)), []string{ean}},
+		{"повернут на 180 градусов", rotate180(renderEAN13(ean, 3, 60)), []string{ean}},
+		{"шум и неравномерный свет", degrade(renderEAN13(ean, 4, 80)), []string{ean}},
+		{"код на фоне", onCanvas(renderEAN13(ean, 3, 60), 50), []string{ean}},
+		{"подызображение", onCanvas(renderEAN13(ean, 3, 60), 50).SubImage(image.Rect(20, 20, 400, 150)), []string{ean}},
+		{"без кода", image.NewGray(image.Rect(0, 0, 300, 100)), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Scan(tt.img); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanRejectsBadChecksum(t *testing.T) {
+	// Полосы нарисованы правильно, но контрольная цифра не сходится
+	if got := Scan(renderEAN13("4006381333932", 3, 60)); len(got) != 0 {
+		t.Errorf("Scan() = %v, want no codes", got)
+	}
+}
@@ -1,52 +1,129 @@
 package bot
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
 
+	"github.com/TelegramBot/recipe-recognition-bot/internal/album"
 	"github.com/TelegramBot/recipe-recognition-bot/internal/database"
 	dbmodels "github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/dialog"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/drafts"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/imageprep"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
 	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/reminders"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/speech"
 	"github.com/TelegramBot/recipe-recognition-bot/internal/vision"
 )
 
+// Options содержит ограничения и таймауты бота
+type Options struct {
+	// MaxRecipes - сколько сохраненных рецептов показывать пользователю
+	MaxRecipes int
+	// DraftTTL - сколько несохраненный рецепт доступен для сохранения и перегенерации
+	DraftTTL time.Duration
+	// DialogTimeout - через сколько бездействия сбрасывается многошаговый диалог
+	DialogTimeout time.Duration
+	// MaxVoiceDuration - голосовые сообщения длиннее не распознаются
+	MaxVoiceDuration time.Duration
+	// MaxImageSize - изображения больше этого размера в байтах не загружаются
+	MaxImageSize int64
+	// AlbumWindow - сколько ждать следующее фото альбома, прежде чем распознавать альбом целиком
+	AlbumWindow time.Duration
+	// LowConfidence - продукты, распознанные с меньшей уверенностью, помечаются знаком вопроса
+	LowConfidence float64
+	// AnnotateImages - отправлять фото с рамками вокруг распознанных продуктов
+	AnnotateImages bool
+	// ImageOptions - подготовка фото с рамками: размер и качество JPEG
+	ImageOptions imageprep.Options
+	// ExpiryReminders - ежедневно напоминать о продуктах с истекающим сроком годности
+	ExpiryReminders bool
+	// Reminders - время рассылки напоминаний и за сколько дней до конца срока напоминать
+	Reminders reminders.Options
+}
+
+// maxParallelRecognitions - сколько фото альбома распознается одновременно
+const maxParallelRecognitions = 3
+
 // Bot представляет телеграм-бота
 type Bot struct {
-	api             *tgbotapi.BotAPI
-	logger          *zap.Logger
-	dbManager       *database.DBManager
-	visionService   *vision.OpenAIVision
-	recipeGenerator *recipes.RecipeGenerator
-	maxRecipes      int
+	api              *tgbotapi.BotAPI
+	logger           *zap.Logger
+	dbManager        *database.DBManager
+	visionService    vision.Recognizer
+	recipeGenerator  recipes.Generator
+	transcriber      speech.Transcriber
+	maxRecipes       int
+	maxVoiceDuration time.Duration
+	maxImageSize     int64
+	lowConfidence    float64
+	annotateImages   bool
+	imageOptions     imageprep.Options
+	expiryDays       int
+	drafts           *drafts.Store
+	dialogs          *dialog.Manager
+	albums           *album.Collector
+	// reminders - nil, если напоминания о сроках годности выключены
+	reminders *reminders.Scheduler
 }
 
 // NewBot создает новый экземпляр бота
 func NewBot(token string, logger *zap.Logger, dbManager *database.DBManager,
-	visionService *vision.OpenAIVision, recipeGenerator *recipes.RecipeGenerator,
-	maxRecipes int) (*Bot, error) {
+	visionService vision.Recognizer, recipeGenerator recipes.Generator,
+	transcriber speech.Transcriber, opts Options) (*Bot, error) {
 
-	bot, err := tgbotapi.NewBotAPI(token)
+	api, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create BotAPI: %w", err)
 	}
 
-	return &Bot{
-		api:             bot,
-		logger:          logger,
-		dbManager:       dbManager,
-		visionService:   visionService,
-		recipeGenerator: recipeGenerator,
-		maxRecipes:      maxRecipes,
-	}, nil
+	return newBot(api, logger, dbManager, visionService, recipeGenerator, transcriber, opts), nil
+}
+
+// newBot собирает бота вокруг готового клиента Telegram API
+func newBot(api *tgbotapi.BotAPI, logger *zap.Logger, dbManager *database.DBManager,
+	visionService vision.Recognizer, recipeGenerator recipes.Generator,
+	transcriber speech.Transcriber, opts Options) *Bot {
+
+	b := &Bot{
+		api:              api,
+		logger:           logger,
+		dbManager:        dbManager,
+		visionService:    visionService,
+		recipeGenerator:  recipeGenerator,
+		transcriber:      transcriber,
+		maxRecipes:       opts.MaxRecipes,
+		maxVoiceDuration: opts.MaxVoiceDuration,
+		maxImageSize:     opts.MaxImageSize,
+		lowConfidence:    opts.LowConfidence,
+		annotateImages:   opts.AnnotateImages,
+		imageOptions:     opts.ImageOptions,
+		expiryDays:       opts.Reminders.Days,
+		drafts:           drafts.NewStore(opts.DraftTTL),
+		dialogs:          dialog.NewManager(dbManager, opts.DialogTimeout, logger),
+	}
+
+	b.albums = album.NewCollector(opts.AlbumWindow, b.recognizePhotos)
+	if opts.ExpiryReminders {
+		b.reminders = reminders.NewScheduler(dbManager, b.sendExpiryReminder, opts.Reminders, reminders.SystemClock, logger)
+	}
+
+	// Многошаговые сценарии
+	b.dialogs.Register(b.newProductsFlow())
+	b.dialogs.Register(b.newPreferencesFlow())
+
+	return b
 }
 
 // Start запускает бота
@@ -59,10 +136,25 @@ func (b *Bot) Start(ctx context.Context) error {
 		tgbotapi.BotCommand{Command: "start", Description: "Начать работу с ботом"},
 		tgbotapi.BotCommand{Command: "help", Description: "Получить справку"},
 		tgbotapi.BotCommand{Command: "recipes", Description: "Просмотреть сохраненные рецепты"},
+		tgbotapi.BotCommand{Command: "cook", Description: "Рецепт из списка продуктов"},
+		tgbotapi.BotCommand{Command: "preferences", Description: "Диеты, аллергии и калорийность"},
+		tgbotapi.BotCommand{Command: "pantry", Description: "Запасы, которые всегда есть дома"},
+		tgbotapi.BotCommand{Command: "inventory", Description: "Продукты дома по распознанным фото"},
+		tgbotapi.BotCommand{Command: "expiry", Description: "Срок годности продукта дома"},
+		tgbotapi.BotCommand{Command: "shopping", Description: "Список покупок"},
 	))
 
 	updates := b.api.GetUpdatesChan(u)
 
+	// Очистка устаревших черновиков рецептов
+	go b.drafts.Run(ctx, time.Minute)
+	// Очистка истекших диалогов
+	go b.dialogs.Run(ctx, 10*time.Minute)
+	// Ежедневные напоминания о продуктах с истекающим сроком годности
+	if b.reminders != nil {
+		go b.reminders.Run(ctx)
+	}
+
 	b.logger.Info("Bot started")
 
 	for {
@@ -87,18 +179,43 @@ func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
 			b.handleHelpCommand(ctx, update)
 		case "recipes":
 			b.handleRecipesCommand(ctx, update)
+		case "cook":
+			b.handleCookCommand(ctx, update)
+		case "preferences":
+			b.handlePreferencesCommand(ctx, update)
+		case "pantry":
+			b.handlePantryCommand(ctx, update)
+		case "inventory":
+			b.handleInventoryCommand(ctx, update)
+		case "expiry":
+			b.handleExpiryCommand(ctx, update)
+		case "shopping":
+			b.handleShoppingCommand(ctx, update)
 		default:
 			b.handleUnknownCommand(ctx, update)
 		}
 		return
 	}
 
-	// Обработка фотографий
-	if update.Message != nil && update.Message.Photo != nil {
+	// Обработка фотографий, в том числе отправленных файлом без сжатия
+	if update.Message != nil && (update.Message.Photo != nil || isImageDocument(update.Message.Document)) {
 		b.handlePhotoMessage(ctx, update)
 		return
 	}
 
+	// Прочие файлы не поддерживаются
+	if update.Message != nil && update.Message.Document != nil {
+		b.api.Send(tgbotapi.NewMessage(update.Message.Chat.ID,
+			"Этот файл не похож на изображение. Отправьте фото продуктов в формате JPEG, PNG или WEBP."))
+		return
+	}
+
+	// Обработка голосовых сообщений
+	if update.Message != nil && update.Message.Voice != nil {
+		b.handleVoiceMessage(ctx, update)
+		return
+	}
+
 	// Обработка callback-запросов
 	if update.CallbackQuery != nil {
 		b.handleCallbackQuery(ctx, update)
@@ -113,9 +230,20 @@ func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
 		case "Мои рецепты":
 			b.handleRecipesCommand(ctx, update)
 		default:
-			msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-				"Отправьте фото продуктов или используйте команды (/help).")
-			b.api.Send(msg)
+			// Ответ на запрос активного диалога, например название продукта для списка
+			handled, err := b.dialogs.HandleMessage(ctx, update.Message)
+			if err != nil {
+				b.logger.Error("Dialog failed to handle message",
+					zap.Int64("chat_id", update.Message.Chat.ID), zap.Error(err))
+				b.api.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Произошла ошибка. Попробуйте снова."))
+				return
+			}
+			if handled {
+				return
+			}
+
+			// Иначе считаем сообщение списком продуктов
+			b.handleProductsText(ctx, update)
 		}
 	}
 }
@@ -131,10 +259,16 @@ func (b *Bot) handleStartCommand(ctx context.Context, update tgbotapi.Update) {
 	welcomeText := fmt.Sprintf(
 		"Здравствуйте, %s!\n\n"+
 			"Я бот для распознавания продуктов и генерации рецептов.\n\n"+
-			"Отправьте мне фотографию продуктов, и я предложу рецепт.\n\n"+
+			"Отправьте мне фотографию продуктов или перечислите их текстом, и я предложу рецепт.\n\n"+
 			"Команды:\n"+
 			"/help - справка\n"+
-			"/recipes - сохраненные рецепты",
+			"/cook - рецепт из списка продуктов\n"+
+			"/recipes - сохраненные рецепты\n"+
+			"/preferences - диеты, аллергии и калорийность\n"+
+			"/pantry - запасы, которые всегда есть дома\n"+
+			"/inventory - продукты дома\n"+
+			"/expiry - срок годности продукта\n"+
+			"/shopping - список покупок",
 		user.FirstName,
 	)
 
@@ -153,15 +287,24 @@ func (b *Bot) handleStartCommand(ctx context.Context, update tgbotapi.Update) {
 func (b *Bot) handleHelpCommand(ctx context.Context, update tgbotapi.Update) {
 	helpText := `*Как пользоваться ботом:*
 
-1. Отправьте фото продуктов
-2. Бот распознает продукты 
-3. Бот предложит рецепт
-4. Вы можете сохранить рецепт
+1. Отправьте фото продуктов, перечислите их текстом («яйца, помидоры, сыр») или продиктуйте голосовым сообщением
+2. Бот распознает продукты
+3. Уберите лишние продукты, добавьте недостающие и нажмите «Сгенерировать рецепт»
+4. Бот предложит рецепт
+5. Нажмите «Сохранить», чтобы добавить рецепт в /recipes, или попросите другой вариант
 
 *Команды:*
 /start - начать работу
 /help - справка
-/recipes - сохраненные рецепты`
+/cook яйца, сыр - рецепт из списка продуктов; без списка - из продуктов дома
+/inventory - продукты дома: пополняется подтвержденными списками с фото
+/expiry молоко 25.10 - срок годности продукта дома; о продуктах с истекающим сроком бот напомнит
+/recipes - сохраненные рецепты; из открытого рецепта недостающие продукты можно добавить в список покупок
+/shopping - список покупок: отметка купленного и очистка
+/preferences - диеты, аллергии и калорийность
+/pantry add соль, масло - запасы, которые всегда есть дома и доступны для любого рецепта
+
+Рецепты подбираются с учетом ваших ограничений питания, но проверка аллергенов словарная: перед приготовлением сверяйтесь с составом продуктов.`
 
 	var msg tgbotapi.MessageConfig
 	if update.CallbackQuery != nil {
@@ -182,6 +325,61 @@ func (b *Bot) handleUnknownCommand(ctx context.Context, update tgbotapi.Update)
 	b.api.Send(msg)
 }
 
+// handleCookCommand обрабатывает команду /cook: продукты можно указать сразу;
+// без списка рецепт предлагается из продуктов дома, а если их нет - продукты спрашиваются следующим сообщением
+func (b *Bot) handleCookCommand(ctx context.Context, update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	user := update.Message.From
+
+	dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+	if err != nil {
+		b.logger.Error("Failed to load user", zap.Int64("user_id", user.ID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	if args := update.Message.CommandArguments(); strings.TrimSpace(args) != "" {
+		items := ingredients.Parse(args)
+		if len(items) == 0 {
+			b.api.Send(tgbotapi.NewMessage(chatID,
+				"Не удалось разобрать список продуктов. Пример: /cook яйца, помидоры, сыр"))
+			return
+		}
+		err = b.startProductEditing(ctx, chatID, newChoices(items))
+	} else if started, inventoryErr := b.startInventoryEditing(ctx, chatID, dbUser.ID); inventoryErr != nil || !started {
+		if inventoryErr != nil {
+			b.logger.Warn("Failed to offer inventory", zap.Int32("user_id", dbUser.ID), zap.Error(inventoryErr))
+		}
+		err = b.askForProducts(ctx, chatID)
+	}
+
+	if err != nil {
+		b.logger.Error("Failed to start product editing", zap.Int64("chat_id", chatID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+	}
+}
+
+// handleProductsText разбирает обычное сообщение как список продуктов
+func (b *Bot) handleProductsText(ctx context.Context, update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	user := update.Message.From
+
+	items := ingredients.Parse(update.Message.Text)
+	if len(items) == 0 {
+		msg := tgbotapi.NewMessage(chatID,
+			"Отправьте фото продуктов, перечислите их через запятую или используйте команды (/help).")
+		b.api.Send(msg)
+		return
+	}
+
+	b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+
+	if err := b.startProductEditing(ctx, chatID, newChoices(items)); err != nil {
+		b.logger.Error("Failed to start product editing", zap.Int64("chat_id", chatID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+	}
+}
+
 // handleRecipesCommand обрабатывает команду /recipes
 func (b *Bot) handleRecipesCommand(ctx context.Context, update tgbotapi.Update) {
 	var user *tgbotapi.User
@@ -233,6 +431,14 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, update tgbotapi.Update) {
 	chatID := update.CallbackQuery.Message.Chat.ID
 	user := update.CallbackQuery.From
 
+	// Кнопки многошаговых диалогов
+	if handled, err := b.dialogs.HandleCallback(ctx, update.CallbackQuery); handled {
+		if err != nil {
+			b.handleDialogCallbackError(update.CallbackQuery, err)
+		}
+		return
+	}
+
 	// Просмотр рецепта
 	if strings.HasPrefix(data, "recipe:") {
 		recipeID, _ := strconv.Atoi(data[7:])
@@ -251,9 +457,12 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, update tgbotapi.Update) {
 			return
 		}
 
-		recipeMsg := tgbotapi.NewMessage(chatID, recipe.RecipeContent)
+		recipeMsg := tgbotapi.NewMessage(chatID, b.renderStoredRecipe(recipe))
 		recipeMsg.ParseMode = tgbotapi.ModeMarkdown
 		recipeMsg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🛒 В список покупок", fmt.Sprintf("shopping:add:%d", recipe.ID)),
+			),
 			tgbotapi.NewInlineKeyboardRow(
 				tgbotapi.NewInlineKeyboardButtonData("🗑 Удалить", fmt.Sprintf("delete:%d", recipe.ID)),
 				tgbotapi.NewInlineKeyboardButtonData("« Назад", "list_recipes"),
@@ -282,87 +491,206 @@ func (b *Bot) handleCallbackQuery(ctx context.Context, update tgbotapi.Update) {
 		return
 	}
 
+	// Другое блюдо из тех же продуктов
+	if data == "another_recipe" {
+		b.handleAnotherRecipe(ctx, update)
+		return
+	}
+
+	// Действия с несохраненным рецептом
+	if strings.HasPrefix(data, "draft:") {
+		b.handleDraftCallback(ctx, update)
+		return
+	}
+
+	// Продукты дома: отметка израсходованных, рецепт из них или из продуктов с истекающим сроком
+	// и списание после сохранения рецепта
+	if strings.HasPrefix(data, "inventory:") {
+		b.handleInventoryCallback(ctx, update)
+		return
+	}
+
+	// Удаление и добавление запасов
+	if strings.HasPrefix(data, "pantry:") {
+		b.handlePantryCallback(ctx, update)
+		return
+	}
+
+	// Список покупок: добавление недостающих продуктов рецепта, отметка купленных и очистка
+	if strings.HasPrefix(data, "shopping:") {
+		b.handleShoppingCallback(ctx, update)
+		return
+	}
+
 	// Возврат к списку
 	if data == "list_recipes" {
 		b.handleRecipesCommand(ctx, update)
 	}
 }
 
-// handlePhotoMessage обрабатывает сообщения с фотографиями
+// handlePhotoMessage обрабатывает сообщения с фотографиями и изображениями-файлами
 func (b *Bot) handlePhotoMessage(ctx context.Context, update tgbotapi.Update) {
-	user := update.Message.From
-	chatID := update.Message.Chat.ID
+	// Фото из альбома копятся, пока не придет весь альбом
+	if update.Message.MediaGroupID != "" {
+		b.albums.Add(ctx, update.Message)
+		return
+	}
+
+	b.recognizePhotos(ctx, []*tgbotapi.Message{update.Message})
+}
+
+// recognizePhotos распознает продукты на одном фото или на всех фото альбома,
+// объединяет списки и показывает общий список для правки
+func (b *Bot) recognizePhotos(ctx context.Context, messages []*tgbotapi.Message) {
+	user := messages[0].From
+	chatID := messages[0].Chat.ID
 
 	// Регистрируем пользователя
-	dbUser, _ := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+	b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
 
 	// Сообщение об обработке
-	processingMsg := tgbotapi.NewMessage(chatID, "Обрабатываю фото... Это займет несколько секунд.")
-	sentMsg, _ := b.api.Send(processingMsg)
+	processingText := "Обрабатываю фото... Это займет несколько секунд."
+	if len(messages) > 1 {
+		processingText = fmt.Sprintf("Обрабатываю %d фото... Это займет несколько секунд.", len(messages))
+	}
+	sentMsg, _ := b.api.Send(tgbotapi.NewMessage(chatID, processingText))
+	defer b.api.Request(tgbotapi.NewDeleteMessage(chatID, sentMsg.MessageID))
+
+	var (
+		wg      sync.WaitGroup
+		results = make([]*recognizedPhoto, len(messages))
+		errs    = make([]error, len(messages))
+		sem     = make(chan struct{}, maxParallelRecognitions)
+	)
+	for i, msg := range messages {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = b.recognizeImage(ctx, msg)
+			if errs[i] != nil {
+				b.logger.Warn("Photo recognition failed",
+					zap.Int64("chat_id", chatID), zap.Int("photo", i+1), zap.Error(errs[i]))
+			}
+		}()
+	}
+	wg.Wait()
 
-	// Получаем фото
-	photos := update.Message.Photo
-	fileID := photos[len(photos)-1].FileID
-	fileURL, err := b.api.GetFileDirectURL(fileID)
+	failed := 0
+	var firstErr error
+	for _, err := range errs {
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
 
-	if err != nil {
-		errMsg := tgbotapi.NewMessage(chatID, "Не удалось загрузить фото. Попробуйте снова.")
-		b.api.Send(errMsg)
+	// Одинаковые продукты с разных фото объединяются с учетом регистра, числа и синонимов
+	var lists [][]vision.Item
+	for _, result := range results {
+		if result != nil {
+			lists = append(lists, result.items)
+		}
+	}
+	products := recognizedChoices(vision.MergeItems(lists...), b.lowConfidence)
+	if len(products) == 0 {
+		b.api.Send(tgbotapi.NewMessage(chatID, b.imageErrorText(firstErr)))
 		return
 	}
 
-	// Загружаем изображение
-	photoResp, err := http.Get(fileURL)
-	if err != nil {
-		errMsg := tgbotapi.NewMessage(chatID, "Ошибка при загрузке изображения.")
+	if failed > 0 {
+		b.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf(
+			"Не удалось распознать продукты на %d из %d фото, список составлен по остальным.", failed, len(messages))))
+	}
+
+	if b.annotateImages {
+		b.sendAnnotatedPhotos(chatID, results, products)
+	}
+
+	// Показываем список продуктов для правки перед генерацией рецепта
+	if err := b.startProductEditing(ctx, chatID, products); err != nil {
+		b.logger.Error("Failed to start product editing", zap.Int64("chat_id", chatID), zap.Error(err))
+		errMsg := tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова.")
 		b.api.Send(errMsg)
-		return
 	}
-	defer photoResp.Body.Close()
+}
 
-	photoData, _ := io.ReadAll(photoResp.Body)
+// downloadFile загружает файл из Telegram по его идентификатору.
+// Файлы больше maxSize байт не загружаются целиком; 0 - без ограничения.
+func (b *Bot) downloadFile(ctx context.Context, fileID string, maxSize int64) ([]byte, error) {
+	fileURL, err := b.api.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file URL: %w", err)
+	}
 
-	// Распознаем продукты
-	recognizedItems, err := b.visionService.RecognizeProductsFromImage(ctx, bytes.NewReader(photoData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
 	if err != nil {
-		errMsg := tgbotapi.NewMessage(chatID, "Не удалось распознать продукты. Сделайте более четкий снимок.")
-		b.api.Send(errMsg)
-		return
+		return nil, err
 	}
 
-	// Отправляем список продуктов
-	itemsList := ""
-	for i, item := range recognizedItems.Items {
-		itemsList += fmt.Sprintf("%d. %s\n", i+1, item)
+	// Файлы загружаются тем же HTTP-клиентом, что и запросы к API
+	resp, err := b.api.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
 	}
+	defer resp.Body.Close()
 
-	recognizedMsg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Распознанные продукты:\n%s\n\nГенерирую рецепт...", itemsList))
-	b.api.Send(recognizedMsg)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	if maxSize <= 0 {
+		return io.ReadAll(resp.Body)
+	}
 
-	// Генерируем рецепт
-	recipe, err := b.recipeGenerator.GenerateRecipe(ctx, recognizedItems.Items)
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
 	if err != nil {
-		errMsg := tgbotapi.NewMessage(chatID, "Не удалось сгенерировать рецепт. Попробуйте снова.")
-		b.api.Send(errMsg)
-		return
+		return nil, err
 	}
+	if int64(len(data)) > maxSize {
+		return nil, errFileTooLarge
+	}
+	return data, nil
+}
 
-	// Форматируем и сохраняем рецепт
-	formattedRecipe := b.recipeGenerator.FormatRecipe(recipe)
-	ingredientsJSON, _ := json.Marshal(recipe.Ingredients)
+// handleDialogCallbackError отвечает на кнопку диалога, который не удалось обработать
+func (b *Bot) handleDialogCallbackError(query *tgbotapi.CallbackQuery, err error) {
+	chatID := query.Message.Chat.ID
 
-	b.dbManager.Queries.SaveRecipe(ctx, dbmodels.SaveRecipeParams{
-		UserID:        dbUser.ID,
-		RecipeTitle:   recipe.Title,
-		RecipeContent: formattedRecipe,
-		Ingredients:   ingredientsJSON,
-	})
+	if errors.Is(err, dialog.ErrNoSession) {
+		b.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, "Этот диалог устарел. Начните заново."))
+		b.removeInlineKeyboard(chatID, query.Message.MessageID)
+		return
+	}
 
-	// Отправляем рецепт
-	recipeMsg := tgbotapi.NewMessage(chatID, formattedRecipe)
-	recipeMsg.ParseMode = tgbotapi.ModeMarkdown
+	b.logger.Error("Dialog failed to handle callback",
+		zap.Int64("chat_id", chatID), zap.String("data", query.Data), zap.Error(err))
+	b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+}
 
-	// Удаляем сообщение о процессе
-	b.api.Request(tgbotapi.NewDeleteMessage(chatID, sentMsg.MessageID))
-	b.api.Send(recipeMsg)
+// loadRecipe загружает сохраненный рецепт пользователя в структурированном виде
+func (b *Bot) loadRecipe(ctx context.Context, userID int32, recipeID int32) (*recipes.Recipe, error) {
+	stored, err := b.dbManager.Queries.GetRecipe(ctx, dbmodels.GetRecipeParams{ID: recipeID, UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recipe: %w", err)
+	}
+	return recipes.DecodeDocument(stored.Document, stored.SchemaVersion)
+}
+
+// renderStoredRecipe формирует текст сохраненного рецепта из его JSON-документа.
+// Для записей, которые еще не удалось перевести в документ, отдается сохраненный Markdown.
+func (b *Bot) renderStoredRecipe(stored dbmodels.RecipeBotRecipe) string {
+	recipe, err := recipes.DecodeDocument(stored.Document, stored.SchemaVersion)
+	if err != nil {
+		b.logger.Warn("Falling back to stored markdown", zap.Int32("recipe_id", stored.ID), zap.Error(err))
+		if stored.RecipeContent.Valid {
+			return stored.RecipeContent.String
+		}
+		return stored.RecipeTitle
+	}
+	return recipes.FormatRecipe(recipe)
 }
@@ -0,0 +1,367 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database"
+	dbmodels "github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/speech"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/vision"
+)
+
+const testToken = "test-token"
+
+// sentMessage - сообщение, отправленное ботом в Telegram
+type sentMessage struct {
+	ID     int
+	Method string
+	Params url.Values
+}
+
+// fakeTelegram отвечает на запросы к Bot API и на загрузку файлов без обращения к сети
+type fakeTelegram struct {
+	mu     sync.Mutex
+	lastID int
+	files  map[string][]byte
+	sent   chan sentMessage
+}
+
+func newFakeTelegram(files map[string][]byte) *fakeTelegram {
+	return &fakeTelegram{files: files, sent: make(chan sentMessage, 100)}
+}
+
+func (f *fakeTelegram) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	f.serve(rec, req)
+	return rec.Result(), nil
+}
+
+func (f *fakeTelegram) serve(w http.ResponseWriter, r *http.Request) {
+	if filePath, ok := strings.CutPrefix(r.URL.Path, "/file/bot"+testToken+"/"); ok {
+		data, ok := f.files[filePath]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+		return
+	}
+
+	method, ok := strings.CutPrefix(r.URL.Path, "/bot"+testToken+"/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	r.ParseForm()
+
+	var result any = true
+	switch method {
+	case "getMe":
+		result = tgbotapi.User{ID: 1, IsBot: true, UserName: "test_bot"}
+	case "getFile":
+		fileID := r.Form.Get("file_id")
+		result = tgbotapi.File{FileID: fileID, FilePath: "files/" + fileID}
+	case "sendMessage", "sendPhoto":
+		f.mu.Lock()
+		f.lastID++
+		id := f.lastID
+		f.mu.Unlock()
+
+		chatID, _ := strconv.ParseInt(r.Form.Get("chat_id"), 10, 64)
+		result = tgbotapi.Message{MessageID: id, Chat: &tgbotapi.Chat{ID: chatID}, Text: r.Form.Get("text")}
+		f.sent <- sentMessage{ID: id, Method: method, Params: r.Form}
+	}
+
+	raw, _ := json.Marshal(result)
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: raw})
+}
+
+// waitMessage ждет сообщение, текст которого содержит substr; остальные сообщения пропускаются
+func (f *fakeTelegram) waitMessage(t *testing.T, substr string) sentMessage {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case msg := <-f.sent:
+			if strings.Contains(msg.Params.Get("text"), substr) {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("message containing %q was not sent", substr)
+			return sentMessage{}
+		}
+	}
+}
+
+// fakeDB выполняет запросы sqlc в памяти, различая их по имени из комментария "-- name:"
+type fakeDB struct {
+	mu       sync.Mutex
+	users    map[int64]int32
+	dialogs  map[int64][]any
+	recipes  int32
+	executed map[string]int
+	// saveErr возвращается запросом SaveRecipe
+	saveErr error
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{
+		users:    make(map[int64]int32),
+		dialogs:  make(map[int64][]any),
+		executed: make(map[string]int),
+	}
+}
+
+// queryName возвращает имя запроса sqlc
+func queryName(sql string) string {
+	fields := strings.Fields(strings.TrimPrefix(sql, "-- name:"))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+func (db *fakeDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	name := queryName(sql)
+	db.executed[name]++
+	switch name {
+	case "UpsertDialogSession":
+		// chat_id, flow, state, data, expires_at
+		db.dialogs[args[0].(int64)] = args
+	case "DeleteDialogSession":
+		delete(db.dialogs, args[0].(int64))
+	}
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (db *fakeDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.executed[queryName(sql)]++
+	return emptyRows{}, nil
+}
+
+func (db *fakeDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	name := queryName(sql)
+	db.executed[name]++
+	switch name {
+	case "GetUserByTelegramID":
+		if id, ok := db.users[args[0].(int64)]; ok {
+			return fakeRow{values: []any{id, args[0]}}
+		}
+	case "CreateUser":
+		id := int32(len(db.users) + 1)
+		db.users[args[0].(int64)] = id
+		return fakeRow{values: []any{id, args[0]}}
+	case "GetDialogSession":
+		if args, ok := db.dialogs[args[0].(int64)]; ok {
+			return fakeRow{values: args}
+		}
+	case "SaveRecipe":
+		if db.saveErr != nil {
+			return fakeRow{err: db.saveErr}
+		}
+		db.recipes++
+		return fakeRow{values: []any{db.recipes, args[0]}}
+	case "UpsertRecognitionSession":
+		return fakeRow{values: []any{args[0], args[1], []byte("[]")}}
+	}
+	return fakeRow{err: pgx.ErrNoRows}
+}
+
+// dialog возвращает имя сценария и состояние диалога чата
+func (db *fakeDB) dialog(chatID int64) (flow, state string, ok bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	args, ok := db.dialogs[chatID]
+	if !ok {
+		return "", "", false
+	}
+	return args[1].(string), args[2].(string), true
+}
+
+func (db *fakeDB) count(name string) int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.executed[name]
+}
+
+// fakeRow заполняет первые колонки результата; остальные остаются нулевыми
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i, value := range r.values {
+		if i < len(dest) && value != nil {
+			reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+		}
+	}
+	return nil
+}
+
+// emptyRows - пустой результат запроса :many
+type emptyRows struct{}
+
+func (emptyRows) Close()                                       {}
+func (emptyRows) Err() error                                   { return nil }
+func (emptyRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (emptyRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (emptyRows) Next() bool                                   { return false }
+func (emptyRows) Scan(dest ...any) error                       { return nil }
+func (emptyRows) Values() ([]any, error)                       { return nil, nil }
+func (emptyRows) RawValues() [][]byte                          { return nil }
+func (emptyRows) Conn() *pgx.Conn                              { return nil }
+
+// newTestBot собирает бота с фикстурными бэкендами, поддельным Telegram и базой в памяти
+func newTestBot(t *testing.T, tg *fakeTelegram, db *fakeDB, recognizer vision.Recognizer, transcriber speech.Transcriber) *Bot {
+	t.Helper()
+	api, err := tgbotapi.NewBotAPIWithClient(testToken, tgbotapi.APIEndpoint, &http.Client{Transport: tg})
+	if err != nil {
+		t.Fatalf("NewBotAPIWithClient() error = %v", err)
+	}
+
+	dbManager := &database.DBManager{Queries: dbmodels.New(db)}
+	return newBot(api, zap.NewNop(), dbManager, recognizer, recipes.NewFixtureGenerator(), transcriber, Options{
+		DraftTTL:         time.Hour,
+		DialogTimeout:    time.Hour,
+		MaxVoiceDuration: time.Minute,
+		MaxImageSize:     1 << 20,
+	})
+}
+
+var (
+	testUser = &tgbotapi.User{ID: 42, FirstName: "Анна"}
+	testChat = &tgbotapi.Chat{ID: 4200}
+)
+
+func photoUpdate(fileID string) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		MessageID: 1,
+		From:      testUser,
+		Chat:      testChat,
+		Photo: []tgbotapi.PhotoSize{
+			{FileID: fileID + "-small", FileSize: 1},
+			{FileID: fileID, FileSize: 4},
+		},
+	}}
+}
+
+func callbackUpdate(messageID int, data string) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "callback",
+		From:    testUser,
+		Message: &tgbotapi.Message{MessageID: messageID, Chat: testChat},
+		Data:    data,
+	}}
+}
+
+func TestHandlePhotoMessageGeneratesRecipe(t *testing.T) {
+	tg := newFakeTelegram(map[string][]byte{"files/photo-1": []byte("jpeg")})
+	db := newFakeDB()
+	b := newTestBot(t, tg, db, vision.NewFixtureVision(nil), speech.NewFixtureTranscriber(""))
+	ctx := context.Background()
+
+	b.handlePhotoMessage(ctx, photoUpdate("photo-1"))
+
+	list := tg.waitMessage(t, "Продукты:")
+	for _, want := range []string{"1. ✅ яйца", "2. ✅ помидоры", "3. ✅ сыр"} {
+		if !strings.Contains(list.Params.Get("text"), want) {
+			t.Errorf("product list %q does not contain %q", list.Params.Get("text"), want)
+		}
+	}
+	if !strings.Contains(list.Params.Get("reply_markup"), "products:generate") {
+		t.Errorf("product list keyboard %s has no generate button", list.Params.Get("reply_markup"))
+	}
+	if flow, state, ok := db.dialog(testChat.ID); !ok || flow != productsFlow || state != string(productsEditing) {
+		t.Fatalf("dialog = %q/%q (exists %v), want %s/%s", flow, state, ok, productsFlow, productsEditing)
+	}
+
+	b.handleCallbackQuery(ctx, callbackUpdate(list.ID, "products:generate"))
+
+	recipe := tg.waitMessage(t, "Блюдо из: яйца, помидоры, сыр")
+	if !strings.Contains(recipe.Params.Get("reply_markup"), "draft:save") {
+		t.Errorf("recipe keyboard %s has no save button", recipe.Params.Get("reply_markup"))
+	}
+	if _, _, ok := db.dialog(testChat.ID); ok {
+		t.Error("product dialog is not finished after generation")
+	}
+	if db.count("UpsertInventoryItems") != 1 {
+		t.Errorf("recognized products were not added to the inventory")
+	}
+	if db.count("AddSuggestedTitle") != 1 {
+		t.Errorf("generated title was not remembered")
+	}
+}
+
+func TestHandlePhotoMessageRecognitionFailure(t *testing.T) {
+	tests := []struct {
+		name       string
+		recognizer vision.Recognizer
+		fileID     string
+		want       string
+	}{
+		{
+			name:       "ошибка модели",
+			recognizer: vision.NewFailingFixtureVision(fmt.Errorf("model is unavailable")),
+			fileID:     "photo-1",
+			want:       "Не удалось распознать продукты",
+		},
+		{
+			name:       "неподдерживаемый формат",
+			recognizer: vision.NewFailingFixtureVision(vision.ErrUnsupportedImage),
+			fileID:     "photo-1",
+			want:       "Формат изображения не поддерживается",
+		},
+		{
+			name:       "файл не загружен",
+			recognizer: vision.NewFixtureVision(nil),
+			fileID:     "missing",
+			want:       "Ошибка при загрузке изображения",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tg := newFakeTelegram(map[string][]byte{"files/photo-1": []byte("jpeg")})
+			db := newFakeDB()
+			b := newTestBot(t, tg, db, tt.recognizer, speech.NewFixtureTranscriber(""))
+
+			b.handlePhotoMessage(context.Background(), photoUpdate(tt.fileID))
+
+			tg.waitMessage(t, tt.want)
+			if _, _, ok := db.dialog(testChat.ID); ok {
+				t.Error("product dialog started without recognized products")
+			}
+		})
+	}
+}
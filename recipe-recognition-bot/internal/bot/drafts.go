@@ -0,0 +1,198 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	dbmodels "github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/drafts"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+)
+
+// draftKeyboard - кнопки под свежесгенерированным рецептом
+func draftKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💾 Сохранить", "draft:save"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔁 Перегенерировать", "draft:regenerate"),
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Другой рецепт", "another_recipe"),
+		),
+	)
+}
+
+// offerRecipe генерирует рецепт, отмечает блюдо как предложенное в сессии распознавания,
+// отправляет с кнопками сохранения и запоминает как черновик пользователя
+func (b *Bot) offerRecipe(ctx context.Context, chatID int64, user *dbmodels.RecipeBotUser, req recipes.Request) error {
+	// Без ограничений пользователя рецепт может оказаться опасным для аллергика, поэтому не генерируем его
+	prefs, err := b.dbManager.GetPreferences(ctx, user.ID)
+	if err != nil {
+		b.logger.Error("Failed to load preferences", zap.Int32("user_id", user.ID), zap.Error(err))
+		return err
+	}
+	req.Preferences = prefs
+
+	// Без запасов рецепт все равно можно приготовить, только без соли и масла
+	if req.Staples, err = b.dbManager.PantryStaples(ctx, user.ID); err != nil {
+		b.logger.Warn("Failed to load pantry staples", zap.Int32("user_id", user.ID), zap.Error(err))
+	}
+
+	recipe, err := b.recipeGenerator.GenerateRecipe(ctx, req)
+	if err != nil {
+		b.logger.Error("Recipe generation failed", zap.Int64("user_id", user.TelegramID), zap.Error(err))
+		return err
+	}
+
+	if err := b.dbManager.AddSuggestedTitle(ctx, user.ID, recipe.Title); err != nil {
+		b.logger.Warn("Failed to remember suggested title", zap.Int32("user_id", user.ID), zap.Error(err))
+	}
+
+	recipeMsg := tgbotapi.NewMessage(chatID, recipes.FormatRecipe(recipe))
+	recipeMsg.ParseMode = tgbotapi.ModeMarkdown
+	recipeMsg.ReplyMarkup = draftKeyboard()
+	sent, err := b.api.Send(recipeMsg)
+	if err != nil {
+		return err
+	}
+
+	// Черновик привязан к сообщению: кнопки под прежними рецептами его не сохранят
+	b.drafts.Put(user.TelegramID, drafts.Draft{
+		Recipe:    recipe,
+		Products:  req.Products,
+		Priority:  req.Priority,
+		MessageID: sent.MessageID,
+	})
+	return nil
+}
+
+// handleDraftCallback обрабатывает кнопки под несохраненным рецептом
+func (b *Bot) handleDraftCallback(ctx context.Context, update tgbotapi.Update) {
+	query := update.CallbackQuery
+	chatID := query.Message.Chat.ID
+	user := query.From
+
+	var (
+		draft drafts.Draft
+		ok    bool
+	)
+	if query.Data == "draft:save" {
+		// Обновления обрабатываются параллельно: при двойном нажатии черновик забирает только первое
+		draft, ok = b.drafts.Take(user.ID, query.Message.MessageID)
+	} else {
+		draft, ok = b.drafts.Get(user.ID)
+		ok = ok && draft.MessageID == query.Message.MessageID
+	}
+	if !ok {
+		b.api.Request(tgbotapi.NewCallbackWithAlert(query.ID,
+			"Этот рецепт устарел. Используйте кнопки под последним рецептом или отправьте фото продуктов заново."))
+		b.removeInlineKeyboard(chatID, query.Message.MessageID)
+		return
+	}
+
+	dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+	if err != nil {
+		b.logger.Error("Failed to load user", zap.Int64("user_id", user.ID), zap.Error(err))
+		if query.Data == "draft:save" {
+			b.drafts.Restore(user.ID, draft)
+		}
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	switch query.Data {
+	case "draft:save":
+		saved, err := b.saveRecipe(ctx, dbUser.ID, draft.Recipe)
+		if err != nil {
+			b.logger.Error("Failed to save recipe", zap.Int64("user_id", user.ID), zap.Error(err))
+			// Черновик возвращается, чтобы сохранение можно было повторить
+			b.drafts.Restore(user.ID, draft)
+			b.api.Request(tgbotapi.NewCallback(query.ID, "Не удалось сохранить рецепт. Попробуйте снова."))
+			return
+		}
+
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Рецепт сохранен"))
+		b.removeInlineKeyboard(chatID, query.Message.MessageID)
+		b.api.Send(tgbotapi.NewMessage(chatID, "✅ Рецепт сохранен. Используйте /recipes для просмотра."))
+		b.offerInventoryUsage(ctx, chatID, dbUser.ID, saved.ID, draft.Recipe)
+
+	case "draft:regenerate":
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Генерирую рецепт..."))
+		b.removeInlineKeyboard(chatID, query.Message.MessageID)
+		b.api.Request(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+
+		err := b.offerRecipe(ctx, chatID, dbUser, recipes.Request{
+			Products: draft.Products,
+			Priority: draft.Priority,
+			Force:    true,
+		})
+		if err != nil {
+			b.api.Send(tgbotapi.NewMessage(chatID, "Не удалось сгенерировать рецепт. Попробуйте снова."))
+		}
+
+	default:
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+	}
+}
+
+// handleAnotherRecipe генерирует другое блюдо из последнего распознанного набора продуктов,
+// избегая блюд, уже предложенных в этой сессии
+func (b *Bot) handleAnotherRecipe(ctx context.Context, update tgbotapi.Update) {
+	query := update.CallbackQuery
+	chatID := query.Message.Chat.ID
+	user := query.From
+
+	dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+	if err != nil {
+		b.logger.Error("Failed to load user", zap.Int64("user_id", user.ID), zap.Error(err))
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	session, err := b.dbManager.GetRecognitionSession(ctx, dbUser.ID)
+	if err != nil {
+		b.api.Request(tgbotapi.NewCallbackWithAlert(query.ID,
+			"Не найден список продуктов. Отправьте фото продуктов заново."))
+		return
+	}
+
+	b.api.Request(tgbotapi.NewCallback(query.ID, "Подбираю другое блюдо..."))
+	b.removeInlineKeyboard(chatID, query.Message.MessageID)
+	b.api.Request(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+
+	err = b.offerRecipe(ctx, chatID, dbUser, recipes.Request{
+		Products:    session.Items,
+		AvoidTitles: session.SuggestedTitles,
+	})
+	if err != nil {
+		b.api.Send(tgbotapi.NewMessage(chatID, "Не удалось сгенерировать рецепт. Попробуйте снова."))
+	}
+}
+
+// saveRecipe сохраняет рецепт пользователя в БД
+func (b *Bot) saveRecipe(ctx context.Context, userID int32, recipe *recipes.Recipe) (dbmodels.RecipeBotRecipe, error) {
+	ingredientsJSON, _ := json.Marshal(recipe.Ingredients)
+	document, err := recipes.EncodeDocument(recipe)
+	if err != nil {
+		return dbmodels.RecipeBotRecipe{}, err
+	}
+
+	return b.dbManager.Queries.SaveRecipe(ctx, dbmodels.SaveRecipeParams{
+		UserID:        userID,
+		RecipeTitle:   recipe.Title,
+		Ingredients:   ingredientsJSON,
+		Model:         pgtype.Text{String: recipe.Model, Valid: recipe.Model != ""},
+		Document:      document,
+		SchemaVersion: recipes.SchemaVersion,
+	})
+}
+
+// removeInlineKeyboard убирает инлайн-кнопки у сообщения
+func (b *Bot) removeInlineKeyboard(chatID int64, messageID int) {
+	b.api.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+}
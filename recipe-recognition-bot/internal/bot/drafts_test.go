@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/drafts"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/speech"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/vision"
+)
+
+func newDraftTestBot(t *testing.T, db *fakeDB) (*Bot, *fakeTelegram) {
+	t.Helper()
+	tg := newFakeTelegram(nil)
+	b := newTestBot(t, tg, db, vision.NewFixtureVision(nil), speech.NewFixtureTranscriber(""))
+	b.drafts.Put(testUser.ID, drafts.Draft{
+		Recipe:    &recipes.Recipe{Title: "Омлет", Ingredients: []recipes.Ingredient{{Name: "яйца"}}},
+		MessageID: 7,
+	})
+	return b, tg
+}
+
+func TestHandleDraftCallbackSavesOnce(t *testing.T) {
+	db := newFakeDB()
+	b, tg := newDraftTestBot(t, db)
+
+	// Двойное нажатие «Сохранить»: обновления обрабатываются в отдельных горутинах
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.handleDraftCallback(context.Background(), callbackUpdate(7, "draft:save"))
+		}()
+	}
+	wg.Wait()
+
+	tg.waitMessage(t, "Рецепт сохранен")
+	if n := db.count("SaveRecipe"); n != 1 {
+		t.Errorf("recipe saved %d times, want 1", n)
+	}
+	if _, ok := b.drafts.Get(testUser.ID); ok {
+		t.Error("saved draft is still in the store")
+	}
+}
+
+func TestHandleDraftCallbackRestoresDraftOnFailure(t *testing.T) {
+	db := newFakeDB()
+	db.saveErr = errors.New("connection reset")
+	b, _ := newDraftTestBot(t, db)
+
+	b.handleDraftCallback(context.Background(), callbackUpdate(7, "draft:save"))
+
+	if draft, ok := b.drafts.Get(testUser.ID); !ok || draft.MessageID != 7 {
+		t.Fatalf("draft after failed save = %+v, %v; want it back in the store", draft, ok)
+	}
+
+	db.mu.Lock()
+	db.saveErr = nil
+	db.mu.Unlock()
+	b.handleDraftCallback(context.Background(), callbackUpdate(7, "draft:save"))
+	if n := db.count("SaveRecipe"); n != 2 {
+		t.Errorf("SaveRecipe called %d times, want a retry after the failure", n)
+	}
+}
@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	dbmodels "github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/inventory"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/reminders"
+)
+
+const expiryUsage = "Укажите продукт из /inventory и срок годности датой или числом дней:\n" +
+	"/expiry молоко 25.10\n" +
+	"/expiry сыр 7"
+
+// expiryDate - дата окончания срока; "~" отмечает срок, оцененный по категории продукта
+func expiryDate(item inventory.Item) string {
+	date := item.ExpiresAt.Format("02.01")
+	if item.ExpiryEstimated {
+		return "~" + date
+	}
+	return date
+}
+
+// expiryLabel - срок годности в списке продуктов дома; пустая строка - срок неизвестен
+func expiryLabel(item inventory.Item, now time.Time) string {
+	switch {
+	case item.ExpiresAt.IsZero():
+		return ""
+	case item.ExpiresAt.Before(now):
+		return "⚠️ срок истек " + expiryDate(item)
+	default:
+		return "годен до " + expiryDate(item)
+	}
+}
+
+// daysUntil - сколько календарных дней осталось от now до t
+func daysUntil(now, t time.Time) int {
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	year, month, day = t.In(now.Location()).Date()
+	target := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	// Округление сглаживает переход на летнее время, когда в сутках 23 или 25 часов
+	return int(math.Round(target.Sub(today).Hours() / 24))
+}
+
+// expiryReminderText - текст ежедневного напоминания о продуктах с истекающим сроком
+func expiryReminderText(items []inventory.Item, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString("⏰ Скоро истекает срок годности:\n\n")
+	for _, item := range items {
+		when := "до " + expiryDate(item)
+		switch days := daysUntil(now, item.ExpiresAt); {
+		case item.ExpiresAt.Before(now):
+			when = "срок истек " + expiryDate(item)
+		case days == 0:
+			when = "сегодня"
+		case days == 1:
+			when = "завтра"
+		case days == 2:
+			when = "послезавтра"
+		}
+		sb.WriteString(fmt.Sprintf("• %s — %s\n", item.Name, when))
+	}
+	sb.WriteString("\nПриготовьте из них что-нибудь, пока они не испортились. " +
+		"Израсходованные продукты отметьте в /inventory.")
+	return sb.String()
+}
+
+// sendExpiryReminder отправляет напоминание планировщика с кнопкой рецепта из истекающих продуктов
+func (b *Bot) sendExpiryReminder(ctx context.Context, reminder reminders.Reminder) error {
+	msg := tgbotapi.NewMessage(reminder.ChatID, expiryReminderText(reminder.Items, time.Now()))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🍳 Рецепт из этих продуктов", "inventory:use_up"),
+	))
+	_, err := b.api.Send(msg)
+	return err
+}
+
+// parseExpiryArgs разбирает аргументы /expiry: название продукта и срок в конце.
+// Срок занимает одно слово ("25.10", "7") или два ("3 дня").
+func parseExpiryArgs(args string, now time.Time) (string, time.Time, bool) {
+	fields := strings.Fields(args)
+	for n := 1; n <= 2 && n < len(fields); n++ {
+		expiresAt, err := inventory.ParseExpiry(strings.Join(fields[len(fields)-n:], " "), now)
+		if err == nil {
+			return strings.Join(fields[:len(fields)-n], " "), expiresAt, true
+		}
+	}
+	return "", time.Time{}, false
+}
+
+// handleExpiryCommand обрабатывает команду /expiry: ручной ввод срока годности продукта
+func (b *Bot) handleExpiryCommand(ctx context.Context, update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	user := update.Message.From
+
+	name, expiresAt, ok := parseExpiryArgs(update.Message.CommandArguments(), time.Now())
+	if !ok {
+		b.api.Send(tgbotapi.NewMessage(chatID, expiryUsage))
+		return
+	}
+
+	dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+	if err != nil {
+		b.logger.Error("Failed to load user", zap.Int64("user_id", user.ID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	items, err := b.dbManager.InventoryItems(ctx, dbUser.ID)
+	if err != nil {
+		b.logger.Error("Failed to load inventory", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	key := ingredients.Key(name)
+	for _, item := range items {
+		if ingredients.Key(item.Name) != key {
+			continue
+		}
+		if _, err := b.dbManager.SetInventoryExpiry(ctx, dbUser.ID, item.ID, expiresAt); err != nil {
+			b.logger.Error("Failed to set inventory expiry", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+			b.api.Send(tgbotapi.NewMessage(chatID, "Не удалось сохранить срок годности. Попробуйте снова."))
+			return
+		}
+		b.api.Send(tgbotapi.NewMessage(chatID,
+			fmt.Sprintf("✅ %s: годен до %s", item.Name, expiresAt.Format("02.01.2006"))))
+		return
+	}
+
+	b.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf(
+		"Продукта «%s» нет в списке /inventory. Срок годности задается для продуктов, которые есть дома.", name)))
+}
+
+// cookExpiring предлагает рецепт из продуктов дома, в первую очередь из тех, у которых истекает срок.
+// false - таких продуктов уже нет: их израсходовали или срок продлили.
+func (b *Bot) cookExpiring(ctx context.Context, chatID int64, user *dbmodels.RecipeBotUser) (bool, error) {
+	items, err := b.dbManager.InventoryItems(ctx, user.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load inventory: %w", err)
+	}
+	expiring := inventory.Expiring(items, inventory.ExpiryHorizon(time.Now(), b.expiryDays))
+	if len(expiring) == 0 {
+		return false, nil
+	}
+
+	products := make([]string, 0, len(items))
+	for _, item := range items {
+		products = append(products, item.Name)
+	}
+	priority := make([]string, 0, len(expiring))
+	for _, item := range expiring {
+		priority = append(priority, item.Name)
+	}
+
+	// Как и после подтверждения списка, по этим продуктам можно попросить другой рецепт
+	if err := b.dbManager.StartRecognitionSession(ctx, user.ID, products); err != nil {
+		b.logger.Warn("Failed to store recognition session", zap.Int32("user_id", user.ID), zap.Error(err))
+	}
+	return true, b.offerRecipe(ctx, chatID, user, recipes.Request{Products: products, Priority: priority})
+}
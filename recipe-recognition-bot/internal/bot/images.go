@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/imageprep"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/vision"
+)
+
+var (
+	// errFileTooLarge - файл превышает допустимый размер
+	errFileTooLarge = errors.New("file is too large")
+	// errDownloadFailed - файл не удалось получить из Telegram
+	errDownloadFailed = errors.New("failed to download image")
+)
+
+// imageExtensions - расширения изображений для файлов без MIME-типа или с типом application/octet-stream
+var imageExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".webp": true, ".gif": true, ".heic": true, ".heif": true,
+}
+
+// isImageDocument сообщает, похож ли файл на изображение. Окончательно формат
+// проверяется по содержимому после загрузки.
+func isImageDocument(doc *tgbotapi.Document) bool {
+	if doc == nil {
+		return false
+	}
+	return strings.HasPrefix(doc.MimeType, "image/") ||
+		imageExtensions[strings.ToLower(filepath.Ext(doc.FileName))]
+}
+
+// imageFile возвращает идентификатор и размер изображения из сообщения:
+// наибольший вариант сжатого фото или исходный файл
+func imageFile(msg *tgbotapi.Message) (string, int) {
+	if msg.Document != nil {
+		return msg.Document.FileID, msg.Document.FileSize
+	}
+	photo := msg.Photo[len(msg.Photo)-1]
+	return photo.FileID, photo.FileSize
+}
+
+// recognizedPhoto - загруженное изображение и распознанные на нем продукты
+type recognizedPhoto struct {
+	data  []byte
+	items []vision.Item
+}
+
+// recognizeImage загружает изображение из сообщения и распознает на нем продукты
+func (b *Bot) recognizeImage(ctx context.Context, msg *tgbotapi.Message) (*recognizedPhoto, error) {
+	fileID, size := imageFile(msg)
+	// Размер, заявленный Telegram, позволяет отказаться от загрузки заранее
+	if b.maxImageSize > 0 && int64(size) > b.maxImageSize {
+		return nil, errFileTooLarge
+	}
+
+	data, err := b.downloadFile(ctx, fileID, b.maxImageSize)
+	if err != nil {
+		if errors.Is(err, errFileTooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", errDownloadFailed, err)
+	}
+
+	recognizedItems, err := b.visionService.RecognizeProductsFromImage(ctx, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return &recognizedPhoto{data: data, items: recognizedItems.Items}, nil
+}
+
+// sendAnnotatedPhotos отправляет фото с пронумерованными рамками вокруг продуктов.
+// Номера совпадают с номерами в списке продуктов; фото без рамок не отправляются.
+func (b *Bot) sendAnnotatedPhotos(chatID int64, photos []*recognizedPhoto, products []productChoice) {
+	numbers := make(map[string]int, len(products))
+	for i, p := range products {
+		numbers[ingredients.Key(p.Name)] = i + 1
+	}
+
+	var files []tgbotapi.FileBytes
+	for i, photo := range photos {
+		if photo == nil {
+			continue
+		}
+
+		var boxes []imageprep.Box
+		for _, item := range photo.items {
+			number, ok := numbers[ingredients.Key(item.Name)]
+			if !ok || item.Box == nil {
+				continue
+			}
+			boxes = append(boxes, imageprep.Box{
+				X:      item.Box.X,
+				Y:      item.Box.Y,
+				Width:  item.Box.Width,
+				Height: item.Box.Height,
+				Label:  number,
+			})
+		}
+		if len(boxes) == 0 {
+			continue
+		}
+
+		annotated, err := imageprep.Annotate(photo.data, boxes, b.imageOptions)
+		if err != nil {
+			// Поврежденное фото не передавалось модели, предупреждение о нем не нужно
+			if !errors.Is(err, imageprep.ErrUndecodable) {
+				b.logger.Warn("Failed to annotate photo", zap.Int64("chat_id", chatID), zap.Error(err))
+			}
+			continue
+		}
+		files = append(files, tgbotapi.FileBytes{Name: fmt.Sprintf("products-%d.jpg", i+1), Bytes: annotated})
+	}
+
+	var err error
+	switch len(files) {
+	case 0:
+		return
+	case 1:
+		_, err = b.api.Send(tgbotapi.NewPhoto(chatID, files[0]))
+	default:
+		media := make([]interface{}, 0, len(files))
+		for _, file := range files {
+			media = append(media, tgbotapi.NewInputMediaPhoto(file))
+		}
+		_, err = b.api.SendMediaGroup(tgbotapi.NewMediaGroup(chatID, media))
+	}
+	if err != nil {
+		b.logger.Warn("Failed to send annotated photos", zap.Int64("chat_id", chatID), zap.Error(err))
+	}
+}
+
+// imageErrorText - сообщение пользователю о том, почему изображение не удалось распознать
+func (b *Bot) imageErrorText(err error) string {
+	switch {
+	case errors.Is(err, errFileTooLarge):
+		return fmt.Sprintf("Файл слишком большой. Отправьте изображение размером до %d МБ.", b.maxImageSize>>20)
+	case errors.Is(err, vision.ErrUnsupportedImage):
+		return "Формат изображения не поддерживается. Отправьте фото в формате JPEG, PNG или WEBP."
+	case errors.Is(err, errDownloadFailed):
+		return "Ошибка при загрузке изображения."
+	default:
+		return "Не удалось распознать продукты. Сделайте более четкий снимок."
+	}
+}
@@ -0,0 +1,265 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/inventory"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+)
+
+// inventoryText - текст сообщения со списком продуктов, которые есть дома
+func inventoryText(items []inventory.Item) string {
+	if len(items) == 0 {
+		return "Список продуктов дома пуст. Отправьте фото продуктов — после подтверждения списка они появятся здесь."
+	}
+
+	now := time.Now()
+	estimated := false
+	var sb strings.Builder
+	sb.WriteString("Дома есть:\n")
+	for i, item := range items {
+		line := fmt.Sprintf("%d. %s", i+1, item.Name)
+		if item.Quantity != "" {
+			line += " — " + item.Quantity
+		}
+		details := "с " + item.AddedAt.Format("02.01")
+		if expiry := expiryLabel(item, now); expiry != "" {
+			details += ", " + expiry
+		}
+		sb.WriteString(line + " (" + details + ")\n")
+		estimated = estimated || item.ExpiryEstimated
+	}
+	if estimated {
+		sb.WriteString("\n~ — срок оценен по типу продукта. Точный срок: /expiry молоко 25.10")
+	} else {
+		sb.WriteString("\nСрок годности: /expiry молоко 25.10")
+	}
+	sb.WriteString("\nНажмите на продукт, когда он закончится. /cook без списка предложит рецепт из этих продуктов.")
+	return sb.String()
+}
+
+// inventoryKeyboard - кнопки отметки израсходованных продуктов и генерации рецепта
+func inventoryKeyboard(items []inventory.Item) tgbotapi.InlineKeyboardMarkup {
+	// Пустой, а не nil список убирает кнопки, когда закончился последний продукт
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for _, item := range items {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✔️ "+item.Name, fmt.Sprintf("inventory:used:%d", item.ID)),
+		))
+	}
+	if len(items) > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🍳 Рецепт из этих продуктов", "inventory:cook"),
+		))
+	}
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// inventoryChoices - продукты, которые есть дома, для редактируемого списка
+func inventoryChoices(items []inventory.Item) []productChoice {
+	choices := make([]productChoice, 0, len(items))
+	for _, item := range items {
+		choices = append(choices, productChoice{Name: item.Name, Selected: true, Quantity: item.Quantity})
+	}
+	return choices
+}
+
+// rememberInventory добавляет выбранные продукты, распознанные по фото, в список того, что есть дома.
+// Срок годности оценивается по категории продукта; точный пользователь задает командой /expiry.
+func (b *Bot) rememberInventory(ctx context.Context, userID int32, products []productChoice) {
+	var items []inventory.Item
+	for _, p := range products {
+		if p.Selected && p.Recognized {
+			items = append(items, inventory.Item{Name: p.Name, Quantity: p.Quantity})
+		}
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	items = inventory.WithEstimatedExpiry(items, time.Now())
+	if _, err := b.dbManager.AddInventoryItems(ctx, userID, items); err != nil {
+		b.logger.Warn("Failed to update inventory", zap.Int32("user_id", userID), zap.Error(err))
+	}
+}
+
+// handleInventoryCommand обрабатывает команду /inventory
+func (b *Bot) handleInventoryCommand(ctx context.Context, update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	user := update.Message.From
+
+	dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+	if err != nil {
+		b.logger.Error("Failed to load user", zap.Int64("user_id", user.ID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	items, err := b.dbManager.InventoryItems(ctx, dbUser.ID)
+	if err != nil {
+		b.logger.Error("Failed to load inventory", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, inventoryText(items))
+	if len(items) > 0 {
+		msg.ReplyMarkup = inventoryKeyboard(items)
+	}
+	b.api.Send(msg)
+}
+
+// startInventoryEditing показывает продукты, которые есть дома, для правки перед генерацией.
+// false - список пуст и сценарий не начат.
+func (b *Bot) startInventoryEditing(ctx context.Context, chatID int64, userID int32) (bool, error) {
+	items, err := b.dbManager.InventoryItems(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load inventory: %w", err)
+	}
+	if len(items) == 0 {
+		return false, nil
+	}
+	return true, b.startProductEditing(ctx, chatID, inventoryChoices(items))
+}
+
+// offerInventoryUsage после сохранения рецепта предлагает списать использованные продукты
+func (b *Bot) offerInventoryUsage(ctx context.Context, chatID int64, userID int32, recipeID int32, recipe *recipes.Recipe) {
+	items, err := b.dbManager.InventoryItems(ctx, userID)
+	if err != nil {
+		b.logger.Warn("Failed to load inventory", zap.Int32("user_id", userID), zap.Error(err))
+		return
+	}
+	usages := inventory.Consume(items, recipe.Ingredients)
+	if len(usages) == 0 {
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, "Списать продукты, использованные в рецепте?\n\n"+usagesText(usages))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Списать", fmt.Sprintf("inventory:consume:%d", recipeID)),
+		tgbotapi.NewInlineKeyboardButtonData("Не нужно", "inventory:keep"),
+	))
+	b.api.Send(msg)
+}
+
+// usagesText - что станет с продуктами после списания
+func usagesText(usages []inventory.Usage) string {
+	var sb strings.Builder
+	for _, usage := range usages {
+		if usage.Used() {
+			sb.WriteString(fmt.Sprintf("• %s — закончится\n", usage.Item.Name))
+		} else {
+			sb.WriteString(fmt.Sprintf("• %s — останется %s\n", usage.Item.Name, usage.Remaining))
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// handleInventoryCallback обрабатывает кнопки списка продуктов и списания после сохранения рецепта
+func (b *Bot) handleInventoryCallback(ctx context.Context, update tgbotapi.Update) {
+	query := update.CallbackQuery
+	chatID := query.Message.Chat.ID
+	user := query.From
+
+	dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+	if err != nil {
+		b.logger.Error("Failed to load user", zap.Int64("user_id", user.ID), zap.Error(err))
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(query.Data, "inventory:used:"):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(query.Data, "inventory:used:"), 10, 64)
+		if _, err := b.dbManager.RemoveInventoryItem(ctx, dbUser.ID, id); err != nil {
+			b.logger.Error("Failed to remove inventory item", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+			b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+			return
+		}
+
+		items, err := b.dbManager.InventoryItems(ctx, dbUser.ID)
+		if err != nil {
+			b.logger.Error("Failed to load inventory", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+			b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+			return
+		}
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.api.Request(tgbotapi.NewEditMessageTextAndMarkup(chatID, query.Message.MessageID,
+			inventoryText(items), inventoryKeyboard(items)))
+
+	case query.Data == "inventory:cook":
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+		started, err := b.startInventoryEditing(ctx, chatID, dbUser.ID)
+		switch {
+		case err != nil:
+			b.logger.Error("Failed to start product editing", zap.Int64("chat_id", chatID), zap.Error(err))
+			b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+		case !started:
+			b.api.Send(tgbotapi.NewMessage(chatID, inventoryText(nil)))
+		}
+
+	case query.Data == "inventory:use_up":
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Генерирую рецепт..."))
+		b.removeInlineKeyboard(chatID, query.Message.MessageID)
+		b.api.Request(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+		started, err := b.cookExpiring(ctx, chatID, dbUser)
+		switch {
+		case err != nil:
+			b.logger.Error("Failed to cook expiring products", zap.Int64("chat_id", chatID), zap.Error(err))
+			b.api.Send(tgbotapi.NewMessage(chatID, "Не удалось сгенерировать рецепт. Попробуйте снова."))
+		case !started:
+			b.api.Send(tgbotapi.NewMessage(chatID,
+				"Продуктов с истекающим сроком больше нет. Все продукты дома — /inventory."))
+		}
+
+	case strings.HasPrefix(query.Data, "inventory:consume:"):
+		recipeID, _ := strconv.Atoi(strings.TrimPrefix(query.Data, "inventory:consume:"))
+		usages, err := b.consumeInventory(ctx, dbUser.ID, int32(recipeID))
+		if err != nil {
+			b.logger.Error("Failed to consume inventory", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+			b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+			return
+		}
+
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Продукты списаны"))
+		text := "Использованных продуктов уже нет в списке /inventory."
+		if len(usages) > 0 {
+			text = "✅ Продукты списаны:\n\n" + usagesText(usages)
+		}
+		b.api.Request(tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, text))
+
+	case query.Data == "inventory:keep":
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.api.Request(tgbotapi.NewDeleteMessage(chatID, query.Message.MessageID))
+
+	default:
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+	}
+}
+
+// consumeInventory списывает продукты, использованные в сохраненном рецепте.
+// Списание считается заново по текущим продуктам: после сохранения рецепта их могли изменить.
+func (b *Bot) consumeInventory(ctx context.Context, userID int32, recipeID int32) ([]inventory.Usage, error) {
+	recipe, err := b.loadRecipe(ctx, userID, recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := b.dbManager.InventoryItems(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inventory: %w", err)
+	}
+
+	usages := inventory.Consume(items, recipe.Ingredients)
+	if err := b.dbManager.ApplyInventoryUsage(ctx, userID, usages); err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
@@ -0,0 +1,176 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+)
+
+// maxStapleLength - ограничение на длину названия запаса в символах:
+// название передается в данных кнопки удаления, а они не длиннее 64 байт
+const maxStapleLength = 24
+
+const pantryUsage = "Запасы — продукты, которые всегда есть дома: соль, масло, вода. " +
+	"Они доступны для любого рецепта и отмечаются в нем значком 🏠.\n\n" +
+	"/pantry — показать запасы\n" +
+	"/pantry add соль, сахар — добавить\n" +
+	"/pantry remove сахар — удалить"
+
+// pantryText - текст сообщения со списком запасов
+func pantryText(staples []string) string {
+	if len(staples) == 0 {
+		return "Запасов пока нет.\n\n" + pantryUsage +
+			"\n\nБазовые запасы: " + strings.Join(recipes.DefaultStaples, ", ") + "."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Запасы:\n")
+	for i, name := range staples {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, name))
+	}
+	sb.WriteString("\nНажмите на продукт, чтобы убрать его из запасов. Добавить: /pantry add соль, сахар")
+	return sb.String()
+}
+
+// pantryKeyboard - кнопки удаления запасов или добавления базовых, если запасов нет
+func pantryKeyboard(staples []string) tgbotapi.InlineKeyboardMarkup {
+	if len(staples) == 0 {
+		return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➕ Добавить базовые запасы", "pantry:defaults"),
+		))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, name := range staples {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ "+name, "pantry:remove:"+name),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// parseStaples разбирает список запасов; слишком длинные названия возвращаются отдельно
+func parseStaples(text string) (staples, rejected []string) {
+	for _, name := range ingredients.Parse(text) {
+		if len([]rune(name)) > maxStapleLength {
+			rejected = append(rejected, name)
+			continue
+		}
+		staples = append(staples, name)
+	}
+	return staples, rejected
+}
+
+// handlePantryCommand обрабатывает команду /pantry: показ, добавление и удаление запасов
+func (b *Bot) handlePantryCommand(ctx context.Context, update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	user := update.Message.From
+
+	dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+	if err != nil {
+		b.logger.Error("Failed to load user", zap.Int64("user_id", user.ID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	// Продукты можно перечислить и с новой строки: "/pantry add\nсоль\nсахар"
+	args := strings.TrimSpace(update.Message.CommandArguments())
+	action := args
+	if i := strings.IndexFunc(args, unicode.IsSpace); i >= 0 {
+		action, args = args[:i], args[i:]
+	} else {
+		args = ""
+	}
+
+	switch strings.ToLower(action) {
+	case "":
+	case "add", "добавить":
+		staples, rejected := parseStaples(args)
+		if len(rejected) > 0 {
+			b.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf(
+				"Слишком длинные названия, сократите их до %d символов: %s", maxStapleLength, strings.Join(rejected, ", "))))
+		}
+		if len(staples) == 0 {
+			if len(rejected) == 0 {
+				b.api.Send(tgbotapi.NewMessage(chatID, "Не удалось разобрать список. Пример: /pantry add соль, сахар"))
+			}
+			return
+		}
+		if _, err := b.dbManager.AddPantryStaples(ctx, dbUser.ID, staples); err != nil {
+			b.logger.Error("Failed to add pantry staples", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+			b.api.Send(tgbotapi.NewMessage(chatID, "Не удалось сохранить запасы. Попробуйте снова."))
+			return
+		}
+	case "remove", "удалить":
+		for _, name := range ingredients.Parse(args) {
+			if _, err := b.dbManager.RemovePantryStaple(ctx, dbUser.ID, name); err != nil {
+				b.logger.Error("Failed to remove pantry staple", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+				b.api.Send(tgbotapi.NewMessage(chatID, "Не удалось удалить запасы. Попробуйте снова."))
+				return
+			}
+		}
+	default:
+		b.api.Send(tgbotapi.NewMessage(chatID, pantryUsage))
+		return
+	}
+
+	b.sendPantry(ctx, chatID, dbUser.ID)
+}
+
+// sendPantry отправляет текущий список запасов с кнопками
+func (b *Bot) sendPantry(ctx context.Context, chatID int64, userID int32) {
+	staples, err := b.dbManager.PantryStaples(ctx, userID)
+	if err != nil {
+		b.logger.Error("Failed to load pantry staples", zap.Int32("user_id", userID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, pantryText(staples))
+	msg.ReplyMarkup = pantryKeyboard(staples)
+	b.api.Send(msg)
+}
+
+// handlePantryCallback обрабатывает кнопки под списком запасов
+func (b *Bot) handlePantryCallback(ctx context.Context, update tgbotapi.Update) {
+	query := update.CallbackQuery
+	chatID := query.Message.Chat.ID
+	user := query.From
+
+	dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+	if err != nil {
+		b.logger.Error("Failed to load user", zap.Int64("user_id", user.ID), zap.Error(err))
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(query.Data, "pantry:remove:"):
+		_, err = b.dbManager.RemovePantryStaple(ctx, dbUser.ID, strings.TrimPrefix(query.Data, "pantry:remove:"))
+	case query.Data == "pantry:defaults":
+		_, err = b.dbManager.AddPantryStaples(ctx, dbUser.ID, recipes.DefaultStaples)
+	}
+	if err != nil {
+		b.logger.Error("Failed to update pantry staples", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	staples, err := b.dbManager.PantryStaples(ctx, dbUser.ID)
+	if err != nil {
+		b.logger.Error("Failed to load pantry staples", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+	b.api.Request(tgbotapi.NewEditMessageTextAndMarkup(chatID, query.Message.MessageID,
+		pantryText(staples), pantryKeyboard(staples)))
+}
@@ -0,0 +1,278 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/dialog"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+)
+
+// Сценарий настройки ограничений питания
+const (
+	preferencesFlow = "preferences"
+
+	// preferencesMenu - пользователь переключает диеты и аллергены кнопками
+	preferencesMenu dialog.State = "menu"
+	// preferencesCalories - бот ждет ограничение калорийности текстом
+	preferencesCalories dialog.State = "awaiting_calories"
+)
+
+// maxCaloriesLimit - больше этого значения ограничение калорийности порции не имеет смысла
+const maxCaloriesLimit = 5000
+
+// preferencesData - данные сценария настройки ограничений
+type preferencesData struct {
+	Diets       []string `json:"diets"`
+	Allergens   []string `json:"allergens"`
+	MaxCalories int      `json:"max_calories"`
+	// MessageID - сообщение с кнопками; кнопки старых сообщений не действуют
+	MessageID int `json:"message_id"`
+}
+
+func (d *preferencesData) preferences() recipes.Preferences {
+	return recipes.Preferences{Diets: d.Diets, Allergens: d.Allergens, MaxCalories: d.MaxCalories}
+}
+
+// toggle включает или выключает ограничение в списке
+func toggle(list []string, id string) []string {
+	if i := slices.Index(list, id); i >= 0 {
+		return slices.Delete(list, i, i+1)
+	}
+	return append(list, id)
+}
+
+// preferencesSummary - текущие ограничения пользователя
+func preferencesSummary(prefs recipes.Preferences) string {
+	names := func(ids []string) string {
+		if len(ids) == 0 {
+			return "нет"
+		}
+		var list []string
+		for _, id := range ids {
+			list = append(list, recipes.RestrictionName(id))
+		}
+		return strings.Join(list, ", ")
+	}
+
+	calories := "не ограничена"
+	if prefs.MaxCalories > 0 {
+		calories = fmt.Sprintf("до %d ккал", prefs.MaxCalories)
+	}
+
+	return fmt.Sprintf("Диеты: %s\nАллергии: %s\nКалорийность порции: %s",
+		names(prefs.Diets), names(prefs.Allergens), calories)
+}
+
+// preferencesText - текст сообщения с настройками
+func preferencesText(data *preferencesData) string {
+	return "Ограничения питания учитываются при генерации рецептов.\n\n" +
+		preferencesSummary(data.preferences()) +
+		"\n\nНажмите на диету или аллерген, чтобы включить или выключить его, и нажмите «Сохранить»."
+}
+
+// preferencesKeyboard - кнопки диет, аллергенов, калорийности и сохранения
+func preferencesKeyboard(data *preferencesData) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	addButtons := func(kind string, restrictions []recipes.Restriction, enabled []string) {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, r := range restrictions {
+			mark := "▫️"
+			if slices.Contains(enabled, r.ID) {
+				mark = "✅"
+			}
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(mark+" "+r.Name,
+				fmt.Sprintf("preferences:%s:%s", kind, r.ID)))
+			if len(row) == 2 {
+				rows = append(rows, row)
+				row = nil
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("— Диеты —", "preferences:noop")))
+	addButtons("diet", recipes.Diets, data.Diets)
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("— Аллергии —", "preferences:noop")))
+	addButtons("allergen", recipes.Allergens, data.Allergens)
+
+	calories := "⚡ Калорийность: не ограничена"
+	if data.MaxCalories > 0 {
+		calories = fmt.Sprintf("⚡ Калорийность: до %d ккал", data.MaxCalories)
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(calories, "preferences:calories"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💾 Сохранить", "preferences:save"),
+		),
+	)
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// newPreferencesFlow описывает сценарий настройки ограничений питания
+func (b *Bot) newPreferencesFlow() *dialog.Flow {
+	return &dialog.Flow{
+		Name:    preferencesFlow,
+		Initial: preferencesMenu,
+		States: map[dialog.State]dialog.StateHandler{
+			preferencesMenu: {
+				OnCallback: b.handlePreferencesCallback,
+				Next:       []dialog.State{preferencesCalories},
+			},
+			preferencesCalories: {
+				OnMessage:  b.handleCaloriesInput,
+				OnCallback: b.handlePreferencesCallback,
+				Next:       []dialog.State{preferencesMenu},
+			},
+		},
+	}
+}
+
+// handlePreferencesCommand обрабатывает команду /preferences
+func (b *Bot) handlePreferencesCommand(ctx context.Context, update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	user := update.Message.From
+
+	if err := b.startPreferencesEditing(ctx, chatID, user); err != nil {
+		b.logger.Error("Failed to start preferences editing", zap.Int64("chat_id", chatID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+	}
+}
+
+// startPreferencesEditing показывает сохраненные ограничения с кнопками и начинает сценарий настройки
+func (b *Bot) startPreferencesEditing(ctx context.Context, chatID int64, user *tgbotapi.User) error {
+	dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+	if err != nil {
+		return fmt.Errorf("failed to load user: %w", err)
+	}
+	prefs, err := b.dbManager.GetPreferences(ctx, dbUser.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load preferences: %w", err)
+	}
+
+	data := &preferencesData{Diets: prefs.Diets, Allergens: prefs.Allergens, MaxCalories: prefs.MaxCalories}
+	if err := b.sendPreferences(chatID, data); err != nil {
+		return err
+	}
+
+	_, err = b.dialogs.Start(ctx, chatID, preferencesFlow, data)
+	return err
+}
+
+// sendPreferences отправляет настройки с кнопками и запоминает новое сообщение в данных сценария
+func (b *Bot) sendPreferences(chatID int64, data *preferencesData) error {
+	msg := tgbotapi.NewMessage(chatID, preferencesText(data))
+	msg.ReplyMarkup = preferencesKeyboard(data)
+	sent, err := b.api.Send(msg)
+	if err != nil {
+		return fmt.Errorf("failed to send preferences: %w", err)
+	}
+	data.MessageID = sent.MessageID
+	return nil
+}
+
+// handlePreferencesCallback обрабатывает кнопки настройки ограничений
+func (b *Bot) handlePreferencesCallback(ctx context.Context, s *dialog.Session, query *tgbotapi.CallbackQuery) (dialog.State, error) {
+	chatID := query.Message.Chat.ID
+	user := query.From
+
+	var data preferencesData
+	if err := s.Decode(&data); err != nil {
+		return s.State, err
+	}
+	if data.MessageID != query.Message.MessageID {
+		b.api.Request(tgbotapi.NewCallbackWithAlert(query.ID,
+			"Эти настройки устарели. Используйте последнее сообщение или /preferences."))
+		b.removeInlineKeyboard(chatID, query.Message.MessageID)
+		return s.State, nil
+	}
+
+	action, id, _ := strings.Cut(strings.TrimPrefix(query.Data, "preferences:"), ":")
+	switch action {
+	case "diet", "allergen":
+		known := func(r recipes.Restriction) bool { return r.ID == id }
+		switch {
+		case action == "diet" && slices.ContainsFunc(recipes.Diets, known):
+			data.Diets = toggle(data.Diets, id)
+		case action == "allergen" && slices.ContainsFunc(recipes.Allergens, known):
+			data.Allergens = toggle(data.Allergens, id)
+		default:
+			b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+			return s.State, nil
+		}
+		if err := s.Encode(&data); err != nil {
+			return s.State, err
+		}
+
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.api.Request(tgbotapi.NewEditMessageTextAndMarkup(chatID, data.MessageID,
+			preferencesText(&data), preferencesKeyboard(&data)))
+		return s.State, nil
+
+	case "calories":
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.api.Send(tgbotapi.NewMessage(chatID,
+			"Напишите, сколько килокалорий может быть в одной порции, например 500. 0 - без ограничения."))
+		return preferencesCalories, nil
+
+	case "save":
+		dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+		if err != nil {
+			return s.State, fmt.Errorf("failed to load user: %w", err)
+		}
+		prefs := data.preferences()
+		if err := b.dbManager.SavePreferences(ctx, dbUser.ID, prefs); err != nil {
+			return s.State, fmt.Errorf("failed to save preferences: %w", err)
+		}
+
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Настройки сохранены"))
+		b.api.Request(tgbotapi.NewEditMessageText(chatID, data.MessageID,
+			"✅ Ограничения питания сохранены.\n\n"+preferencesSummary(prefs)))
+		return dialog.Done, nil
+
+	default:
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+		return s.State, nil
+	}
+}
+
+// handleCaloriesInput принимает ограничение калорийности порции
+func (b *Bot) handleCaloriesInput(ctx context.Context, s *dialog.Session, msg *tgbotapi.Message) (dialog.State, error) {
+	chatID := msg.Chat.ID
+
+	var data preferencesData
+	if err := s.Decode(&data); err != nil {
+		return s.State, err
+	}
+
+	text := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(msg.Text), "ккал"))
+	calories, err := strconv.Atoi(text)
+	if err != nil || calories < 0 || calories > maxCaloriesLimit {
+		b.api.Send(tgbotapi.NewMessage(chatID,
+			fmt.Sprintf("Нужно целое число от 0 до %d. Попробуйте еще раз.", maxCaloriesLimit)))
+		return s.State, nil
+	}
+	data.MaxCalories = calories
+
+	// Старые настройки заменяем новыми, чтобы кнопки оставались под последним сообщением
+	b.removeInlineKeyboard(chatID, data.MessageID)
+	if err := b.sendPreferences(chatID, &data); err != nil {
+		return s.State, err
+	}
+	if err := s.Encode(&data); err != nil {
+		return s.State, err
+	}
+	return preferencesMenu, nil
+}
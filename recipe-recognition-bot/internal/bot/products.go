@@ -0,0 +1,360 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/dialog"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/vision"
+)
+
+// Сценарий правки списка распознанных продуктов
+const (
+	productsFlow = "products"
+
+	// productsEditing - пользователь переключает продукты кнопками
+	productsEditing dialog.State = "editing"
+	// productsAwaiting - бот ждет название продукта текстом
+	productsAwaiting dialog.State = "awaiting_product"
+	// productsTranscript - пользователь проверяет расшифровку голосового сообщения
+	productsTranscript dialog.State = "transcript"
+)
+
+// productChoice - продукт в редактируемом списке
+type productChoice struct {
+	Name     string `json:"name"`
+	Selected bool   `json:"selected"`
+	// Quantity - примерное количество, оцененное по фото
+	Quantity string `json:"quantity,omitempty"`
+	// Uncertain - модель не уверена, что распознала продукт правильно
+	Uncertain bool `json:"uncertain,omitempty"`
+	// Recognized - продукт распознан по фото; после подтверждения списка он попадает в /inventory
+	Recognized bool `json:"recognized,omitempty"`
+}
+
+// newChoices - выбранные продукты из списка названий
+func newChoices(names []string) []productChoice {
+	choices := make([]productChoice, 0, len(names))
+	for _, name := range names {
+		choices = append(choices, productChoice{Name: name, Selected: true})
+	}
+	return choices
+}
+
+// recognizedChoices - выбранные продукты из результата распознавания;
+// продукты с уверенностью ниже порога помечаются как сомнительные
+func recognizedChoices(items []vision.Item, lowConfidence float64) []productChoice {
+	choices := make([]productChoice, 0, len(items))
+	for _, item := range items {
+		choices = append(choices, productChoice{
+			Name:       item.Name,
+			Selected:   true,
+			Quantity:   item.Quantity,
+			Uncertain:  item.Uncertain(lowConfidence),
+			Recognized: true,
+		})
+	}
+	return choices
+}
+
+// productsData - данные сценария правки списка
+type productsData struct {
+	Products []productChoice `json:"products"`
+	// MessageID - сообщение со списком и кнопками; кнопки старых сообщений не действуют
+	MessageID int `json:"message_id"`
+	// Transcript - расшифровка голосового сообщения, из которой получен список
+	Transcript string `json:"transcript,omitempty"`
+}
+
+func (d *productsData) selected() []string {
+	var selected []string
+	for _, p := range d.Products {
+		if p.Selected {
+			selected = append(selected, p.Name)
+		}
+	}
+	return selected
+}
+
+// productsText - текст сообщения со списком продуктов
+func productsText(data *productsData) string {
+	var sb strings.Builder
+	sb.WriteString("Продукты:\n")
+	uncertain := false
+	for i, p := range data.Products {
+		line := fmt.Sprintf("%d. %s %s", i+1, productMark(p), productTitle(p))
+		if p.Quantity != "" {
+			line += " — " + p.Quantity
+		}
+		sb.WriteString(line + "\n")
+		uncertain = uncertain || p.Uncertain
+	}
+	if uncertain {
+		sb.WriteString("\n? — продукт распознан неуверенно, проверьте его.\n")
+	}
+	sb.WriteString("\nНажмите на продукт, чтобы исключить или вернуть его, добавьте недостающие и нажмите «Сгенерировать рецепт».")
+	return sb.String()
+}
+
+// productsKeyboard - кнопки переключения продуктов, добавления и генерации
+func productsKeyboard(data *productsData) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, p := range data.Products {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(productMark(p)+" "+productTitle(p), fmt.Sprintf("products:toggle:%d", i)),
+		))
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➕ Добавить продукт", "products:add"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🍳 Сгенерировать рецепт", "products:generate"),
+		),
+	)
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// productTitle - название продукта со знаком вопроса, если модель в нем не уверена
+func productTitle(p productChoice) string {
+	if p.Uncertain {
+		return p.Name + "?"
+	}
+	return p.Name
+}
+
+func productMark(p productChoice) string {
+	if p.Selected {
+		return "✅"
+	}
+	return "❌"
+}
+
+// newProductsFlow описывает сценарий правки списка продуктов
+func (b *Bot) newProductsFlow() *dialog.Flow {
+	return &dialog.Flow{
+		Name:    productsFlow,
+		Initial: productsEditing,
+		States: map[dialog.State]dialog.StateHandler{
+			productsEditing: {
+				OnCallback: b.handleProductCallback,
+				Next:       []dialog.State{productsAwaiting},
+			},
+			productsAwaiting: {
+				OnMessage:  b.handleProductInput,
+				OnCallback: b.handleProductCallback,
+				Next:       []dialog.State{productsEditing},
+			},
+			productsTranscript: {
+				OnCallback: b.handleProductCallback,
+				Next:       []dialog.State{productsEditing},
+			},
+		},
+	}
+}
+
+// askForProducts просит перечислить продукты текстом и начинает сценарий правки с пустым списком
+func (b *Bot) askForProducts(ctx context.Context, chatID int64) error {
+	if _, err := b.dialogs.StartAt(ctx, chatID, productsFlow, productsAwaiting, &productsData{}); err != nil {
+		return err
+	}
+
+	_, err := b.api.Send(tgbotapi.NewMessage(chatID,
+		"Перечислите продукты через запятую или по одному в строке, например: яйца, помидоры, сыр."))
+	return err
+}
+
+// startTranscriptConfirmation показывает расшифровку голосового сообщения и разобранные продукты;
+// пользователь может сразу сгенерировать рецепт или перейти к правке списка
+func (b *Bot) startTranscriptConfirmation(ctx context.Context, chatID int64, transcript string, items []string) error {
+	data := &productsData{Products: newChoices(items), Transcript: transcript}
+
+	text := fmt.Sprintf("Я расслышал: «%s»\n\nПродукты: %s\n\nВсе верно?",
+		transcript, strings.Join(data.selected(), ", "))
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🍳 Сгенерировать рецепт", "products:generate"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Изменить список", "products:edit"),
+		),
+	)
+	sent, err := b.api.Send(msg)
+	if err != nil {
+		return err
+	}
+	data.MessageID = sent.MessageID
+
+	_, err = b.dialogs.StartAt(ctx, chatID, productsFlow, productsTranscript, data)
+	return err
+}
+
+// startProductEditing показывает продукты с кнопками и начинает сценарий правки
+func (b *Bot) startProductEditing(ctx context.Context, chatID int64, products []productChoice) error {
+	data := &productsData{Products: products}
+
+	if err := b.sendProductList(chatID, data); err != nil {
+		return err
+	}
+
+	_, err := b.dialogs.Start(ctx, chatID, productsFlow, data)
+	return err
+}
+
+// sendProductList отправляет список с кнопками и запоминает новое сообщение в данных сценария
+func (b *Bot) sendProductList(chatID int64, data *productsData) error {
+	msg := tgbotapi.NewMessage(chatID, productsText(data))
+	msg.ReplyMarkup = productsKeyboard(data)
+	sent, err := b.api.Send(msg)
+	if err != nil {
+		return fmt.Errorf("failed to send product list: %w", err)
+	}
+	data.MessageID = sent.MessageID
+	return nil
+}
+
+// handleProductCallback обрабатывает кнопки редактирования списка продуктов
+func (b *Bot) handleProductCallback(ctx context.Context, s *dialog.Session, query *tgbotapi.CallbackQuery) (dialog.State, error) {
+	chatID := query.Message.Chat.ID
+	user := query.From
+
+	var data productsData
+	if err := s.Decode(&data); err != nil {
+		return s.State, err
+	}
+	if data.MessageID != query.Message.MessageID {
+		b.api.Request(tgbotapi.NewCallbackWithAlert(query.ID,
+			"Этот список устарел. Используйте последнее сообщение со списком продуктов."))
+		b.removeInlineKeyboard(chatID, query.Message.MessageID)
+		return s.State, nil
+	}
+
+	switch {
+	case strings.HasPrefix(query.Data, "products:toggle:"):
+		idx, err := strconv.Atoi(strings.TrimPrefix(query.Data, "products:toggle:"))
+		if err != nil || idx < 0 || idx >= len(data.Products) {
+			b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+			return s.State, nil
+		}
+
+		data.Products[idx].Selected = !data.Products[idx].Selected
+		if err := s.Encode(&data); err != nil {
+			return s.State, err
+		}
+
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.api.Request(tgbotapi.NewEditMessageTextAndMarkup(chatID, data.MessageID,
+			productsText(&data), productsKeyboard(&data)))
+		return s.State, nil
+
+	case query.Data == "products:edit":
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.removeInlineKeyboard(chatID, query.Message.MessageID)
+
+		if err := b.sendProductList(chatID, &data); err != nil {
+			return s.State, err
+		}
+		if err := s.Encode(&data); err != nil {
+			return s.State, err
+		}
+		return productsEditing, nil
+
+	case query.Data == "products:add":
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.api.Send(tgbotapi.NewMessage(chatID,
+			"Напишите название продукта. Можно несколько через запятую."))
+		return productsAwaiting, nil
+
+	case query.Data == "products:generate":
+		products := data.selected()
+		if len(products) == 0 {
+			b.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, "Отметьте хотя бы один продукт."))
+			return s.State, nil
+		}
+
+		dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+		if err != nil {
+			return s.State, fmt.Errorf("failed to load user: %w", err)
+		}
+
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Генерирую рецепт..."))
+		b.removeInlineKeyboard(chatID, query.Message.MessageID)
+		b.api.Request(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+
+		// Запоминаем продукты, чтобы по ним можно было попросить другой рецепт без повторной загрузки фото
+		if err := b.dbManager.StartRecognitionSession(ctx, dbUser.ID, products); err != nil {
+			b.logger.Warn("Failed to store recognition session", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+		}
+		// Подтвержденные продукты с фото пополняют список того, что есть дома
+		b.rememberInventory(ctx, dbUser.ID, data.Products)
+
+		// Генерация занимает до нескольких минут, поэтому идет уже после завершения диалога:
+		// обработчик держит блокировку чата, и остальные обновления чата ждали бы рецепт
+		go func() {
+			if err := b.offerRecipe(ctx, chatID, dbUser, recipes.Request{Products: products}); err != nil {
+				b.api.Send(tgbotapi.NewMessage(chatID, "Не удалось сгенерировать рецепт. Попробуйте снова."))
+			}
+		}()
+		return dialog.Done, nil
+
+	default:
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+		return s.State, nil
+	}
+}
+
+// handleProductInput добавляет продукты, присланные текстом
+func (b *Bot) handleProductInput(ctx context.Context, s *dialog.Session, msg *tgbotapi.Message) (dialog.State, error) {
+	chatID := msg.Chat.ID
+
+	var data productsData
+	if err := s.Decode(&data); err != nil {
+		return s.State, err
+	}
+
+	names := ingredients.Parse(msg.Text)
+	if len(names) == 0 {
+		b.api.Send(tgbotapi.NewMessage(chatID, "Не понял название продукта. Попробуйте еще раз."))
+		return s.State, nil
+	}
+
+	for _, name := range names {
+		// Сравниваем по ключу, чтобы «яйцо» не добавлялось рядом с распознанными «яйцами»
+		key := ingredients.Key(name)
+		found := false
+		for i := range data.Products {
+			if ingredients.Key(data.Products[i].Name) == key {
+				// Продукт, названный пользователем, больше не вызывает сомнений
+				data.Products[i].Selected = true
+				data.Products[i].Uncertain = false
+				found = true
+				break
+			}
+		}
+		if !found {
+			data.Products = append(data.Products, productChoice{Name: name, Selected: true})
+		}
+	}
+
+	// Старый список заменяем новым, чтобы кнопки оставались под последним сообщением
+	if data.MessageID != 0 {
+		b.removeInlineKeyboard(chatID, data.MessageID)
+	}
+
+	if err := b.sendProductList(chatID, &data); err != nil {
+		return s.State, err
+	}
+	if err := s.Encode(&data); err != nil {
+		return s.State, err
+	}
+	return productsEditing, nil
+}
@@ -0,0 +1,47 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/speech"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/vision"
+)
+
+func TestHandleProductInputMatchesWordForms(t *testing.T) {
+	tg := newFakeTelegram(map[string][]byte{"files/photo-1": []byte("jpeg")})
+	db := newFakeDB()
+	b := newTestBot(t, tg, db, vision.NewFixtureVision(nil), speech.NewFixtureTranscriber(""))
+	ctx := context.Background()
+
+	b.handlePhotoMessage(ctx, photoUpdate("photo-1"))
+	list := tg.waitMessage(t, "Продукты:")
+
+	// Снимаем отметку, чтобы проверить, что названный продукт снова выбирается
+	b.handleCallbackQuery(ctx, callbackUpdate(list.ID, "products:toggle:0"))
+	b.handleCallbackQuery(ctx, callbackUpdate(list.ID, "products:add"))
+	tg.waitMessage(t, "Напишите название продукта")
+
+	handled, err := b.dialogs.HandleMessage(ctx, &tgbotapi.Message{
+		MessageID: 2,
+		From:      testUser,
+		Chat:      testChat,
+		Text:      "Яйцо, хлеб",
+	})
+	if err != nil || !handled {
+		t.Fatalf("HandleMessage() = %v, %v; want handled", handled, err)
+	}
+
+	text := tg.waitMessage(t, "Продукты:").Params.Get("text")
+	for _, want := range []string{"1. ✅ яйца", "4. ✅ хлеб"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("product list %q does not contain %q", text, want)
+		}
+	}
+	if strings.Contains(strings.ToLower(text), "яйцо") {
+		t.Errorf("product list %q has a duplicate of яйца", text)
+	}
+}
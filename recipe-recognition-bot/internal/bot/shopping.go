@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/shopping"
+)
+
+// shoppingItemText - продукт списка покупок с количеством: "молоко — 1,5 л"
+func shoppingItemText(item shopping.Item) string {
+	if item.Quantity == "" {
+		return item.Name
+	}
+	return item.Name + " — " + item.Quantity
+}
+
+// shoppingText - текст сообщения со списком покупок
+func shoppingText(items []shopping.Item) string {
+	if len(items) == 0 {
+		return "Список покупок пуст. Откройте рецепт в /recipes и нажмите «🛒 В список покупок» — " +
+			"в список попадут продукты, которых нет дома."
+	}
+
+	checked := 0
+	for _, item := range items {
+		if item.Checked {
+			checked++
+		}
+	}
+	return fmt.Sprintf("🛒 Список покупок: куплено %d из %d.\n\nНажмите на продукт, когда купите его.", checked, len(items))
+}
+
+// shoppingKeyboard - продукты списка покупок кнопками и кнопки очистки
+func shoppingKeyboard(items []shopping.Item) tgbotapi.InlineKeyboardMarkup {
+	// Пустой, а не nil список убирает кнопки, когда список очищен
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	hasChecked := false
+	for _, item := range items {
+		mark := "⬜ "
+		if item.Checked {
+			mark = "✅ "
+			hasChecked = true
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(mark+shoppingItemText(item), fmt.Sprintf("shopping:toggle:%d", item.ID)),
+		))
+	}
+
+	var actions []tgbotapi.InlineKeyboardButton
+	if hasChecked {
+		actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("🧹 Убрать купленные", "shopping:clear_checked"))
+	}
+	if len(items) > 0 {
+		actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("🗑 Очистить", "shopping:clear"))
+		rows = append(rows, actions)
+	}
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// handleShoppingCommand обрабатывает команду /shopping
+func (b *Bot) handleShoppingCommand(ctx context.Context, update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	user := update.Message.From
+
+	dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+	if err != nil {
+		b.logger.Error("Failed to load user", zap.Int64("user_id", user.ID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	items, err := b.dbManager.ShoppingItems(ctx, dbUser.ID)
+	if err != nil {
+		b.logger.Error("Failed to load shopping list", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, shoppingText(items))
+	if len(items) > 0 {
+		msg.ReplyMarkup = shoppingKeyboard(items)
+	}
+	b.api.Send(msg)
+}
+
+// addRecipeToShopping добавляет в список покупок ингредиенты сохраненного рецепта,
+// которых нет среди запасов и продуктов дома
+func (b *Bot) addRecipeToShopping(ctx context.Context, userID int32, recipeID int32) ([]shopping.Item, error) {
+	recipe, err := b.loadRecipe(ctx, userID, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	staples, err := b.dbManager.PantryStaples(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pantry staples: %w", err)
+	}
+	items, err := b.dbManager.InventoryItems(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inventory: %w", err)
+	}
+
+	missing := shopping.Missing(recipe.Ingredients, staples, items)
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	return b.dbManager.AddShoppingItems(ctx, userID, recipeID, missing)
+}
+
+// handleShoppingCallback обрабатывает кнопку рецепта «В список покупок» и кнопки списка покупок
+func (b *Bot) handleShoppingCallback(ctx context.Context, update tgbotapi.Update) {
+	query := update.CallbackQuery
+	chatID := query.Message.Chat.ID
+	user := query.From
+
+	dbUser, err := b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+	if err != nil {
+		b.logger.Error("Failed to load user", zap.Int64("user_id", user.ID), zap.Error(err))
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(query.Data, "shopping:add:"):
+		recipeID, _ := strconv.Atoi(strings.TrimPrefix(query.Data, "shopping:add:"))
+		added, err := b.addRecipeToShopping(ctx, dbUser.ID, int32(recipeID))
+		if errors.Is(err, database.ErrRecipeInShoppingList) {
+			b.api.Request(tgbotapi.NewCallbackWithAlert(query.ID,
+				"Этот рецепт уже в списке покупок. Весь список: /shopping"))
+			return
+		}
+		if err != nil {
+			b.logger.Error("Failed to add recipe to shopping list", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+			b.api.Request(tgbotapi.NewCallback(query.ID, "Не удалось обновить список покупок. Попробуйте снова."))
+			return
+		}
+		if len(added) == 0 {
+			b.api.Request(tgbotapi.NewCallbackWithAlert(query.ID, "Все продукты для этого рецепта уже есть дома."))
+			return
+		}
+
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Добавлено в список покупок"))
+		var sb strings.Builder
+		sb.WriteString("🛒 В списке покупок:\n")
+		for _, item := range added {
+			sb.WriteString("• " + shoppingItemText(item) + "\n")
+		}
+		sb.WriteString("\nВесь список: /shopping")
+		b.api.Send(tgbotapi.NewMessage(chatID, sb.String()))
+		return
+
+	case query.Data == "shopping:clear":
+		// Очистка всего списка необратима, поэтому сначала спрашиваем подтверждение
+		b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+		b.api.Request(tgbotapi.NewEditMessageTextAndMarkup(chatID, query.Message.MessageID,
+			"Очистить весь список покупок?",
+			tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🗑 Да, очистить", "shopping:clear_all"),
+				tgbotapi.NewInlineKeyboardButtonData("Отмена", "shopping:show"),
+			))))
+		return
+
+	case strings.HasPrefix(query.Data, "shopping:toggle:"):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(query.Data, "shopping:toggle:"), 10, 64)
+		_, err = b.dbManager.ToggleShoppingItem(ctx, dbUser.ID, id)
+	case query.Data == "shopping:clear_checked":
+		_, err = b.dbManager.ClearShoppingList(ctx, dbUser.ID, true)
+	case query.Data == "shopping:clear_all":
+		_, err = b.dbManager.ClearShoppingList(ctx, dbUser.ID, false)
+	}
+	if err != nil {
+		b.logger.Error("Failed to update shopping list", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	items, err := b.dbManager.ShoppingItems(ctx, dbUser.ID)
+	if err != nil {
+		b.logger.Error("Failed to load shopping list", zap.Int32("user_id", dbUser.ID), zap.Error(err))
+		b.api.Request(tgbotapi.NewCallback(query.ID, "Произошла ошибка. Попробуйте снова."))
+		return
+	}
+
+	b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+	b.api.Request(tgbotapi.NewEditMessageTextAndMarkup(chatID, query.Message.MessageID,
+		shoppingText(items), shoppingKeyboard(items)))
+}
@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+)
+
+// handleVoiceMessage распознает продиктованный список продуктов и показывает расшифровку для подтверждения
+func (b *Bot) handleVoiceMessage(ctx context.Context, update tgbotapi.Update) {
+	user := update.Message.From
+	chatID := update.Message.Chat.ID
+	voice := update.Message.Voice
+
+	b.dbManager.GetUserOrCreate(ctx, user.ID, user.UserName, user.FirstName, user.LastName)
+
+	if b.maxVoiceDuration > 0 && time.Duration(voice.Duration)*time.Second > b.maxVoiceDuration {
+		b.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf(
+			"Голосовое сообщение слишком длинное. Продиктуйте продукты короче, чем за %d сек.",
+			int(b.maxVoiceDuration.Seconds()))))
+		return
+	}
+
+	b.api.Request(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+
+	audio, err := b.downloadFile(ctx, voice.FileID, 0)
+	if err != nil {
+		b.logger.Error("Failed to download voice message", zap.Int64("chat_id", chatID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Не удалось загрузить голосовое сообщение. Попробуйте снова."))
+		return
+	}
+
+	// Telegram присылает голосовые сообщения в OGG/Opus
+	text, err := b.transcriber.Transcribe(ctx, bytes.NewReader(audio), "voice.ogg")
+	if err != nil {
+		b.logger.Error("Voice transcription failed", zap.Int64("chat_id", chatID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID,
+			"Не удалось распознать речь. Попробуйте еще раз или перечислите продукты текстом."))
+		return
+	}
+
+	items := ingredients.Parse(text)
+	if len(items) == 0 {
+		b.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf(
+			"Я расслышал: «%s», но не нашел в этом продуктов. Попробуйте еще раз.", text)))
+		return
+	}
+
+	if err := b.startTranscriptConfirmation(ctx, chatID, text, items); err != nil {
+		b.logger.Error("Failed to start transcript confirmation", zap.Int64("chat_id", chatID), zap.Error(err))
+		b.api.Send(tgbotapi.NewMessage(chatID, "Произошла ошибка. Попробуйте снова."))
+	}
+}
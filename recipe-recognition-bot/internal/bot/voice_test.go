@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/speech"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/vision"
+)
+
+func voiceUpdate(fileID string, duration int) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		MessageID: 1,
+		From:      testUser,
+		Chat:      testChat,
+		Voice:     &tgbotapi.Voice{FileID: fileID, Duration: duration},
+	}}
+}
+
+func TestHandleVoiceMessageGeneratesRecipe(t *testing.T) {
+	tg := newFakeTelegram(map[string][]byte{"files/voice-1": []byte("ogg")})
+	db := newFakeDB()
+	b := newTestBot(t, tg, db, vision.NewFixtureVision(nil), speech.NewFixtureTranscriber("яйца, помидоры, сыр"))
+	ctx := context.Background()
+
+	b.handleVoiceMessage(ctx, voiceUpdate("voice-1", 5))
+
+	confirmation := tg.waitMessage(t, "Я расслышал: «яйца, помидоры, сыр»")
+	if !strings.Contains(confirmation.Params.Get("text"), "Продукты: яйца, помидоры, сыр") {
+		t.Errorf("confirmation %q does not list the products", confirmation.Params.Get("text"))
+	}
+	if flow, state, ok := db.dialog(testChat.ID); !ok || flow != productsFlow || state != string(productsTranscript) {
+		t.Fatalf("dialog = %q/%q (exists %v), want %s/%s", flow, state, ok, productsFlow, productsTranscript)
+	}
+
+	b.handleCallbackQuery(ctx, callbackUpdate(confirmation.ID, "products:generate"))
+
+	tg.waitMessage(t, "Блюдо из: яйца, помидоры, сыр")
+	// Продиктованные продукты не распознаны по фото и не попадают в /inventory
+	if db.count("UpsertInventoryItems") != 0 {
+		t.Error("dictated products were added to the inventory")
+	}
+}
+
+func TestHandleVoiceMessageRejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		transcriber speech.Transcriber
+		fileID      string
+		duration    int
+		want        string
+	}{
+		{
+			name:        "ошибка распознавания речи",
+			transcriber: speech.NewFailingFixtureTranscriber(errors.New("model is unavailable")),
+			fileID:      "voice-1",
+			duration:    5,
+			want:        "Не удалось распознать речь",
+		},
+		{
+			name:        "слишком длинное сообщение",
+			transcriber: speech.NewFixtureTranscriber(""),
+			fileID:      "voice-1",
+			duration:    120,
+			want:        "Голосовое сообщение слишком длинное",
+		},
+		{
+			name:        "файл не загружен",
+			transcriber: speech.NewFixtureTranscriber(""),
+			fileID:      "missing",
+			duration:    5,
+			want:        "Не удалось загрузить голосовое сообщение",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tg := newFakeTelegram(map[string][]byte{"files/voice-1": []byte("ogg")})
+			db := newFakeDB()
+			b := newTestBot(t, tg, db, vision.NewFixtureVision(nil), tt.transcriber)
+
+			b.handleVoiceMessage(context.Background(), voiceUpdate(tt.fileID, tt.duration))
+
+			tg.waitMessage(t, tt.want)
+			if _, _, ok := db.dialog(testChat.ID); ok {
+				t.Error("product dialog started without a transcript")
+			}
+		})
+	}
+}
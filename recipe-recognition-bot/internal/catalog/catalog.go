@@ -0,0 +1,65 @@
+package catalog
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+)
+
+// maxNameWords - сколько слов названия с упаковки остается в названии для рецепта:
+// первые слова обычно называют сам продукт, остальные - сорт и способ обработки
+const maxNameWords = 3
+
+// Product - товар из справочника штрихкодов
+type Product struct {
+	// Barcode - код EAN-13
+	Barcode string
+	// Name - название для рецептов: без бренда, объема и жирности
+	Name     string
+	FullName string
+	Brand    string
+}
+
+var (
+	// withDigits - слова с цифрами: объем, масса, жирность ("930мл", "3,2%")
+	withDigits = regexp.MustCompile(`\S*\d\S*`)
+	// separators - все, кроме букв, цифр, пробелов и дефисов
+	separators = regexp.MustCompile(`[^\p{L}\p{N}\s-]+`)
+)
+
+// units - единицы измерения, которые остаются от количества, записанного через пробел: "925 мл"
+var units = map[string]bool{
+	"г": true, "гр": true, "кг": true, "мл": true, "л": true, "шт": true,
+	"g": true, "kg": true, "ml": true, "cl": true, "l": true, "oz": true,
+}
+
+// IngredientName строит название продукта для рецепта из названия с упаковки:
+// "Молоко ультрапастеризованное Домик в деревне 3,2% 925 мл" -> "молоко ультрапастеризованное".
+// Пустая строка - из названия не удалось получить продукт.
+func IngredientName(fullName, brands string) string {
+	name := strings.ToLower(fullName)
+	for _, brand := range strings.Split(brands, ",") {
+		if brand = strings.ToLower(strings.TrimSpace(brand)); brand != "" {
+			name = strings.ReplaceAll(name, brand, " ")
+		}
+	}
+	name = withDigits.ReplaceAllString(name, " ")
+	name = separators.ReplaceAllString(name, " ")
+
+	var words []string
+	for _, word := range strings.Fields(name) {
+		if !units[word] {
+			words = append(words, word)
+		}
+	}
+	if len(words) > maxNameWords {
+		words = words[:maxNameWords]
+	}
+
+	name = ingredients.Normalize(strings.Join(words, " "))
+	if !ingredients.Valid(name) {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,109 @@
+package catalog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/barcode"
+)
+
+// maxLineSize - самая длинная строка выгрузки; в ней бывают длинные списки ингредиентов и тегов
+const maxLineSize = 16 << 20
+
+// OpenFoodFactsOptions - фильтры импорта выгрузки Open Food Facts
+type OpenFoodFactsOptions struct {
+	// Country - тег страны продажи, например "en:russia"; пустая строка - все страны
+	Country string
+}
+
+// OpenFoodFactsReader читает товары из CSV-выгрузки Open Food Facts
+// (en.openfoodfacts.org.products.csv): значения разделены табуляцией без кавычек,
+// первая строка - заголовок. Нужны колонки code и product_name;
+// generic_name, brands и countries_tags используются, если есть.
+type OpenFoodFactsReader struct {
+	scanner *bufio.Scanner
+	columns map[string]int
+	opts    OpenFoodFactsOptions
+	line    int
+}
+
+// NewOpenFoodFactsReader читает заголовок выгрузки
+func NewOpenFoodFactsReader(r io.Reader, opts OpenFoodFactsOptions) (*OpenFoodFactsReader, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("empty Open Food Facts dump")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range strings.Split(scanner.Text(), "\t") {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"code", "product_name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("no %q column in Open Food Facts dump", required)
+		}
+	}
+
+	return &OpenFoodFactsReader{scanner: scanner, columns: columns, opts: opts, line: 1}, nil
+}
+
+// Next возвращает следующий товар с корректным штрихкодом и названием.
+// Остальные строки пропускаются; в конце выгрузки возвращается io.EOF.
+func (r *OpenFoodFactsReader) Next() (Product, error) {
+	for r.scanner.Scan() {
+		r.line++
+		fields := strings.Split(r.scanner.Text(), "\t")
+
+		if r.opts.Country != "" && !hasTag(r.field(fields, "countries_tags"), r.opts.Country) {
+			continue
+		}
+
+		code, ok := barcode.Normalize(r.field(fields, "code"))
+		if !ok {
+			continue
+		}
+
+		fullName := r.field(fields, "product_name")
+		brand := r.field(fields, "brands")
+		// Общее название ("молоко питьевое") лучше подходит для рецепта, чем торговое
+		name := IngredientName(r.field(fields, "generic_name"), brand)
+		if name == "" {
+			name = IngredientName(fullName, brand)
+		}
+		if name == "" {
+			continue
+		}
+
+		return Product{Barcode: code, Name: name, FullName: fullName, Brand: brand}, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Product{}, fmt.Errorf("line %d: %w", r.line+1, err)
+	}
+	return Product{}, io.EOF
+}
+
+func (r *OpenFoodFactsReader) field(fields []string, column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(fields) {
+		return ""
+	}
+	return strings.TrimSpace(fields[i])
+}
+
+// hasTag проверяет, есть ли тег в списке тегов через запятую
+func hasTag(tags, tag string) bool {
+	for _, t := range strings.Split(tags, ",") {
+		if strings.TrimSpace(t) == tag {
+			return true
+		}
+	}
+	return false
+}
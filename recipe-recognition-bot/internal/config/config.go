@@ -5,6 +5,8 @@ import (
 	"github.com/joho/godotenv"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config содержит все конфигурационные параметры приложения
@@ -15,6 +17,78 @@ type Config struct {
 	LogLevel          string
 	AppEnvironment    string
 	MaxRecipesPerUser int
+	// DraftTTL - сколько несохраненный рецепт доступен для сохранения и перегенерации
+	DraftTTL time.Duration
+	// DialogTimeout - через сколько бездействия сбрасывается многошаговый диалог
+	DialogTimeout time.Duration
+	// AlbumWindow - сколько ждать следующее фото альбома
+	AlbumWindow time.Duration
+	// ImageMaxSize - изображения больше этого размера в байтах не загружаются
+	ImageMaxSize int64
+
+	// Бэкенд распознавания продуктов
+	VisionProvider     string
+	VisionAPIKey       string
+	VisionBaseURL      string
+	VisionModel        string
+	VisionMaxTokens    int
+	VisionFixtureItems []string
+	VisionStructured   bool
+	// VisionLowConfidence - порог уверенности, ниже которого продукт помечается знаком вопроса
+	VisionLowConfidence float64
+	// VisionAnnotate - отправлять пользователю фото с рамками вокруг распознанных продуктов
+	VisionAnnotate bool
+	// Подготовка изображений: ограничение большей стороны (0 - без уменьшения) и качество JPEG
+	ImageMaxDimension int
+	ImageJPEGQuality  int
+	// Кэш распознавания фото по перцептивному хешу
+	RecognitionCacheEnabled     bool
+	RecognitionCacheTTL         time.Duration
+	RecognitionCacheMaxDistance int
+	// BarcodeScanEnabled - искать на фото штрихкоды товаров из справочника
+	BarcodeScanEnabled bool
+
+	// Бэкенд распознавания речи для голосовых сообщений
+	SpeechProvider    string
+	SpeechAPIKey      string
+	SpeechBaseURL     string
+	SpeechModel       string
+	SpeechLanguage    string
+	SpeechFixtureText string
+	// VoiceMaxDuration - голосовые сообщения длиннее не распознаются
+	VoiceMaxDuration time.Duration
+
+	// Бэкенд генерации рецептов
+	RecipeProvider    string
+	RecipeAPIKey      string
+	RecipeBaseURL     string
+	RecipeModel       string
+	RecipeTemperature float32
+	RecipeMaxTokens   int
+	// RecipeModelChain - упорядоченный список "провайдер:модель", опрашиваемых по очереди
+	RecipeModelChain []string
+	RecipeStructured bool
+	// Кэш сгенерированных рецептов по набору продуктов
+	RecipeCacheEnabled     bool
+	RecipeCacheTTL         time.Duration
+	RecipeCacheMaxVariants int
+	// RecipeMaxRegenerations - сколько раз перегенерировать рецепт, нарушающий ограничения питания пользователя
+	RecipeMaxRegenerations int
+
+	// Ежедневные напоминания о продуктах с истекающим сроком годности
+	ExpiryRemindersEnabled bool
+	// ExpiryReminderTime - время рассылки от начала суток по местному времени сервера
+	ExpiryReminderTime time.Duration
+	// ExpiryReminderDays - за сколько дней до конца срока напоминать о продукте
+	ExpiryReminderDays int
+
+	// Устойчивость вызовов LLM: повторы, дедлайн попытки и автоматический выключатель
+	LLMMaxAttempts      int
+	LLMBaseDelay        time.Duration
+	LLMMaxDelay         time.Duration
+	LLMCallTimeout      time.Duration
+	LLMBreakerThreshold int
+	LLMBreakerCooldown  time.Duration
 }
 
 // LoadConfig загружает конфигурацию из переменных окружения
@@ -32,13 +106,68 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	openAIKey := os.Getenv("OPENAI_API_KEY")
+
 	return &Config{
 		TelegramToken:     os.Getenv("TELEGRAM_TOKEN"),
-		OpenAIAPIKey:      os.Getenv("OPENAI_API_KEY"),
+		OpenAIAPIKey:      openAIKey,
 		PostgresURI:       os.Getenv("POSTGRES_URI"),
 		LogLevel:          getEnvOrDefault("LOG_LEVEL", "info"),
 		AppEnvironment:    getEnvOrDefault("APP_ENVIRONMENT", "development"),
 		MaxRecipesPerUser: maxRecipes,
+		DraftTTL:          getEnvDurationOrDefault("DRAFT_TTL", 30*time.Minute),
+		DialogTimeout:     getEnvDurationOrDefault("DIALOG_TIMEOUT", 24*time.Hour),
+		AlbumWindow:       getEnvDurationOrDefault("ALBUM_WINDOW", 1500*time.Millisecond),
+		ImageMaxSize:      int64(getEnvIntOrDefault("IMAGE_MAX_SIZE_MB", 10)) << 20,
+
+		VisionProvider:      getEnvOrDefault("VISION_PROVIDER", "openai"),
+		VisionAPIKey:        getEnvOrDefault("VISION_API_KEY", openAIKey),
+		VisionBaseURL:       os.Getenv("VISION_BASE_URL"),
+		VisionModel:         os.Getenv("VISION_MODEL"),
+		VisionMaxTokens:     getEnvIntOrDefault("VISION_MAX_TOKENS", 0),
+		VisionFixtureItems:  getEnvList("VISION_FIXTURE_ITEMS"),
+		VisionStructured:    getEnvBool("VISION_STRUCTURED_OUTPUT"),
+		VisionLowConfidence: float64(getEnvFloatOrDefault("VISION_LOW_CONFIDENCE", 0.6)),
+		VisionAnnotate:      getEnvBool("VISION_ANNOTATE"),
+		ImageMaxDimension:   getEnvIntOrDefault("IMAGE_MAX_DIMENSION", 1024),
+		ImageJPEGQuality:    getEnvIntOrDefault("IMAGE_JPEG_QUALITY", 85),
+
+		RecognitionCacheEnabled:     getEnvBoolOrDefault("RECOGNITION_CACHE_ENABLED", true),
+		RecognitionCacheTTL:         getEnvDurationOrDefault("RECOGNITION_CACHE_TTL", 24*time.Hour),
+		RecognitionCacheMaxDistance: getEnvIntOrDefault("RECOGNITION_CACHE_MAX_DISTANCE", 6),
+		BarcodeScanEnabled:          getEnvBoolOrDefault("BARCODE_SCAN_ENABLED", true),
+
+		SpeechProvider:    getEnvOrDefault("SPEECH_PROVIDER", "openai"),
+		SpeechAPIKey:      getEnvOrDefault("SPEECH_API_KEY", openAIKey),
+		SpeechBaseURL:     os.Getenv("SPEECH_BASE_URL"),
+		SpeechModel:       os.Getenv("SPEECH_MODEL"),
+		SpeechLanguage:    getEnvOrDefault("SPEECH_LANGUAGE", "ru"),
+		SpeechFixtureText: os.Getenv("SPEECH_FIXTURE_TEXT"),
+		VoiceMaxDuration:  getEnvDurationOrDefault("VOICE_MAX_DURATION", time.Minute),
+
+		RecipeProvider:         getEnvOrDefault("RECIPE_PROVIDER", "openai"),
+		RecipeAPIKey:           getEnvOrDefault("RECIPE_API_KEY", openAIKey),
+		RecipeBaseURL:          os.Getenv("RECIPE_BASE_URL"),
+		RecipeModel:            os.Getenv("RECIPE_MODEL"),
+		RecipeTemperature:      getEnvFloatOrDefault("RECIPE_TEMPERATURE", 0.7),
+		RecipeMaxTokens:        getEnvIntOrDefault("RECIPE_MAX_TOKENS", 1000),
+		RecipeModelChain:       getEnvList("RECIPE_MODEL_CHAIN"),
+		RecipeStructured:       getEnvBool("RECIPE_STRUCTURED_OUTPUT"),
+		RecipeCacheEnabled:     getEnvBoolOrDefault("RECIPE_CACHE_ENABLED", false),
+		RecipeCacheTTL:         getEnvDurationOrDefault("RECIPE_CACHE_TTL", 6*time.Hour),
+		RecipeCacheMaxVariants: getEnvIntOrDefault("RECIPE_CACHE_MAX_VARIANTS", 3),
+		RecipeMaxRegenerations: getEnvIntOrDefault("RECIPE_MAX_REGENERATIONS", 1),
+
+		ExpiryRemindersEnabled: getEnvBoolOrDefault("EXPIRY_REMINDERS_ENABLED", true),
+		ExpiryReminderTime:     getEnvTimeOfDayOrDefault("EXPIRY_REMINDER_TIME", 10*time.Hour),
+		ExpiryReminderDays:     getEnvIntOrDefault("EXPIRY_REMINDER_DAYS", 2),
+
+		LLMMaxAttempts:      getEnvIntOrDefault("LLM_MAX_ATTEMPTS", 3),
+		LLMBaseDelay:        getEnvDurationOrDefault("LLM_BASE_DELAY", 500*time.Millisecond),
+		LLMMaxDelay:         getEnvDurationOrDefault("LLM_MAX_DELAY", 10*time.Second),
+		LLMCallTimeout:      getEnvDurationOrDefault("LLM_CALL_TIMEOUT", 60*time.Second),
+		LLMBreakerThreshold: getEnvIntOrDefault("LLM_BREAKER_THRESHOLD", 5),
+		LLMBreakerCooldown:  getEnvDurationOrDefault("LLM_BREAKER_COOLDOWN", 30*time.Second),
 	}, nil
 }
 
@@ -48,3 +177,65 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloatOrDefault(key string, defaultValue float32) float32 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 32); err == nil && parsed >= 0 {
+			return float32(parsed)
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvTimeOfDayOrDefault разбирает время суток "ЧЧ:ММ" и возвращает его смещение от полуночи
+func getEnvTimeOfDayOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.Parse("15:04", value); err == nil {
+			return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string) bool {
+	parsed, err := strconv.ParseBool(os.Getenv(key))
+	return err == nil && parsed
+}
+
+func getEnvBoolOrDefault(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList разбирает список значений, разделенных запятыми
+func getEnvList(key string) []string {
+	var values []string
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/dialog"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DBManager служит хранилищем диалогов
+var _ dialog.Store = (*DBManager)(nil)
+
+// LoadDialog возвращает диалог чата или nil, если его нет
+func (m *DBManager) LoadDialog(ctx context.Context, chatID int64) (*dialog.Session, error) {
+	row, err := m.Queries.GetDialogSession(ctx, chatID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dialog.Session{
+		ChatID:    row.ChatID,
+		Flow:      row.Flow,
+		State:     dialog.State(row.State),
+		Data:      row.Data,
+		ExpiresAt: row.ExpiresAt.Time,
+	}, nil
+}
+
+// SaveDialog сохраняет диалог чата
+func (m *DBManager) SaveDialog(ctx context.Context, s *dialog.Session) error {
+	data := []byte(s.Data)
+	if len(data) == 0 {
+		data = []byte("{}")
+	}
+
+	return m.Queries.UpsertDialogSession(ctx, database.UpsertDialogSessionParams{
+		ChatID:    s.ChatID,
+		Flow:      s.Flow,
+		State:     string(s.State),
+		Data:      data,
+		ExpiresAt: pgtype.Timestamptz{Time: s.ExpiresAt, Valid: true},
+	})
+}
+
+// DeleteDialog удаляет диалог чата
+func (m *DBManager) DeleteDialog(ctx context.Context, chatID int64) error {
+	return m.Queries.DeleteDialogSession(ctx, chatID)
+}
+
+// DeleteExpiredDialogs удаляет диалоги, истекшие к моменту now
+func (m *DBManager) DeleteExpiredDialogs(ctx context.Context, now time.Time) (int64, error) {
+	return m.Queries.DeleteExpiredDialogSessions(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+}
@@ -8,13 +8,114 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type RecipeBotDialogSession struct {
+	ChatID int64 `db:"chat_id" json:"chatId"`
+	// имя сценария: products, preferences, ...
+	Flow  string `db:"flow" json:"flow"`
+	State string `db:"state" json:"state"`
+	// данные сценария
+	Data      []byte             `db:"data" json:"data"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updatedAt"`
+}
+
+type RecipeBotExpiryReminder struct {
+	UserID int32              `db:"user_id" json:"userId"`
+	SentAt pgtype.Timestamptz `db:"sent_at" json:"sentAt"`
+}
+
+type RecipeBotInventoryItem struct {
+	ID     int64 `db:"id" json:"id"`
+	UserID int32 `db:"user_id" json:"userId"`
+	// ключ сравнения: одинаковый для "яйца" и "яйцо"
+	ItemKey string `db:"item_key" json:"itemKey"`
+	Name    string `db:"name" json:"name"`
+	// оценка количества: "6 шт", "около 500 г"
+	Quantity string             `db:"quantity" json:"quantity"`
+	AddedAt  pgtype.Timestamptz `db:"added_at" json:"addedAt"`
+	// NULL - срок неизвестен
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+	// срок оценен по категории продукта, а не указан пользователем
+	ExpiryEstimated bool `db:"expiry_estimated" json:"expiryEstimated"`
+}
+
+type RecipeBotPantryStaple struct {
+	UserID int32 `db:"user_id" json:"userId"`
+	// нормализованное название: "масло растительное"
+	Name      string             `db:"name" json:"name"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+}
+
+type RecipeBotProduct struct {
+	// EAN-13; UPC-A дополняется ведущим нулем
+	Barcode string `db:"barcode" json:"barcode"`
+	// название для рецептов: "молоко"
+	Name string `db:"name" json:"name"`
+	// название с упаковки
+	FullName  string             `db:"full_name" json:"fullName"`
+	Brand     string             `db:"brand" json:"brand"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updatedAt"`
+}
+
 type RecipeBotRecipe struct {
-	ID            int32              `db:"id" json:"id"`
-	UserID        int32              `db:"user_id" json:"userId"`
-	RecipeTitle   string             `db:"recipe_title" json:"recipeTitle"`
-	RecipeContent string             `db:"recipe_content" json:"recipeContent"`
+	ID            int32       `db:"id" json:"id"`
+	UserID        int32       `db:"user_id" json:"userId"`
+	RecipeTitle   string      `db:"recipe_title" json:"recipeTitle"`
+	RecipeContent pgtype.Text `db:"recipe_content" json:"recipeContent"`
+	// Список ингредиентов [{name, quantity, unit, optional}]
 	Ingredients   []byte             `db:"ingredients" json:"ingredients"`
 	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	Model         pgtype.Text        `db:"model" json:"model"`
+	Document      []byte             `db:"document" json:"document"`
+	SchemaVersion int32              `db:"schema_version" json:"schemaVersion"`
+}
+
+type RecipeBotRecipeCache struct {
+	ID int64 `db:"id" json:"id"`
+	// sha256 набора продуктов, версии документа и предпочтений
+	CacheKey  string             `db:"cache_key" json:"cacheKey"`
+	Document  []byte             `db:"document" json:"document"`
+	Model     string             `db:"model" json:"model"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+}
+
+type RecipeBotRecognitionCache struct {
+	ID int64 `db:"id" json:"id"`
+	// dHash изображения, 64 бита
+	ImageHash int64 `db:"image_hash" json:"imageHash"`
+	// распознанные продукты [{name, confidence, quantity, box}]
+	Items     []byte             `db:"items" json:"items"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+}
+
+type RecipeBotRecognitionSession struct {
+	UserID int32 `db:"user_id" json:"userId"`
+	// список продуктов ["яйца", "сыр"]
+	Items           []byte             `db:"items" json:"items"`
+	SuggestedTitles []byte             `db:"suggested_titles" json:"suggestedTitles"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"createdAt"`
+	UpdatedAt       pgtype.Timestamptz `db:"updated_at" json:"updatedAt"`
+}
+
+type RecipeBotShoppingItem struct {
+	ID     int64 `db:"id" json:"id"`
+	UserID int32 `db:"user_id" json:"userId"`
+	// ключ сравнения: одинаковый для "яйца" и "яйцо"
+	ItemKey string `db:"item_key" json:"itemKey"`
+	Name    string `db:"name" json:"name"`
+	// сумма количеств из рецептов: "1,5 л", "2 шт + 200 г"
+	Quantity string `db:"quantity" json:"quantity"`
+	// продукт куплен
+	Checked bool               `db:"checked" json:"checked"`
+	AddedAt pgtype.Timestamptz `db:"added_at" json:"addedAt"`
+}
+
+type RecipeBotShoppingRecipe struct {
+	UserID   int32              `db:"user_id" json:"userId"`
+	RecipeID int32              `db:"recipe_id" json:"recipeId"`
+	AddedAt  pgtype.Timestamptz `db:"added_at" json:"addedAt"`
 }
 
 type RecipeBotUser struct {
@@ -26,3 +127,14 @@ type RecipeBotUser struct {
 	CreatedAt        pgtype.Timestamptz `db:"created_at" json:"createdAt"`
 	UpdatedAt        pgtype.Timestamptz `db:"updated_at" json:"updatedAt"`
 }
+
+type RecipeBotUserPreference struct {
+	UserID int32 `db:"user_id" json:"userId"`
+	// vegetarian, vegan, halal, lactose_free, gluten_free
+	Diets []string `db:"diets" json:"diets"`
+	// nuts, peanuts, milk, eggs, fish, shellfish, soy, sesame
+	Allergens []string `db:"allergens" json:"allergens"`
+	// ккал на порцию; 0 - без ограничения
+	MaxCalories int32              `db:"max_calories" json:"maxCalories"`
+	UpdatedAt   pgtype.Timestamptz `db:"updated_at" json:"updatedAt"`
+}
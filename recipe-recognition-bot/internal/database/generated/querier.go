@@ -6,16 +6,61 @@ package database
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AddPantryStaples(ctx context.Context, arg AddPantryStaplesParams) (int64, error)
+	AddShoppingRecipe(ctx context.Context, arg AddShoppingRecipeParams) (int64, error)
+	AddSuggestedTitle(ctx context.Context, arg AddSuggestedTitleParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (RecipeBotUser, error)
+	DeleteCheckedShoppingItems(ctx context.Context, userID int32) (int64, error)
+	DeleteDialogSession(ctx context.Context, chatID int64) error
+	DeleteExpiredCachedRecipes(ctx context.Context) (int64, error)
+	DeleteExpiredDialogSessions(ctx context.Context, expiresAt pgtype.Timestamptz) (int64, error)
+	DeleteExpiredRecognitions(ctx context.Context) (int64, error)
+	DeleteInventoryItem(ctx context.Context, arg DeleteInventoryItemParams) (int64, error)
+	DeletePantryStaple(ctx context.Context, arg DeletePantryStapleParams) (int64, error)
 	DeleteRecipe(ctx context.Context, arg DeleteRecipeParams) error
+	DeleteShoppingItems(ctx context.Context, userID int32) (int64, error)
+	DeleteShoppingRecipes(ctx context.Context, userID int32) error
+	FindCachedRecognition(ctx context.Context, arg FindCachedRecognitionParams) (FindCachedRecognitionRow, error)
+	FindCachedRecognitionByHash(ctx context.Context, imageHash int64) ([]byte, error)
+	GetDialogSession(ctx context.Context, chatID int64) (RecipeBotDialogSession, error)
 	GetRecipe(ctx context.Context, arg GetRecipeParams) (RecipeBotRecipe, error)
+	GetRecognitionSession(ctx context.Context, userID int32) (RecipeBotRecognitionSession, error)
 	GetUserByTelegramID(ctx context.Context, telegramID int64) (RecipeBotUser, error)
+	GetUserPreferences(ctx context.Context, userID int32) (RecipeBotUserPreference, error)
+	ListCachedRecipes(ctx context.Context, cacheKey string) ([]RecipeBotRecipeCache, error)
+	// Продукты с истекающим сроком у пользователей, которым сегодня еще не напоминали
+	ListExpiringInventory(ctx context.Context, arg ListExpiringInventoryParams) ([]ListExpiringInventoryRow, error)
+	ListInventoryItems(ctx context.Context, userID int32) ([]RecipeBotInventoryItem, error)
+	ListPantryStaples(ctx context.Context, userID int32) ([]RecipeBotPantryStaple, error)
+	ListProductsByBarcodes(ctx context.Context, barcodes []string) ([]RecipeBotProduct, error)
+	ListRecipesWithoutDocument(ctx context.Context, limit int32) ([]ListRecipesWithoutDocumentRow, error)
+	ListShoppingItems(ctx context.Context, userID int32) ([]RecipeBotShoppingItem, error)
 	ListUserRecipes(ctx context.Context, arg ListUserRecipesParams) ([]RecipeBotRecipe, error)
+	// Блокирует список покупок пользователя до конца транзакции, в том числе пустой список.
+	// NO KEY UPDATE не мешает вставке строк, ссылающихся на пользователя.
+	LockShoppingList(ctx context.Context, id int32) error
+	SaveCachedRecipe(ctx context.Context, arg SaveCachedRecipeParams) error
+	SaveCachedRecognition(ctx context.Context, arg SaveCachedRecognitionParams) error
 	SaveRecipe(ctx context.Context, arg SaveRecipeParams) (RecipeBotRecipe, error)
+	SetInventoryExpiry(ctx context.Context, arg SetInventoryExpiryParams) (int64, error)
+	SetRecipeDocument(ctx context.Context, arg SetRecipeDocumentParams) error
+	ToggleShoppingItem(ctx context.Context, arg ToggleShoppingItemParams) (int64, error)
+	TrimCachedRecipes(ctx context.Context, arg TrimCachedRecipesParams) error
+	UpdateInventoryQuantity(ctx context.Context, arg UpdateInventoryQuantityParams) error
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (RecipeBotUser, error)
+	UpsertDialogSession(ctx context.Context, arg UpsertDialogSessionParams) error
+	UpsertExpiryReminder(ctx context.Context, arg UpsertExpiryReminderParams) error
+	// Срок, указанный пользователем, не заменяется оценкой, а известный срок - пустым
+	UpsertInventoryItems(ctx context.Context, arg UpsertInventoryItemsParams) (int64, error)
+	UpsertProducts(ctx context.Context, arg UpsertProductsParams) (int64, error)
+	UpsertRecognitionSession(ctx context.Context, arg UpsertRecognitionSessionParams) (RecipeBotRecognitionSession, error)
+	UpsertShoppingItems(ctx context.Context, arg UpsertShoppingItemsParams) (int64, error)
+	UpsertUserPreferences(ctx context.Context, arg UpsertUserPreferencesParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -11,6 +11,62 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addPantryStaples = `-- name: AddPantryStaples :execrows
+INSERT INTO recipe_bot.pantry_staples (user_id, name)
+SELECT $1::int, unnest($2::text[])
+ON CONFLICT (user_id, name) DO NOTHING
+`
+
+type AddPantryStaplesParams struct {
+	UserID int32    `db:"user_id" json:"userId"`
+	Names  []string `db:"names" json:"names"`
+}
+
+func (q *Queries) AddPantryStaples(ctx context.Context, arg AddPantryStaplesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addPantryStaples, arg.UserID, arg.Names)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const addShoppingRecipe = `-- name: AddShoppingRecipe :execrows
+INSERT INTO recipe_bot.shopping_recipes (user_id, recipe_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, recipe_id) DO NOTHING
+`
+
+type AddShoppingRecipeParams struct {
+	UserID   int32 `db:"user_id" json:"userId"`
+	RecipeID int32 `db:"recipe_id" json:"recipeId"`
+}
+
+func (q *Queries) AddShoppingRecipe(ctx context.Context, arg AddShoppingRecipeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addShoppingRecipe, arg.UserID, arg.RecipeID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const addSuggestedTitle = `-- name: AddSuggestedTitle :exec
+UPDATE recipe_bot.recognition_sessions
+SET
+    suggested_titles = suggested_titles || jsonb_build_array($1::text),
+    updated_at = NOW()
+WHERE user_id = $2
+`
+
+type AddSuggestedTitleParams struct {
+	Title  string `db:"title" json:"title"`
+	UserID int32  `db:"user_id" json:"userId"`
+}
+
+func (q *Queries) AddSuggestedTitle(ctx context.Context, arg AddSuggestedTitleParams) error {
+	_, err := q.db.Exec(ctx, addSuggestedTitle, arg.Title, arg.UserID)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO recipe_bot.users (
     telegram_id,
@@ -50,6 +106,104 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (RecipeB
 	return i, err
 }
 
+const deleteCheckedShoppingItems = `-- name: DeleteCheckedShoppingItems :execrows
+DELETE FROM recipe_bot.shopping_items
+WHERE user_id = $1 AND checked
+`
+
+func (q *Queries) DeleteCheckedShoppingItems(ctx context.Context, userID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCheckedShoppingItems, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteDialogSession = `-- name: DeleteDialogSession :exec
+DELETE FROM recipe_bot.dialog_sessions
+WHERE chat_id = $1
+`
+
+func (q *Queries) DeleteDialogSession(ctx context.Context, chatID int64) error {
+	_, err := q.db.Exec(ctx, deleteDialogSession, chatID)
+	return err
+}
+
+const deleteExpiredCachedRecipes = `-- name: DeleteExpiredCachedRecipes :execrows
+DELETE FROM recipe_bot.recipe_cache
+WHERE expires_at < NOW()
+`
+
+func (q *Queries) DeleteExpiredCachedRecipes(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredCachedRecipes)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredDialogSessions = `-- name: DeleteExpiredDialogSessions :execrows
+DELETE FROM recipe_bot.dialog_sessions
+WHERE expires_at < $1
+`
+
+func (q *Queries) DeleteExpiredDialogSessions(ctx context.Context, expiresAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredDialogSessions, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredRecognitions = `-- name: DeleteExpiredRecognitions :execrows
+DELETE FROM recipe_bot.recognition_cache
+WHERE expires_at < NOW()
+`
+
+func (q *Queries) DeleteExpiredRecognitions(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredRecognitions)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteInventoryItem = `-- name: DeleteInventoryItem :execrows
+DELETE FROM recipe_bot.inventory_items
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteInventoryItemParams struct {
+	ID     int64 `db:"id" json:"id"`
+	UserID int32 `db:"user_id" json:"userId"`
+}
+
+func (q *Queries) DeleteInventoryItem(ctx context.Context, arg DeleteInventoryItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteInventoryItem, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deletePantryStaple = `-- name: DeletePantryStaple :execrows
+DELETE FROM recipe_bot.pantry_staples
+WHERE user_id = $1 AND name = $2
+`
+
+type DeletePantryStapleParams struct {
+	UserID int32  `db:"user_id" json:"userId"`
+	Name   string `db:"name" json:"name"`
+}
+
+func (q *Queries) DeletePantryStaple(ctx context.Context, arg DeletePantryStapleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePantryStaple, arg.UserID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteRecipe = `-- name: DeleteRecipe :exec
 DELETE FROM recipe_bot.recipes
 WHERE id = $1 AND user_id = $2
@@ -65,8 +219,92 @@ func (q *Queries) DeleteRecipe(ctx context.Context, arg DeleteRecipeParams) erro
 	return err
 }
 
+const deleteShoppingItems = `-- name: DeleteShoppingItems :execrows
+DELETE FROM recipe_bot.shopping_items
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteShoppingItems(ctx context.Context, userID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteShoppingItems, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteShoppingRecipes = `-- name: DeleteShoppingRecipes :exec
+DELETE FROM recipe_bot.shopping_recipes
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteShoppingRecipes(ctx context.Context, userID int32) error {
+	_, err := q.db.Exec(ctx, deleteShoppingRecipes, userID)
+	return err
+}
+
+const findCachedRecognition = `-- name: FindCachedRecognition :one
+SELECT
+    items,
+    bit_count((image_hash # $1::bigint)::bit(64))::int AS distance
+FROM recipe_bot.recognition_cache
+WHERE expires_at > NOW()
+  AND bit_count((image_hash # $1::bigint)::bit(64)) <= $2::int
+ORDER BY distance, created_at DESC
+    LIMIT 1
+`
+
+type FindCachedRecognitionParams struct {
+	ImageHash   int64 `db:"image_hash" json:"imageHash"`
+	MaxDistance int32 `db:"max_distance" json:"maxDistance"`
+}
+
+type FindCachedRecognitionRow struct {
+	Items    []byte `db:"items" json:"items"`
+	Distance int32  `db:"distance" json:"distance"`
+}
+
+func (q *Queries) FindCachedRecognition(ctx context.Context, arg FindCachedRecognitionParams) (FindCachedRecognitionRow, error) {
+	row := q.db.QueryRow(ctx, findCachedRecognition, arg.ImageHash, arg.MaxDistance)
+	var i FindCachedRecognitionRow
+	err := row.Scan(&i.Items, &i.Distance)
+	return i, err
+}
+
+const findCachedRecognitionByHash = `-- name: FindCachedRecognitionByHash :one
+SELECT items FROM recipe_bot.recognition_cache
+WHERE image_hash = $1 AND expires_at > NOW()
+ORDER BY created_at DESC
+    LIMIT 1
+`
+
+func (q *Queries) FindCachedRecognitionByHash(ctx context.Context, imageHash int64) ([]byte, error) {
+	row := q.db.QueryRow(ctx, findCachedRecognitionByHash, imageHash)
+	var items []byte
+	err := row.Scan(&items)
+	return items, err
+}
+
+const getDialogSession = `-- name: GetDialogSession :one
+SELECT chat_id, flow, state, data, expires_at, updated_at FROM recipe_bot.dialog_sessions
+WHERE chat_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetDialogSession(ctx context.Context, chatID int64) (RecipeBotDialogSession, error) {
+	row := q.db.QueryRow(ctx, getDialogSession, chatID)
+	var i RecipeBotDialogSession
+	err := row.Scan(
+		&i.ChatID,
+		&i.Flow,
+		&i.State,
+		&i.Data,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getRecipe = `-- name: GetRecipe :one
-SELECT id, user_id, recipe_title, recipe_content, ingredients, created_at FROM recipe_bot.recipes
+SELECT id, user_id, recipe_title, recipe_content, ingredients, created_at, model, document, schema_version FROM recipe_bot.recipes
 WHERE id = $1 AND user_id = $2 LIMIT 1
 `
 
@@ -85,6 +323,27 @@ func (q *Queries) GetRecipe(ctx context.Context, arg GetRecipeParams) (RecipeBot
 		&i.RecipeContent,
 		&i.Ingredients,
 		&i.CreatedAt,
+		&i.Model,
+		&i.Document,
+		&i.SchemaVersion,
+	)
+	return i, err
+}
+
+const getRecognitionSession = `-- name: GetRecognitionSession :one
+SELECT user_id, items, suggested_titles, created_at, updated_at FROM recipe_bot.recognition_sessions
+WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetRecognitionSession(ctx context.Context, userID int32) (RecipeBotRecognitionSession, error) {
+	row := q.db.QueryRow(ctx, getRecognitionSession, userID)
+	var i RecipeBotRecognitionSession
+	err := row.Scan(
+		&i.UserID,
+		&i.Items,
+		&i.SuggestedTitles,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -109,8 +368,274 @@ func (q *Queries) GetUserByTelegramID(ctx context.Context, telegramID int64) (Re
 	return i, err
 }
 
+const getUserPreferences = `-- name: GetUserPreferences :one
+SELECT user_id, diets, allergens, max_calories, updated_at FROM recipe_bot.user_preferences
+WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserPreferences(ctx context.Context, userID int32) (RecipeBotUserPreference, error) {
+	row := q.db.QueryRow(ctx, getUserPreferences, userID)
+	var i RecipeBotUserPreference
+	err := row.Scan(
+		&i.UserID,
+		&i.Diets,
+		&i.Allergens,
+		&i.MaxCalories,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listCachedRecipes = `-- name: ListCachedRecipes :many
+SELECT id, cache_key, document, model, created_at, expires_at FROM recipe_bot.recipe_cache
+WHERE cache_key = $1 AND expires_at > NOW()
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListCachedRecipes(ctx context.Context, cacheKey string) ([]RecipeBotRecipeCache, error) {
+	rows, err := q.db.Query(ctx, listCachedRecipes, cacheKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RecipeBotRecipeCache{}
+	for rows.Next() {
+		var i RecipeBotRecipeCache
+		if err := rows.Scan(
+			&i.ID,
+			&i.CacheKey,
+			&i.Document,
+			&i.Model,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiringInventory = `-- name: ListExpiringInventory :many
+SELECT i.user_id, u.telegram_id, i.id, i.name, i.quantity, i.added_at, i.expires_at, i.expiry_estimated
+FROM recipe_bot.inventory_items i
+JOIN recipe_bot.users u ON u.id = i.user_id
+LEFT JOIN recipe_bot.expiry_reminders r ON r.user_id = i.user_id
+WHERE i.expires_at < $1
+  AND (r.sent_at IS NULL OR r.sent_at < $2)
+ORDER BY i.user_id, i.expires_at, i.name
+`
+
+type ListExpiringInventoryParams struct {
+	ExpiresBefore  pgtype.Timestamptz `db:"expires_before" json:"expiresBefore"`
+	RemindedBefore pgtype.Timestamptz `db:"reminded_before" json:"remindedBefore"`
+}
+
+type ListExpiringInventoryRow struct {
+	UserID          int32              `db:"user_id" json:"userId"`
+	TelegramID      int64              `db:"telegram_id" json:"telegramId"`
+	ID              int64              `db:"id" json:"id"`
+	Name            string             `db:"name" json:"name"`
+	Quantity        string             `db:"quantity" json:"quantity"`
+	AddedAt         pgtype.Timestamptz `db:"added_at" json:"addedAt"`
+	ExpiresAt       pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+	ExpiryEstimated bool               `db:"expiry_estimated" json:"expiryEstimated"`
+}
+
+// Продукты с истекающим сроком у пользователей, которым сегодня еще не напоминали
+func (q *Queries) ListExpiringInventory(ctx context.Context, arg ListExpiringInventoryParams) ([]ListExpiringInventoryRow, error) {
+	rows, err := q.db.Query(ctx, listExpiringInventory, arg.ExpiresBefore, arg.RemindedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListExpiringInventoryRow{}
+	for rows.Next() {
+		var i ListExpiringInventoryRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.TelegramID,
+			&i.ID,
+			&i.Name,
+			&i.Quantity,
+			&i.AddedAt,
+			&i.ExpiresAt,
+			&i.ExpiryEstimated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInventoryItems = `-- name: ListInventoryItems :many
+SELECT id, user_id, item_key, name, quantity, added_at, expires_at, expiry_estimated FROM recipe_bot.inventory_items
+WHERE user_id = $1
+ORDER BY added_at DESC, name
+`
+
+func (q *Queries) ListInventoryItems(ctx context.Context, userID int32) ([]RecipeBotInventoryItem, error) {
+	rows, err := q.db.Query(ctx, listInventoryItems, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RecipeBotInventoryItem{}
+	for rows.Next() {
+		var i RecipeBotInventoryItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ItemKey,
+			&i.Name,
+			&i.Quantity,
+			&i.AddedAt,
+			&i.ExpiresAt,
+			&i.ExpiryEstimated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPantryStaples = `-- name: ListPantryStaples :many
+SELECT user_id, name, created_at FROM recipe_bot.pantry_staples
+WHERE user_id = $1
+ORDER BY name
+`
+
+func (q *Queries) ListPantryStaples(ctx context.Context, userID int32) ([]RecipeBotPantryStaple, error) {
+	rows, err := q.db.Query(ctx, listPantryStaples, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RecipeBotPantryStaple{}
+	for rows.Next() {
+		var i RecipeBotPantryStaple
+		if err := rows.Scan(&i.UserID, &i.Name, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductsByBarcodes = `-- name: ListProductsByBarcodes :many
+SELECT barcode, name, full_name, brand, updated_at FROM recipe_bot.products
+WHERE barcode = ANY($1::text[])
+`
+
+func (q *Queries) ListProductsByBarcodes(ctx context.Context, barcodes []string) ([]RecipeBotProduct, error) {
+	rows, err := q.db.Query(ctx, listProductsByBarcodes, barcodes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RecipeBotProduct{}
+	for rows.Next() {
+		var i RecipeBotProduct
+		if err := rows.Scan(
+			&i.Barcode,
+			&i.Name,
+			&i.FullName,
+			&i.Brand,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecipesWithoutDocument = `-- name: ListRecipesWithoutDocument :many
+SELECT id, recipe_title, recipe_content FROM recipe_bot.recipes
+WHERE document IS NULL
+ORDER BY id
+    LIMIT $1
+`
+
+type ListRecipesWithoutDocumentRow struct {
+	ID            int32       `db:"id" json:"id"`
+	RecipeTitle   string      `db:"recipe_title" json:"recipeTitle"`
+	RecipeContent pgtype.Text `db:"recipe_content" json:"recipeContent"`
+}
+
+func (q *Queries) ListRecipesWithoutDocument(ctx context.Context, limit int32) ([]ListRecipesWithoutDocumentRow, error) {
+	rows, err := q.db.Query(ctx, listRecipesWithoutDocument, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRecipesWithoutDocumentRow{}
+	for rows.Next() {
+		var i ListRecipesWithoutDocumentRow
+		if err := rows.Scan(&i.ID, &i.RecipeTitle, &i.RecipeContent); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShoppingItems = `-- name: ListShoppingItems :many
+SELECT id, user_id, item_key, name, quantity, checked, added_at FROM recipe_bot.shopping_items
+WHERE user_id = $1
+ORDER BY checked, added_at, id
+`
+
+func (q *Queries) ListShoppingItems(ctx context.Context, userID int32) ([]RecipeBotShoppingItem, error) {
+	rows, err := q.db.Query(ctx, listShoppingItems, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RecipeBotShoppingItem{}
+	for rows.Next() {
+		var i RecipeBotShoppingItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ItemKey,
+			&i.Name,
+			&i.Quantity,
+			&i.Checked,
+			&i.AddedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUserRecipes = `-- name: ListUserRecipes :many
-SELECT id, user_id, recipe_title, recipe_content, ingredients, created_at FROM recipe_bot.recipes
+SELECT id, user_id, recipe_title, recipe_content, ingredients, created_at, model, document, schema_version FROM recipe_bot.recipes
 WHERE user_id = $1
 ORDER BY created_at DESC
     LIMIT $2
@@ -137,6 +662,9 @@ func (q *Queries) ListUserRecipes(ctx context.Context, arg ListUserRecipesParams
 			&i.RecipeContent,
 			&i.Ingredients,
 			&i.CreatedAt,
+			&i.Model,
+			&i.Document,
+			&i.SchemaVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -148,31 +676,99 @@ func (q *Queries) ListUserRecipes(ctx context.Context, arg ListUserRecipesParams
 	return items, nil
 }
 
+const lockShoppingList = `-- name: LockShoppingList :exec
+SELECT id FROM recipe_bot.users
+WHERE id = $1
+FOR NO KEY UPDATE
+`
+
+// Блокирует список покупок пользователя до конца транзакции, в том числе пустой список.
+// NO KEY UPDATE не мешает вставке строк, ссылающихся на пользователя.
+func (q *Queries) LockShoppingList(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, lockShoppingList, id)
+	return err
+}
+
+const saveCachedRecipe = `-- name: SaveCachedRecipe :exec
+INSERT INTO recipe_bot.recipe_cache (
+    cache_key,
+    document,
+    model,
+    expires_at
+) VALUES (
+             $1, $2, $3, $4
+         )
+`
+
+type SaveCachedRecipeParams struct {
+	CacheKey  string             `db:"cache_key" json:"cacheKey"`
+	Document  []byte             `db:"document" json:"document"`
+	Model     string             `db:"model" json:"model"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+}
+
+func (q *Queries) SaveCachedRecipe(ctx context.Context, arg SaveCachedRecipeParams) error {
+	_, err := q.db.Exec(ctx, saveCachedRecipe,
+		arg.CacheKey,
+		arg.Document,
+		arg.Model,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const saveCachedRecognition = `-- name: SaveCachedRecognition :exec
+INSERT INTO recipe_bot.recognition_cache (
+    image_hash,
+    items,
+    expires_at
+) VALUES (
+             $1, $2, $3
+         )
+`
+
+type SaveCachedRecognitionParams struct {
+	ImageHash int64              `db:"image_hash" json:"imageHash"`
+	Items     []byte             `db:"items" json:"items"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+}
+
+func (q *Queries) SaveCachedRecognition(ctx context.Context, arg SaveCachedRecognitionParams) error {
+	_, err := q.db.Exec(ctx, saveCachedRecognition, arg.ImageHash, arg.Items, arg.ExpiresAt)
+	return err
+}
+
 const saveRecipe = `-- name: SaveRecipe :one
 INSERT INTO recipe_bot.recipes (
     user_id,
     recipe_title,
-    recipe_content,
-    ingredients
+    ingredients,
+    model,
+    document,
+    schema_version
 ) VALUES (
-             $1, $2, $3, $4
+             $1, $2, $3, $4, $5, $6
          )
-    RETURNING id, user_id, recipe_title, recipe_content, ingredients, created_at
+    RETURNING id, user_id, recipe_title, recipe_content, ingredients, created_at, model, document, schema_version
 `
 
 type SaveRecipeParams struct {
-	UserID        int32  `db:"user_id" json:"userId"`
-	RecipeTitle   string `db:"recipe_title" json:"recipeTitle"`
-	RecipeContent string `db:"recipe_content" json:"recipeContent"`
-	Ingredients   []byte `db:"ingredients" json:"ingredients"`
+	UserID        int32       `db:"user_id" json:"userId"`
+	RecipeTitle   string      `db:"recipe_title" json:"recipeTitle"`
+	Ingredients   []byte      `db:"ingredients" json:"ingredients"`
+	Model         pgtype.Text `db:"model" json:"model"`
+	Document      []byte      `db:"document" json:"document"`
+	SchemaVersion int32       `db:"schema_version" json:"schemaVersion"`
 }
 
 func (q *Queries) SaveRecipe(ctx context.Context, arg SaveRecipeParams) (RecipeBotRecipe, error) {
 	row := q.db.QueryRow(ctx, saveRecipe,
 		arg.UserID,
 		arg.RecipeTitle,
-		arg.RecipeContent,
 		arg.Ingredients,
+		arg.Model,
+		arg.Document,
+		arg.SchemaVersion,
 	)
 	var i RecipeBotRecipe
 	err := row.Scan(
@@ -182,10 +778,109 @@ func (q *Queries) SaveRecipe(ctx context.Context, arg SaveRecipeParams) (RecipeB
 		&i.RecipeContent,
 		&i.Ingredients,
 		&i.CreatedAt,
+		&i.Model,
+		&i.Document,
+		&i.SchemaVersion,
 	)
 	return i, err
 }
 
+const setInventoryExpiry = `-- name: SetInventoryExpiry :execrows
+UPDATE recipe_bot.inventory_items
+SET expires_at = $3, expiry_estimated = FALSE
+WHERE id = $1 AND user_id = $2
+`
+
+type SetInventoryExpiryParams struct {
+	ID        int64              `db:"id" json:"id"`
+	UserID    int32              `db:"user_id" json:"userId"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+}
+
+func (q *Queries) SetInventoryExpiry(ctx context.Context, arg SetInventoryExpiryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setInventoryExpiry, arg.ID, arg.UserID, arg.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setRecipeDocument = `-- name: SetRecipeDocument :exec
+UPDATE recipe_bot.recipes
+SET
+    document = $2,
+    schema_version = $3
+WHERE id = $1
+`
+
+type SetRecipeDocumentParams struct {
+	ID            int32  `db:"id" json:"id"`
+	Document      []byte `db:"document" json:"document"`
+	SchemaVersion int32  `db:"schema_version" json:"schemaVersion"`
+}
+
+func (q *Queries) SetRecipeDocument(ctx context.Context, arg SetRecipeDocumentParams) error {
+	_, err := q.db.Exec(ctx, setRecipeDocument, arg.ID, arg.Document, arg.SchemaVersion)
+	return err
+}
+
+const toggleShoppingItem = `-- name: ToggleShoppingItem :execrows
+UPDATE recipe_bot.shopping_items
+SET checked = NOT checked
+WHERE id = $1 AND user_id = $2
+`
+
+type ToggleShoppingItemParams struct {
+	ID     int64 `db:"id" json:"id"`
+	UserID int32 `db:"user_id" json:"userId"`
+}
+
+func (q *Queries) ToggleShoppingItem(ctx context.Context, arg ToggleShoppingItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, toggleShoppingItem, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const trimCachedRecipes = `-- name: TrimCachedRecipes :exec
+DELETE FROM recipe_bot.recipe_cache
+WHERE cache_key = $1
+  AND id NOT IN (
+    SELECT id FROM recipe_bot.recipe_cache
+    WHERE cache_key = $1
+    ORDER BY created_at DESC, id DESC
+    LIMIT $2::int
+)
+`
+
+type TrimCachedRecipesParams struct {
+	CacheKey string `db:"cache_key" json:"cacheKey"`
+	Keep     int32  `db:"keep" json:"keep"`
+}
+
+func (q *Queries) TrimCachedRecipes(ctx context.Context, arg TrimCachedRecipesParams) error {
+	_, err := q.db.Exec(ctx, trimCachedRecipes, arg.CacheKey, arg.Keep)
+	return err
+}
+
+const updateInventoryQuantity = `-- name: UpdateInventoryQuantity :exec
+UPDATE recipe_bot.inventory_items
+SET quantity = $3
+WHERE id = $1 AND user_id = $2
+`
+
+type UpdateInventoryQuantityParams struct {
+	ID       int64  `db:"id" json:"id"`
+	UserID   int32  `db:"user_id" json:"userId"`
+	Quantity string `db:"quantity" json:"quantity"`
+}
+
+func (q *Queries) UpdateInventoryQuantity(ctx context.Context, arg UpdateInventoryQuantityParams) error {
+	_, err := q.db.Exec(ctx, updateInventoryQuantity, arg.ID, arg.UserID, arg.Quantity)
+	return err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE recipe_bot.users
 SET
@@ -223,3 +918,247 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (RecipeB
 	)
 	return i, err
 }
+
+const upsertDialogSession = `-- name: UpsertDialogSession :exec
+INSERT INTO recipe_bot.dialog_sessions (
+    chat_id,
+    flow,
+    state,
+    data,
+    expires_at
+) VALUES (
+             $1, $2, $3, $4, $5
+         )
+ON CONFLICT (chat_id) DO UPDATE
+SET
+    flow = EXCLUDED.flow,
+    state = EXCLUDED.state,
+    data = EXCLUDED.data,
+    expires_at = EXCLUDED.expires_at,
+    updated_at = NOW()
+`
+
+type UpsertDialogSessionParams struct {
+	ChatID    int64              `db:"chat_id" json:"chatId"`
+	Flow      string             `db:"flow" json:"flow"`
+	State     string             `db:"state" json:"state"`
+	Data      []byte             `db:"data" json:"data"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expiresAt"`
+}
+
+func (q *Queries) UpsertDialogSession(ctx context.Context, arg UpsertDialogSessionParams) error {
+	_, err := q.db.Exec(ctx, upsertDialogSession,
+		arg.ChatID,
+		arg.Flow,
+		arg.State,
+		arg.Data,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const upsertExpiryReminder = `-- name: UpsertExpiryReminder :exec
+INSERT INTO recipe_bot.expiry_reminders (user_id, sent_at)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET sent_at = EXCLUDED.sent_at
+`
+
+type UpsertExpiryReminderParams struct {
+	UserID int32              `db:"user_id" json:"userId"`
+	SentAt pgtype.Timestamptz `db:"sent_at" json:"sentAt"`
+}
+
+func (q *Queries) UpsertExpiryReminder(ctx context.Context, arg UpsertExpiryReminderParams) error {
+	_, err := q.db.Exec(ctx, upsertExpiryReminder, arg.UserID, arg.SentAt)
+	return err
+}
+
+const upsertInventoryItems = `-- name: UpsertInventoryItems :execrows
+INSERT INTO recipe_bot.inventory_items (user_id, item_key, name, quantity, expires_at, expiry_estimated)
+SELECT $1::int, item.item_key, item.name, item.quantity, item.expires_at, item.expiry_estimated
+FROM unnest(
+    $2::text[],
+    $3::text[],
+    $4::text[],
+    $5::timestamptz[],
+    $6::boolean[]
+) AS item(item_key, name, quantity, expires_at, expiry_estimated)
+ON CONFLICT (user_id, item_key) DO UPDATE SET
+    name = EXCLUDED.name,
+    quantity = CASE WHEN EXCLUDED.quantity <> '' THEN EXCLUDED.quantity ELSE inventory_items.quantity END,
+    expires_at = CASE
+        WHEN inventory_items.expires_at IS NOT NULL
+            AND (NOT inventory_items.expiry_estimated OR EXCLUDED.expires_at IS NULL)
+            THEN inventory_items.expires_at
+        ELSE EXCLUDED.expires_at
+    END,
+    expiry_estimated = CASE
+        WHEN inventory_items.expires_at IS NOT NULL
+            AND (NOT inventory_items.expiry_estimated OR EXCLUDED.expires_at IS NULL)
+            THEN inventory_items.expiry_estimated
+        ELSE EXCLUDED.expiry_estimated
+    END,
+    added_at = NOW()
+`
+
+type UpsertInventoryItemsParams struct {
+	UserID      int32                `db:"user_id" json:"userId"`
+	ItemKeys    []string             `db:"item_keys" json:"itemKeys"`
+	Names       []string             `db:"names" json:"names"`
+	Quantities  []string             `db:"quantities" json:"quantities"`
+	ExpiryDates []pgtype.Timestamptz `db:"expiry_dates" json:"expiryDates"`
+	Estimated   []bool               `db:"estimated" json:"estimated"`
+}
+
+// Срок, указанный пользователем, не заменяется оценкой, а известный срок - пустым
+func (q *Queries) UpsertInventoryItems(ctx context.Context, arg UpsertInventoryItemsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, upsertInventoryItems,
+		arg.UserID,
+		arg.ItemKeys,
+		arg.Names,
+		arg.Quantities,
+		arg.ExpiryDates,
+		arg.Estimated,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const upsertProducts = `-- name: UpsertProducts :execrows
+INSERT INTO recipe_bot.products (barcode, name, full_name, brand)
+SELECT * FROM unnest(
+    $1::text[],
+    $2::text[],
+    $3::text[],
+    $4::text[]
+)
+ON CONFLICT (barcode) DO UPDATE SET
+    name = EXCLUDED.name,
+    full_name = EXCLUDED.full_name,
+    brand = EXCLUDED.brand,
+    updated_at = NOW()
+`
+
+type UpsertProductsParams struct {
+	Barcodes  []string `db:"barcodes" json:"barcodes"`
+	Names     []string `db:"names" json:"names"`
+	FullNames []string `db:"full_names" json:"fullNames"`
+	Brands    []string `db:"brands" json:"brands"`
+}
+
+func (q *Queries) UpsertProducts(ctx context.Context, arg UpsertProductsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, upsertProducts,
+		arg.Barcodes,
+		arg.Names,
+		arg.FullNames,
+		arg.Brands,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const upsertRecognitionSession = `-- name: UpsertRecognitionSession :one
+INSERT INTO recipe_bot.recognition_sessions (
+    user_id,
+    items,
+    suggested_titles
+) VALUES (
+             $1, $2, '[]'::jsonb
+         )
+ON CONFLICT (user_id) DO UPDATE
+SET
+    items = EXCLUDED.items,
+    suggested_titles = '[]'::jsonb,
+    updated_at = NOW()
+    RETURNING user_id, items, suggested_titles, created_at, updated_at
+`
+
+type UpsertRecognitionSessionParams struct {
+	UserID int32  `db:"user_id" json:"userId"`
+	Items  []byte `db:"items" json:"items"`
+}
+
+func (q *Queries) UpsertRecognitionSession(ctx context.Context, arg UpsertRecognitionSessionParams) (RecipeBotRecognitionSession, error) {
+	row := q.db.QueryRow(ctx, upsertRecognitionSession, arg.UserID, arg.Items)
+	var i RecipeBotRecognitionSession
+	err := row.Scan(
+		&i.UserID,
+		&i.Items,
+		&i.SuggestedTitles,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertShoppingItems = `-- name: UpsertShoppingItems :execrows
+INSERT INTO recipe_bot.shopping_items (user_id, item_key, name, quantity)
+SELECT $1::int, item.item_key, item.name, item.quantity
+FROM unnest(
+    $2::text[],
+    $3::text[],
+    $4::text[]
+) AS item(item_key, name, quantity)
+ON CONFLICT (user_id, item_key) DO UPDATE SET
+    name = EXCLUDED.name,
+    quantity = EXCLUDED.quantity,
+    checked = FALSE
+`
+
+type UpsertShoppingItemsParams struct {
+	UserID     int32    `db:"user_id" json:"userId"`
+	ItemKeys   []string `db:"item_keys" json:"itemKeys"`
+	Names      []string `db:"names" json:"names"`
+	Quantities []string `db:"quantities" json:"quantities"`
+}
+
+func (q *Queries) UpsertShoppingItems(ctx context.Context, arg UpsertShoppingItemsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, upsertShoppingItems,
+		arg.UserID,
+		arg.ItemKeys,
+		arg.Names,
+		arg.Quantities,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const upsertUserPreferences = `-- name: UpsertUserPreferences :exec
+INSERT INTO recipe_bot.user_preferences (
+    user_id,
+    diets,
+    allergens,
+    max_calories
+) VALUES (
+             $1, $2, $3, $4
+         )
+ON CONFLICT (user_id) DO UPDATE
+SET
+    diets = EXCLUDED.diets,
+    allergens = EXCLUDED.allergens,
+    max_calories = EXCLUDED.max_calories,
+    updated_at = NOW()
+`
+
+type UpsertUserPreferencesParams struct {
+	UserID      int32    `db:"user_id" json:"userId"`
+	Diets       []string `db:"diets" json:"diets"`
+	Allergens   []string `db:"allergens" json:"allergens"`
+	MaxCalories int32    `db:"max_calories" json:"maxCalories"`
+}
+
+func (q *Queries) UpsertUserPreferences(ctx context.Context, arg UpsertUserPreferencesParams) error {
+	_, err := q.db.Exec(ctx, upsertUserPreferences,
+		arg.UserID,
+		arg.Diets,
+		arg.Allergens,
+		arg.MaxCalories,
+	)
+	return err
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/inventory"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/reminders"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DBManager служит хранилищем напоминаний о сроках годности
+var _ reminders.Store = (*DBManager)(nil)
+
+// InventoryItems возвращает продукты пользователя, недавно добавленные первыми
+func (m *DBManager) InventoryItems(ctx context.Context, userID int32) ([]inventory.Item, error) {
+	rows, err := m.Queries.ListInventoryItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]inventory.Item, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, inventory.Item{
+			ID:              row.ID,
+			Name:            row.Name,
+			Quantity:        row.Quantity,
+			AddedAt:         row.AddedAt.Time,
+			ExpiresAt:       row.ExpiresAt.Time,
+			ExpiryEstimated: row.ExpiryEstimated,
+		})
+	}
+	return items, nil
+}
+
+// AddInventoryItems добавляет продукты пользователю одним запросом. Уже известный продукт
+// (с учетом формы числа и синонимов) получает новую дату и количество, если оно оценено. Оценка срока
+// годности заменяет только прежнюю оценку: срок, указанный через /expiry, сохраняется.
+// PostgreSQL не обновляет строку дважды за запрос, поэтому из повторов берется последний.
+func (m *DBManager) AddInventoryItems(ctx context.Context, userID int32, items []inventory.Item) (int64, error) {
+	index := make(map[string]int, len(items))
+	params := database.UpsertInventoryItemsParams{UserID: userID}
+	for _, item := range items {
+		key := ingredients.Key(item.Name)
+		if key == "" {
+			continue
+		}
+		expiresAt := pgtype.Timestamptz{Time: item.ExpiresAt, Valid: !item.ExpiresAt.IsZero()}
+		if i, ok := index[key]; ok {
+			params.Names[i], params.Quantities[i] = item.Name, item.Quantity
+			params.ExpiryDates[i], params.Estimated[i] = expiresAt, item.ExpiryEstimated
+			continue
+		}
+		index[key] = len(params.ItemKeys)
+		params.ItemKeys = append(params.ItemKeys, key)
+		params.Names = append(params.Names, item.Name)
+		params.Quantities = append(params.Quantities, item.Quantity)
+		params.ExpiryDates = append(params.ExpiryDates, expiresAt)
+		params.Estimated = append(params.Estimated, item.ExpiryEstimated)
+	}
+	if len(params.ItemKeys) == 0 {
+		return 0, nil
+	}
+
+	return m.Queries.UpsertInventoryItems(ctx, params)
+}
+
+// RemoveInventoryItem удаляет израсходованный продукт; false - продукта уже нет
+func (m *DBManager) RemoveInventoryItem(ctx context.Context, userID int32, id int64) (bool, error) {
+	removed, err := m.Queries.DeleteInventoryItem(ctx, database.DeleteInventoryItemParams{
+		ID:     id,
+		UserID: userID,
+	})
+	return removed > 0, err
+}
+
+// SetInventoryExpiry задает срок годности продукта вручную; false - продукта уже нет
+func (m *DBManager) SetInventoryExpiry(ctx context.Context, userID int32, id int64, expiresAt time.Time) (bool, error) {
+	updated, err := m.Queries.SetInventoryExpiry(ctx, database.SetInventoryExpiryParams{
+		ID:        id,
+		UserID:    userID,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	return updated > 0, err
+}
+
+// ApplyInventoryUsage списывает продукты, использованные в рецепте
+func (m *DBManager) ApplyInventoryUsage(ctx context.Context, userID int32, usages []inventory.Usage) error {
+	for _, usage := range usages {
+		if usage.Used() {
+			if _, err := m.RemoveInventoryItem(ctx, userID, usage.Item.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := m.Queries.UpdateInventoryQuantity(ctx, database.UpdateInventoryQuantityParams{
+			ID:       usage.Item.ID,
+			UserID:   userID,
+			Quantity: usage.Remaining,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpiringItems возвращает продукты со сроком до expiresBefore, сгруппированные по пользователям,
+// которым не напоминали начиная с remindedBefore
+func (m *DBManager) ExpiringItems(ctx context.Context, expiresBefore, remindedBefore time.Time) ([]reminders.Reminder, error) {
+	rows, err := m.Queries.ListExpiringInventory(ctx, database.ListExpiringInventoryParams{
+		ExpiresBefore:  pgtype.Timestamptz{Time: expiresBefore, Valid: true},
+		RemindedBefore: pgtype.Timestamptz{Time: remindedBefore, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Строки отсортированы по пользователю, поэтому продукты одного пользователя идут подряд
+	var due []reminders.Reminder
+	for _, row := range rows {
+		if len(due) == 0 || due[len(due)-1].UserID != row.UserID {
+			due = append(due, reminders.Reminder{UserID: row.UserID, ChatID: row.TelegramID})
+		}
+		reminder := &due[len(due)-1]
+		reminder.Items = append(reminder.Items, inventory.Item{
+			ID:              row.ID,
+			Name:            row.Name,
+			Quantity:        row.Quantity,
+			AddedAt:         row.AddedAt.Time,
+			ExpiresAt:       row.ExpiresAt.Time,
+			ExpiryEstimated: row.ExpiryEstimated,
+		})
+	}
+	return due, nil
+}
+
+// MarkReminded запоминает, что пользователю отправлено напоминание
+func (m *DBManager) MarkReminded(ctx context.Context, userID int32, at time.Time) error {
+	return m.Queries.UpsertExpiryReminder(ctx, database.UpsertExpiryReminderParams{
+		UserID: userID,
+		SentAt: pgtype.Timestamptz{Time: at, Valid: true},
+	})
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -89,3 +90,38 @@ func (m *DBManager) GetUserOrCreate(ctx context.Context, telegramID int64, usern
 
 	return &user, err
 }
+
+// RecipeDocumentConverter строит JSON-документ рецепта из старого Markdown
+type RecipeDocumentConverter func(title, content string) ([]byte, error)
+
+// BackfillRecipeDocuments заполняет документы рецептов, сохраненных до появления колонки document
+func (m *DBManager) BackfillRecipeDocuments(ctx context.Context, convert RecipeDocumentConverter, schemaVersion int32) (int, error) {
+	const batchSize = 100
+	converted := 0
+
+	for {
+		rows, err := m.Queries.ListRecipesWithoutDocument(ctx, batchSize)
+		if err != nil {
+			return converted, err
+		}
+		if len(rows) == 0 {
+			return converted, nil
+		}
+
+		for _, row := range rows {
+			document, err := convert(row.RecipeTitle, row.RecipeContent.String)
+			if err != nil {
+				return converted, fmt.Errorf("convert recipe %d: %w", row.ID, err)
+			}
+
+			if err := m.Queries.SetRecipeDocument(ctx, database.SetRecipeDocumentParams{
+				ID:            row.ID,
+				Document:      document,
+				SchemaVersion: schemaVersion,
+			}); err != nil {
+				return converted, err
+			}
+			converted++
+		}
+	}
+}
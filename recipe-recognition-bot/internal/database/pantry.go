@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+)
+
+// PantryStaples возвращает запасы пользователя по алфавиту
+func (m *DBManager) PantryStaples(ctx context.Context, userID int32) ([]string, error) {
+	rows, err := m.Queries.ListPantryStaples(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Name)
+	}
+	return names, nil
+}
+
+// AddPantryStaples добавляет запасы пользователя и возвращает, сколько из них новых
+func (m *DBManager) AddPantryStaples(ctx context.Context, userID int32, names []string) (int64, error) {
+	return m.Queries.AddPantryStaples(ctx, database.AddPantryStaplesParams{
+		UserID: userID,
+		Names:  names,
+	})
+}
+
+// RemovePantryStaple удаляет продукт из запасов пользователя; false - такого продукта не было
+func (m *DBManager) RemovePantryStaple(ctx context.Context, userID int32, name string) (bool, error) {
+	removed, err := m.Queries.DeletePantryStaple(ctx, database.DeletePantryStapleParams{
+		UserID: userID,
+		Name:   name,
+	})
+	return removed > 0, err
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetPreferences возвращает ограничения питания пользователя; если они не заданы - пустые
+func (m *DBManager) GetPreferences(ctx context.Context, userID int32) (recipes.Preferences, error) {
+	row, err := m.Queries.GetUserPreferences(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return recipes.Preferences{}, nil
+	}
+	if err != nil {
+		return recipes.Preferences{}, err
+	}
+
+	return recipes.Preferences{
+		Diets:       row.Diets,
+		Allergens:   row.Allergens,
+		MaxCalories: int(row.MaxCalories),
+	}, nil
+}
+
+// SavePreferences сохраняет ограничения питания пользователя
+func (m *DBManager) SavePreferences(ctx context.Context, userID int32, prefs recipes.Preferences) error {
+	// NULL нарушил бы NOT NULL у столбцов-массивов
+	diets, allergens := prefs.Diets, prefs.Allergens
+	if diets == nil {
+		diets = []string{}
+	}
+	if allergens == nil {
+		allergens = []string{}
+	}
+
+	return m.Queries.UpsertUserPreferences(ctx, database.UpsertUserPreferencesParams{
+		UserID:      userID,
+		Diets:       diets,
+		Allergens:   allergens,
+		MaxCalories: int32(prefs.MaxCalories),
+	})
+}
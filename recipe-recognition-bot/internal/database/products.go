@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/catalog"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/vision"
+)
+
+// DBManager служит справочником товаров по штрихкодам
+var _ vision.ProductCatalog = (*DBManager)(nil)
+
+// ProductNames возвращает названия известных товаров по штрихкодам
+func (m *DBManager) ProductNames(ctx context.Context, barcodes []string) (map[string]string, error) {
+	rows, err := m.Queries.ListProductsByBarcodes(ctx, barcodes)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(rows))
+	for _, row := range rows {
+		names[row.Barcode] = row.Name
+	}
+	return names, nil
+}
+
+// ImportProducts добавляет товары в справочник одним запросом, обновляя уже известные.
+// PostgreSQL не обновляет строку дважды за запрос, поэтому из товаров с одинаковым штрихкодом берется последний.
+func (m *DBManager) ImportProducts(ctx context.Context, products []catalog.Product) (int64, error) {
+	index := make(map[string]int, len(products))
+	var params database.UpsertProductsParams
+	for _, p := range products {
+		if i, ok := index[p.Barcode]; ok {
+			params.Names[i], params.FullNames[i], params.Brands[i] = p.Name, p.FullName, p.Brand
+			continue
+		}
+		index[p.Barcode] = len(params.Barcodes)
+		params.Barcodes = append(params.Barcodes, p.Barcode)
+		params.Names = append(params.Names, p.Name)
+		params.FullNames = append(params.FullNames, p.FullName)
+		params.Brands = append(params.Brands, p.Brand)
+	}
+
+	return m.Queries.UpsertProducts(ctx, params)
+}
@@ -17,10 +17,12 @@ WHERE telegram_id = $1 LIMIT 1;
 INSERT INTO recipe_bot.recipes (
     user_id,
     recipe_title,
-    recipe_content,
-    ingredients
+    ingredients,
+    model,
+    document,
+    schema_version
 ) VALUES (
-             $1, $2, $3, $4
+             $1, $2, $3, $4, $5, $6
          )
     RETURNING *;
 
@@ -38,6 +40,19 @@ WHERE id = $1 AND user_id = $2 LIMIT 1;
 DELETE FROM recipe_bot.recipes
 WHERE id = $1 AND user_id = $2;
 
+-- name: ListRecipesWithoutDocument :many
+SELECT id, recipe_title, recipe_content FROM recipe_bot.recipes
+WHERE document IS NULL
+ORDER BY id
+    LIMIT $1;
+
+-- name: SetRecipeDocument :exec
+UPDATE recipe_bot.recipes
+SET
+    document = $2,
+    schema_version = $3
+WHERE id = $1;
+
 -- name: UpdateUser :one
 UPDATE recipe_bot.users
 SET
@@ -46,4 +61,279 @@ SET
     last_name = $4,
     updated_at = NOW()
 WHERE telegram_id = $1
-    RETURNING *;
\ No newline at end of file
+    RETURNING *;
+
+-- name: UpsertRecognitionSession :one
+INSERT INTO recipe_bot.recognition_sessions (
+    user_id,
+    items,
+    suggested_titles
+) VALUES (
+             $1, $2, '[]'::jsonb
+         )
+ON CONFLICT (user_id) DO UPDATE
+SET
+    items = EXCLUDED.items,
+    suggested_titles = '[]'::jsonb,
+    updated_at = NOW()
+    RETURNING *;
+
+-- name: GetRecognitionSession :one
+SELECT * FROM recipe_bot.recognition_sessions
+WHERE user_id = $1 LIMIT 1;
+
+-- name: AddSuggestedTitle :exec
+UPDATE recipe_bot.recognition_sessions
+SET
+    suggested_titles = suggested_titles || jsonb_build_array(sqlc.arg(title)::text),
+    updated_at = NOW()
+WHERE user_id = sqlc.arg(user_id);
+
+-- name: UpsertDialogSession :exec
+INSERT INTO recipe_bot.dialog_sessions (
+    chat_id,
+    flow,
+    state,
+    data,
+    expires_at
+) VALUES (
+             $1, $2, $3, $4, $5
+         )
+ON CONFLICT (chat_id) DO UPDATE
+SET
+    flow = EXCLUDED.flow,
+    state = EXCLUDED.state,
+    data = EXCLUDED.data,
+    expires_at = EXCLUDED.expires_at,
+    updated_at = NOW();
+
+-- name: GetDialogSession :one
+SELECT * FROM recipe_bot.dialog_sessions
+WHERE chat_id = $1 LIMIT 1;
+
+-- name: DeleteDialogSession :exec
+DELETE FROM recipe_bot.dialog_sessions
+WHERE chat_id = $1;
+
+-- name: DeleteExpiredDialogSessions :execrows
+DELETE FROM recipe_bot.dialog_sessions
+WHERE expires_at < $1;
+
+-- name: FindCachedRecognition :one
+SELECT
+    items,
+    bit_count((image_hash # sqlc.arg(image_hash)::bigint)::bit(64))::int AS distance
+FROM recipe_bot.recognition_cache
+WHERE expires_at > NOW()
+  AND bit_count((image_hash # sqlc.arg(image_hash)::bigint)::bit(64)) <= sqlc.arg(max_distance)::int
+ORDER BY distance, created_at DESC
+    LIMIT 1;
+
+-- name: FindCachedRecognitionByHash :one
+SELECT items FROM recipe_bot.recognition_cache
+WHERE image_hash = $1 AND expires_at > NOW()
+ORDER BY created_at DESC
+    LIMIT 1;
+
+-- name: SaveCachedRecognition :exec
+INSERT INTO recipe_bot.recognition_cache (
+    image_hash,
+    items,
+    expires_at
+) VALUES (
+             $1, $2, $3
+         );
+
+-- name: DeleteExpiredRecognitions :execrows
+DELETE FROM recipe_bot.recognition_cache
+WHERE expires_at < NOW();
+
+-- name: ListCachedRecipes :many
+SELECT * FROM recipe_bot.recipe_cache
+WHERE cache_key = $1 AND expires_at > NOW()
+ORDER BY created_at DESC;
+
+-- name: SaveCachedRecipe :exec
+INSERT INTO recipe_bot.recipe_cache (
+    cache_key,
+    document,
+    model,
+    expires_at
+) VALUES (
+             $1, $2, $3, $4
+         );
+
+-- name: TrimCachedRecipes :exec
+DELETE FROM recipe_bot.recipe_cache
+WHERE cache_key = sqlc.arg(cache_key)
+  AND id NOT IN (
+    SELECT id FROM recipe_bot.recipe_cache
+    WHERE cache_key = sqlc.arg(cache_key)
+    ORDER BY created_at DESC, id DESC
+    LIMIT sqlc.arg(keep)::int
+);
+
+-- name: DeleteExpiredCachedRecipes :execrows
+DELETE FROM recipe_bot.recipe_cache
+WHERE expires_at < NOW();
+
+-- name: ListProductsByBarcodes :many
+SELECT * FROM recipe_bot.products
+WHERE barcode = ANY(sqlc.arg(barcodes)::text[]);
+
+-- name: UpsertProducts :execrows
+INSERT INTO recipe_bot.products (barcode, name, full_name, brand)
+SELECT * FROM unnest(
+    sqlc.arg(barcodes)::text[],
+    sqlc.arg(names)::text[],
+    sqlc.arg(full_names)::text[],
+    sqlc.arg(brands)::text[]
+)
+ON CONFLICT (barcode) DO UPDATE SET
+    name = EXCLUDED.name,
+    full_name = EXCLUDED.full_name,
+    brand = EXCLUDED.brand,
+    updated_at = NOW();
+
+-- name: GetUserPreferences :one
+SELECT * FROM recipe_bot.user_preferences
+WHERE user_id = $1 LIMIT 1;
+
+-- name: UpsertUserPreferences :exec
+INSERT INTO recipe_bot.user_preferences (
+    user_id,
+    diets,
+    allergens,
+    max_calories
+) VALUES (
+             $1, $2, $3, $4
+         )
+ON CONFLICT (user_id) DO UPDATE
+SET
+    diets = EXCLUDED.diets,
+    allergens = EXCLUDED.allergens,
+    max_calories = EXCLUDED.max_calories,
+    updated_at = NOW();
+
+-- name: ListPantryStaples :many
+SELECT * FROM recipe_bot.pantry_staples
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: AddPantryStaples :execrows
+INSERT INTO recipe_bot.pantry_staples (user_id, name)
+SELECT sqlc.arg(user_id)::int, unnest(sqlc.arg(names)::text[])
+ON CONFLICT (user_id, name) DO NOTHING;
+
+-- name: DeletePantryStaple :execrows
+DELETE FROM recipe_bot.pantry_staples
+WHERE user_id = $1 AND name = $2;
+
+-- name: ListInventoryItems :many
+SELECT * FROM recipe_bot.inventory_items
+WHERE user_id = $1
+ORDER BY added_at DESC, name;
+
+-- name: UpsertInventoryItems :execrows
+-- Срок, указанный пользователем, не заменяется оценкой, а известный срок - пустым
+INSERT INTO recipe_bot.inventory_items (user_id, item_key, name, quantity, expires_at, expiry_estimated)
+SELECT sqlc.arg(user_id)::int, item.item_key, item.name, item.quantity, item.expires_at, item.expiry_estimated
+FROM unnest(
+    sqlc.arg(item_keys)::text[],
+    sqlc.arg(names)::text[],
+    sqlc.arg(quantities)::text[],
+    sqlc.arg(expiry_dates)::timestamptz[],
+    sqlc.arg(estimated)::boolean[]
+) AS item(item_key, name, quantity, expires_at, expiry_estimated)
+ON CONFLICT (user_id, item_key) DO UPDATE SET
+    name = EXCLUDED.name,
+    quantity = CASE WHEN EXCLUDED.quantity <> '' THEN EXCLUDED.quantity ELSE inventory_items.quantity END,
+    expires_at = CASE
+        WHEN inventory_items.expires_at IS NOT NULL
+            AND (NOT inventory_items.expiry_estimated OR EXCLUDED.expires_at IS NULL)
+            THEN inventory_items.expires_at
+        ELSE EXCLUDED.expires_at
+    END,
+    expiry_estimated = CASE
+        WHEN inventory_items.expires_at IS NOT NULL
+            AND (NOT inventory_items.expiry_estimated OR EXCLUDED.expires_at IS NULL)
+            THEN inventory_items.expiry_estimated
+        ELSE EXCLUDED.expiry_estimated
+    END,
+    added_at = NOW();
+
+-- name: UpdateInventoryQuantity :exec
+UPDATE recipe_bot.inventory_items
+SET quantity = $3
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteInventoryItem :execrows
+DELETE FROM recipe_bot.inventory_items
+WHERE id = $1 AND user_id = $2;
+
+-- name: SetInventoryExpiry :execrows
+UPDATE recipe_bot.inventory_items
+SET expires_at = $3, expiry_estimated = FALSE
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListExpiringInventory :many
+-- Продукты с истекающим сроком у пользователей, которым сегодня еще не напоминали
+SELECT i.user_id, u.telegram_id, i.id, i.name, i.quantity, i.added_at, i.expires_at, i.expiry_estimated
+FROM recipe_bot.inventory_items i
+JOIN recipe_bot.users u ON u.id = i.user_id
+LEFT JOIN recipe_bot.expiry_reminders r ON r.user_id = i.user_id
+WHERE i.expires_at < sqlc.arg(expires_before)
+  AND (r.sent_at IS NULL OR r.sent_at < sqlc.arg(reminded_before))
+ORDER BY i.user_id, i.expires_at, i.name;
+
+-- name: UpsertExpiryReminder :exec
+INSERT INTO recipe_bot.expiry_reminders (user_id, sent_at)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET sent_at = EXCLUDED.sent_at;
+
+-- name: ListShoppingItems :many
+SELECT * FROM recipe_bot.shopping_items
+WHERE user_id = $1
+ORDER BY checked, added_at, id;
+
+-- name: UpsertShoppingItems :execrows
+INSERT INTO recipe_bot.shopping_items (user_id, item_key, name, quantity)
+SELECT sqlc.arg(user_id)::int, item.item_key, item.name, item.quantity
+FROM unnest(
+    sqlc.arg(item_keys)::text[],
+    sqlc.arg(names)::text[],
+    sqlc.arg(quantities)::text[]
+) AS item(item_key, name, quantity)
+ON CONFLICT (user_id, item_key) DO UPDATE SET
+    name = EXCLUDED.name,
+    quantity = EXCLUDED.quantity,
+    checked = FALSE;
+
+-- name: ToggleShoppingItem :execrows
+UPDATE recipe_bot.shopping_items
+SET checked = NOT checked
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteCheckedShoppingItems :execrows
+DELETE FROM recipe_bot.shopping_items
+WHERE user_id = $1 AND checked;
+
+-- name: DeleteShoppingItems :execrows
+DELETE FROM recipe_bot.shopping_items
+WHERE user_id = $1;
+
+-- name: LockShoppingList :exec
+-- Блокирует список покупок пользователя до конца транзакции, в том числе пустой список.
+-- NO KEY UPDATE не мешает вставке строк, ссылающихся на пользователя.
+SELECT id FROM recipe_bot.users
+WHERE id = $1
+FOR NO KEY UPDATE;
+
+-- name: AddShoppingRecipe :execrows
+INSERT INTO recipe_bot.shopping_recipes (user_id, recipe_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, recipe_id) DO NOTHING;
+
+-- name: DeleteShoppingRecipes :exec
+DELETE FROM recipe_bot.shopping_recipes
+WHERE user_id = $1;
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DBManager служит кэшем сгенерированных рецептов
+var _ recipes.RecipeCache = (*DBManager)(nil)
+
+// CachedRecipes возвращает неистекшие варианты рецептов для ключа, новые первыми
+func (m *DBManager) CachedRecipes(ctx context.Context, key string) ([]recipes.CachedRecipe, error) {
+	rows, err := m.Queries.ListCachedRecipes(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := make([]recipes.CachedRecipe, 0, len(rows))
+	for _, row := range rows {
+		cached = append(cached, recipes.CachedRecipe{Document: row.Document, Model: row.Model})
+	}
+	return cached, nil
+}
+
+// CacheRecipe сохраняет вариант рецепта и удаляет для ключа варианты сверх maxVariants
+func (m *DBManager) CacheRecipe(ctx context.Context, key string, recipe recipes.CachedRecipe, ttl time.Duration, maxVariants int) error {
+	err := m.Queries.SaveCachedRecipe(ctx, database.SaveCachedRecipeParams{
+		CacheKey:  key,
+		Document:  recipe.Document,
+		Model:     recipe.Model,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(ttl), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.Queries.TrimCachedRecipes(ctx, database.TrimCachedRecipesParams{
+		CacheKey: key,
+		Keep:     int32(maxVariants),
+	})
+}
+
+// DeleteExpiredCachedRecipes удаляет истекшие рецепты из кэша
+func (m *DBManager) DeleteExpiredCachedRecipes(ctx context.Context) (int64, error) {
+	return m.Queries.DeleteExpiredCachedRecipes(ctx)
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/vision"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DBManager служит кэшем распознавания фото
+var _ vision.RecognitionCache = (*DBManager)(nil)
+
+// FindRecognition ищет неистекшую запись с ближайшим хешем изображения.
+// Хеш хранится в BIGINT: биты uint64 переносятся без изменений.
+// Сначала проверяется точное совпадение по индексу, и только затем
+// перебираются записи в пределах maxDistance.
+func (m *DBManager) FindRecognition(ctx context.Context, hash uint64, maxDistance int) ([]vision.Item, bool, error) {
+	data, err := m.Queries.FindCachedRecognitionByHash(ctx, int64(hash))
+	if errors.Is(err, pgx.ErrNoRows) && maxDistance > 0 {
+		var row database.FindCachedRecognitionRow
+		row, err = m.Queries.FindCachedRecognition(ctx, database.FindCachedRecognitionParams{
+			ImageHash:   int64(hash),
+			MaxDistance: int32(maxDistance),
+		})
+		data = row.Items
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var items []vision.Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, false, err
+	}
+	return items, true, nil
+}
+
+// SaveRecognition сохраняет распознанные продукты для изображения
+func (m *DBManager) SaveRecognition(ctx context.Context, hash uint64, items []vision.Item, ttl time.Duration) error {
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	return m.Queries.SaveCachedRecognition(ctx, database.SaveCachedRecognitionParams{
+		ImageHash: int64(hash),
+		Items:     itemsJSON,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(ttl), Valid: true},
+	})
+}
+
+// DeleteExpiredRecognitions удаляет истекшие записи кэша распознавания
+func (m *DBManager) DeleteExpiredRecognitions(ctx context.Context) (int64, error) {
+	return m.Queries.DeleteExpiredRecognitions(ctx)
+}
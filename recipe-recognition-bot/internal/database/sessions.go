@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+)
+
+// RecognitionSession - последний распознанный набор продуктов пользователя
+type RecognitionSession struct {
+	Items           []string
+	SuggestedTitles []string
+}
+
+// StartRecognitionSession запоминает новый набор продуктов и сбрасывает предложенные блюда
+func (m *DBManager) StartRecognitionSession(ctx context.Context, userID int32, items []string) error {
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.Queries.UpsertRecognitionSession(ctx, database.UpsertRecognitionSessionParams{
+		UserID: userID,
+		Items:  itemsJSON,
+	})
+	return err
+}
+
+// GetRecognitionSession возвращает последний набор продуктов пользователя
+func (m *DBManager) GetRecognitionSession(ctx context.Context, userID int32) (*RecognitionSession, error) {
+	row, err := m.Queries.GetRecognitionSession(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var session RecognitionSession
+	if err := json.Unmarshal(row.Items, &session.Items); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(row.SuggestedTitles, &session.SuggestedTitles); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// AddSuggestedTitle отмечает блюдо как уже предложенное в текущей сессии
+func (m *DBManager) AddSuggestedTitle(ctx context.Context, userID int32, title string) error {
+	return m.Queries.AddSuggestedTitle(ctx, database.AddSuggestedTitleParams{
+		Title:  title,
+		UserID: userID,
+	})
+}
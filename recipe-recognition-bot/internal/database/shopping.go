@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/database/generated"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/shopping"
+)
+
+// ErrRecipeInShoppingList - рецепт уже добавлен в список покупок, и в списке есть некупленные продукты
+var ErrRecipeInShoppingList = errors.New("recipe is already in the shopping list")
+
+// ShoppingItems возвращает список покупок пользователя: некупленные продукты первыми
+func (m *DBManager) ShoppingItems(ctx context.Context, userID int32) ([]shopping.Item, error) {
+	rows, err := m.Queries.ListShoppingItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return shoppingItems(rows), nil
+}
+
+// AddShoppingItems добавляет в список покупок продукты рецепта. Количество продукта, который уже есть
+// в списке и еще не куплен, складывается с новым; возвращаются добавленные продукты с итоговым количеством.
+// Рецепт, уже добавленный в список, повторно не добавляется: возвращается ErrRecipeInShoppingList.
+// Когда все продукты списка куплены, рецепты снова можно добавить.
+func (m *DBManager) AddShoppingItems(ctx context.Context, userID int32, recipeID int32, added []shopping.Item) ([]shopping.Item, error) {
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	q := m.Queries.WithTx(tx)
+
+	// Одновременные нажатия кнопки складывают количества по очереди, а не затирают друг друга
+	if err := q.LockShoppingList(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to lock shopping list: %w", err)
+	}
+
+	rows, err := q.ListShoppingItems(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shopping list: %w", err)
+	}
+	list := shoppingItems(rows)
+
+	if !hasUnchecked(list) {
+		if err := q.DeleteShoppingRecipes(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to reset shopping recipes: %w", err)
+		}
+	}
+	marked, err := q.AddShoppingRecipe(ctx, database.AddShoppingRecipeParams{
+		UserID:   userID,
+		RecipeID: recipeID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark shopping recipe: %w", err)
+	}
+	if marked == 0 {
+		return nil, ErrRecipeInShoppingList
+	}
+
+	merged := shopping.Merge(list, added)
+	if len(merged) > 0 {
+		params := database.UpsertShoppingItemsParams{UserID: userID}
+		for _, item := range merged {
+			params.ItemKeys = append(params.ItemKeys, ingredients.Key(item.Name))
+			params.Names = append(params.Names, item.Name)
+			params.Quantities = append(params.Quantities, item.Quantity)
+		}
+		if _, err := q.UpsertShoppingItems(ctx, params); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit shopping list: %w", err)
+	}
+	return merged, nil
+}
+
+func shoppingItems(rows []database.RecipeBotShoppingItem) []shopping.Item {
+	items := make([]shopping.Item, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, shopping.Item{
+			ID:       row.ID,
+			Name:     row.Name,
+			Quantity: row.Quantity,
+			Checked:  row.Checked,
+		})
+	}
+	return items
+}
+
+func hasUnchecked(items []shopping.Item) bool {
+	for _, item := range items {
+		if !item.Checked {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleShoppingItem отмечает продукт купленным или снимает отметку; false - продукта уже нет
+func (m *DBManager) ToggleShoppingItem(ctx context.Context, userID int32, id int64) (bool, error) {
+	toggled, err := m.Queries.ToggleShoppingItem(ctx, database.ToggleShoppingItemParams{
+		ID:     id,
+		UserID: userID,
+	})
+	return toggled > 0, err
+}
+
+// ClearShoppingList удаляет из списка купленные продукты или, если onlyChecked = false, весь список
+func (m *DBManager) ClearShoppingList(ctx context.Context, userID int32, onlyChecked bool) (int64, error) {
+	if onlyChecked {
+		return m.Queries.DeleteCheckedShoppingItems(ctx, userID)
+	}
+	return m.Queries.DeleteShoppingItems(ctx, userID)
+}
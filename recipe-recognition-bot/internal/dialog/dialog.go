@@ -0,0 +1,118 @@
+package dialog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var (
+	// ErrNoSession - в чате нет активного диалога нужного сценария
+	ErrNoSession = errors.New("no active dialog session")
+	// ErrInvalidTransition - обработчик попытался перейти в состояние, не разрешенное сценарием
+	ErrInvalidTransition = errors.New("invalid dialog transition")
+)
+
+// State - именованное состояние сценария
+type State string
+
+// Done - специальное состояние, завершающее диалог
+const Done State = ""
+
+// Session - диалог в одном чате
+type Session struct {
+	ChatID    int64
+	Flow      string
+	State     State
+	Data      json.RawMessage
+	ExpiresAt time.Time
+}
+
+// Decode читает данные сценария
+func (s *Session) Decode(v any) error {
+	if len(s.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(s.Data, v)
+}
+
+// Encode сохраняет данные сценария в сессию
+func (s *Session) Encode(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode dialog data: %w", err)
+	}
+	s.Data = data
+	return nil
+}
+
+// MessageHandler обрабатывает текстовое сообщение и возвращает следующее состояние
+type MessageHandler func(ctx context.Context, s *Session, msg *tgbotapi.Message) (State, error)
+
+// CallbackHandler обрабатывает нажатие инлайн-кнопки и возвращает следующее состояние
+type CallbackHandler func(ctx context.Context, s *Session, query *tgbotapi.CallbackQuery) (State, error)
+
+// StateHandler описывает поведение сценария в одном состоянии
+type StateHandler struct {
+	OnMessage  MessageHandler
+	OnCallback CallbackHandler
+	// Next - состояния, в которые разрешен переход; остаться в текущем и завершить диалог можно всегда
+	Next []State
+}
+
+// Flow - многошаговый сценарий.
+// Данные инлайн-кнопок сценария должны начинаться с "<Name>:".
+type Flow struct {
+	Name    string
+	Initial State
+	// Timeout - время бездействия, после которого диалог сбрасывается; 0 - значение менеджера по умолчанию
+	Timeout time.Duration
+	States  map[State]StateHandler
+}
+
+func (f *Flow) allows(from, to State) bool {
+	if to == from || to == Done {
+		return true
+	}
+	handler, ok := f.States[from]
+	if !ok {
+		return false
+	}
+	for _, next := range handler.Next {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Flow) validate() error {
+	if f.Name == "" {
+		return errors.New("dialog flow name is empty")
+	}
+	if _, ok := f.States[f.Initial]; !ok {
+		return fmt.Errorf("dialog flow %q: initial state %q is not defined", f.Name, f.Initial)
+	}
+	for from, handler := range f.States {
+		for _, next := range handler.Next {
+			if _, ok := f.States[next]; !ok {
+				return fmt.Errorf("dialog flow %q: transition %q -> %q targets undefined state", f.Name, from, next)
+			}
+		}
+	}
+	return nil
+}
+
+// Store - постоянное хранилище диалогов
+type Store interface {
+	// LoadDialog возвращает диалог чата или nil, если его нет
+	LoadDialog(ctx context.Context, chatID int64) (*Session, error)
+	SaveDialog(ctx context.Context, s *Session) error
+	DeleteDialog(ctx context.Context, chatID int64) error
+	// DeleteExpiredDialogs удаляет диалоги, истекшие к моменту now
+	DeleteExpiredDialogs(ctx context.Context, now time.Time) (int64, error)
+}
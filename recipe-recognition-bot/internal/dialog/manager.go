@@ -0,0 +1,253 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// lockStripes - число блокировок, между которыми распределяются чаты. Чаты с общей блокировкой
+// обрабатываются по очереди, но число блокировок не растет с числом чатов.
+const lockStripes = 64
+
+// Manager хранит диалоги чатов и передает события обработчикам зарегистрированных сценариев
+type Manager struct {
+	store          Store
+	logger         *zap.Logger
+	defaultTimeout time.Duration
+	now            func() time.Time
+
+	mu    sync.Mutex
+	flows map[string]*Flow
+	locks [lockStripes]sync.Mutex
+}
+
+// NewManager создает менеджер диалогов
+func NewManager(store Store, defaultTimeout time.Duration, logger *zap.Logger) *Manager {
+	return &Manager{
+		store:          store,
+		logger:         logger,
+		defaultTimeout: defaultTimeout,
+		now:            time.Now,
+		flows:          make(map[string]*Flow),
+	}
+}
+
+// Register добавляет сценарий. Повторная регистрация имени или некорректный сценарий - ошибка программиста.
+func (m *Manager) Register(flow *Flow) {
+	if err := flow.validate(); err != nil {
+		panic(err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.flows[flow.Name]; exists {
+		panic(fmt.Sprintf("dialog: flow %q already registered", flow.Name))
+	}
+	m.flows[flow.Name] = flow
+}
+
+// Start начинает сценарий в чате с начального состояния, заменяя текущий диалог.
+// Не вызывается из обработчиков сценариев: они меняют сессию через возвращаемое состояние.
+func (m *Manager) Start(ctx context.Context, chatID int64, flowName string, data any) (*Session, error) {
+	flow, ok := m.flow(flowName)
+	if !ok {
+		return nil, fmt.Errorf("unknown dialog flow %q", flowName)
+	}
+	return m.StartAt(ctx, chatID, flowName, flow.Initial, data)
+}
+
+// StartAt начинает сценарий в чате с указанного состояния
+func (m *Manager) StartAt(ctx context.Context, chatID int64, flowName string, state State, data any) (*Session, error) {
+	flow, ok := m.flow(flowName)
+	if !ok {
+		return nil, fmt.Errorf("unknown dialog flow %q", flowName)
+	}
+	if _, ok := flow.States[state]; !ok {
+		return nil, fmt.Errorf("dialog flow %q: state %q is not defined", flowName, state)
+	}
+
+	unlock := m.lock(chatID)
+	defer unlock()
+
+	s := &Session{
+		ChatID: chatID,
+		Flow:   flow.Name,
+		State:  state,
+	}
+	if err := s.Encode(data); err != nil {
+		return nil, err
+	}
+	if err := m.save(ctx, flow, s); err != nil {
+		return nil, err
+	}
+
+	m.logger.Debug("Dialog started",
+		zap.Int64("chat_id", chatID),
+		zap.String("flow", flow.Name),
+		zap.String("state", string(s.State)))
+	return s, nil
+}
+
+// Update сохраняет данные сессии, измененные вне обработчиков, и продлевает ее
+func (m *Manager) Update(ctx context.Context, s *Session) error {
+	flow, ok := m.flow(s.Flow)
+	if !ok {
+		return fmt.Errorf("unknown dialog flow %q", s.Flow)
+	}
+	return m.save(ctx, flow, s)
+}
+
+// Current возвращает активный диалог чата или nil
+func (m *Manager) Current(ctx context.Context, chatID int64) (*Session, error) {
+	s, err := m.store.LoadDialog(ctx, chatID)
+	if err != nil || s == nil {
+		return nil, err
+	}
+	if _, ok := m.flow(s.Flow); !ok || !m.now().Before(s.ExpiresAt) {
+		return nil, nil
+	}
+	return s, nil
+}
+
+// Finish завершает диалог в чате
+func (m *Manager) Finish(ctx context.Context, chatID int64) error {
+	return m.store.DeleteDialog(ctx, chatID)
+}
+
+// HandleMessage передает текстовое сообщение активному диалогу.
+// Возвращает false, если в чате нет диалога, ожидающего текст.
+func (m *Manager) HandleMessage(ctx context.Context, msg *tgbotapi.Message) (bool, error) {
+	chatID := msg.Chat.ID
+
+	unlock := m.lock(chatID)
+	defer unlock()
+
+	s, err := m.Current(ctx, chatID)
+	if err != nil || s == nil {
+		return false, err
+	}
+
+	flow, _ := m.flow(s.Flow)
+	handler := flow.States[s.State]
+	if handler.OnMessage == nil {
+		return false, nil
+	}
+
+	next, err := handler.OnMessage(ctx, s, msg)
+	if err != nil {
+		return true, err
+	}
+	return true, m.transition(ctx, flow, s, next)
+}
+
+// HandleCallback передает нажатие кнопки сценарию, которому принадлежат данные кнопки.
+// Возвращает false, если данные не относятся ни к одному сценарию,
+// и ErrNoSession, если сценарий в чате уже не активен.
+func (m *Manager) HandleCallback(ctx context.Context, query *tgbotapi.CallbackQuery) (bool, error) {
+	name, _, found := strings.Cut(query.Data, ":")
+	if !found {
+		return false, nil
+	}
+	flow, ok := m.flow(name)
+	if !ok {
+		return false, nil
+	}
+
+	chatID := query.Message.Chat.ID
+
+	unlock := m.lock(chatID)
+	defer unlock()
+
+	s, err := m.Current(ctx, chatID)
+	if err != nil {
+		return true, err
+	}
+	if s == nil || s.Flow != flow.Name {
+		return true, ErrNoSession
+	}
+
+	handler := flow.States[s.State]
+	if handler.OnCallback == nil {
+		return true, ErrNoSession
+	}
+
+	next, err := handler.OnCallback(ctx, s, query)
+	if err != nil {
+		return true, err
+	}
+	return true, m.transition(ctx, flow, s, next)
+}
+
+// Run периодически удаляет истекшие диалоги до отмены контекста
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := m.store.DeleteExpiredDialogs(ctx, m.now())
+			if err != nil {
+				m.logger.Warn("Failed to delete expired dialogs", zap.Error(err))
+				continue
+			}
+			if removed > 0 {
+				m.logger.Debug("Expired dialogs deleted", zap.Int64("count", removed))
+			}
+		}
+	}
+}
+
+func (m *Manager) transition(ctx context.Context, flow *Flow, s *Session, next State) error {
+	if !flow.allows(s.State, next) {
+		return fmt.Errorf("%w: flow %q %q -> %q", ErrInvalidTransition, flow.Name, s.State, next)
+	}
+
+	if next == Done {
+		m.logger.Debug("Dialog finished", zap.Int64("chat_id", s.ChatID), zap.String("flow", flow.Name))
+		return m.store.DeleteDialog(ctx, s.ChatID)
+	}
+
+	if next != s.State {
+		m.logger.Debug("Dialog transition",
+			zap.Int64("chat_id", s.ChatID),
+			zap.String("flow", flow.Name),
+			zap.String("from", string(s.State)),
+			zap.String("to", string(next)))
+	}
+	s.State = next
+	return m.save(ctx, flow, s)
+}
+
+func (m *Manager) save(ctx context.Context, flow *Flow, s *Session) error {
+	timeout := flow.Timeout
+	if timeout <= 0 {
+		timeout = m.defaultTimeout
+	}
+	s.ExpiresAt = m.now().Add(timeout)
+	return m.store.SaveDialog(ctx, s)
+}
+
+func (m *Manager) flow(name string) (*Flow, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	flow, ok := m.flows[name]
+	return flow, ok
+}
+
+// lock сериализует обработку событий одного чата: обновления приходят в отдельных горутинах
+func (m *Manager) lock(chatID int64) func() {
+	// ID групповых чатов отрицательные
+	l := &m.locks[uint64(chatID)%lockStripes]
+	l.Lock()
+	return l.Unlock
+}
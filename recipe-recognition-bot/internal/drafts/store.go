@@ -0,0 +1,129 @@
+package drafts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+)
+
+// Draft - сгенерированный, но еще не сохраненный рецепт
+type Draft struct {
+	Recipe *recipes.Recipe
+	// Products - продукты, из которых генерировался рецепт
+	Products []string
+	// Priority - продукты с истекающим сроком, которые рецепт должен использовать в первую очередь
+	Priority []string
+	// MessageID - сообщение с рецептом и кнопками; кнопки старых сообщений не действуют
+	MessageID int
+	CreatedAt time.Time
+}
+
+// Store хранит по одному черновику на пользователя и удаляет устаревшие
+type Store struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	drafts map[int64]Draft
+	now    func() time.Time
+}
+
+// NewStore создает хранилище черновиков с заданным временем жизни
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		ttl:    ttl,
+		drafts: make(map[int64]Draft),
+		now:    time.Now,
+	}
+}
+
+// Put сохраняет черновик пользователя, заменяя предыдущий
+func (s *Store) Put(userID int64, draft Draft) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if draft.CreatedAt.IsZero() {
+		draft.CreatedAt = s.now()
+	}
+	s.drafts[userID] = draft
+}
+
+// Get возвращает черновик пользователя, если он еще не устарел
+func (s *Store) Get(userID int64) (Draft, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft, ok := s.drafts[userID]
+	if !ok {
+		return Draft{}, false
+	}
+	if s.expired(draft) {
+		delete(s.drafts, userID)
+		return Draft{}, false
+	}
+	return draft, true
+}
+
+// Take забирает черновик пользователя, привязанный к сообщению messageID. Проверка и удаление
+// идут под одной блокировкой, поэтому из двух одновременных нажатий черновик получит только одно.
+func (s *Store) Take(userID int64, messageID int) (Draft, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft, ok := s.drafts[userID]
+	if !ok || draft.MessageID != messageID {
+		return Draft{}, false
+	}
+	delete(s.drafts, userID)
+	if s.expired(draft) {
+		return Draft{}, false
+	}
+	return draft, true
+}
+
+// Restore возвращает взятый черновик, если пользователь еще не получил новый
+func (s *Store) Restore(userID int64, draft Draft) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.drafts[userID]; !ok {
+		s.drafts[userID] = draft
+	}
+}
+
+// Delete удаляет черновик пользователя
+func (s *Store) Delete(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.drafts, userID)
+}
+
+// Run периодически удаляет устаревшие черновики до отмены контекста
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep()
+		}
+	}
+}
+
+func (s *Store) sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for userID, draft := range s.drafts {
+		if s.expired(draft) {
+			delete(s.drafts, userID)
+		}
+	}
+}
+
+func (s *Store) expired(draft Draft) bool {
+	return s.now().Sub(draft.CreatedAt) > s.ttl
+}
@@ -0,0 +1,75 @@
+package drafts
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+)
+
+func TestStoreTake(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	s := NewStore(time.Hour)
+	s.now = func() time.Time { return now }
+	s.Put(1, Draft{Recipe: &recipes.Recipe{Title: "омлет"}, MessageID: 10})
+
+	if _, ok := s.Take(1, 9); ok {
+		t.Fatal("Take() with another message returned the draft")
+	}
+	draft, ok := s.Take(1, 10)
+	if !ok || draft.Recipe.Title != "омлет" {
+		t.Fatalf("Take() = %+v, %v; want the draft", draft, ok)
+	}
+	if _, ok := s.Take(1, 10); ok {
+		t.Error("draft was taken twice")
+	}
+
+	s.Put(2, Draft{MessageID: 20})
+	now = now.Add(2 * time.Hour)
+	if _, ok := s.Take(2, 20); ok {
+		t.Error("Take() returned an expired draft")
+	}
+}
+
+func TestStoreTakeConcurrent(t *testing.T) {
+	s := NewStore(time.Hour)
+	s.Put(1, Draft{MessageID: 10})
+
+	var taken atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := s.Take(1, 10); ok {
+				taken.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if taken.Load() != 1 {
+		t.Errorf("draft taken %d times, want 1", taken.Load())
+	}
+}
+
+func TestStoreRestore(t *testing.T) {
+	s := NewStore(time.Hour)
+	s.Put(1, Draft{MessageID: 10})
+
+	draft, _ := s.Take(1, 10)
+	s.Restore(1, draft)
+	if got, ok := s.Get(1); !ok || got.MessageID != 10 {
+		t.Fatalf("Get() after Restore() = %+v, %v; want the restored draft", got, ok)
+	}
+
+	// Новый рецепт, полученный, пока сохранялся старый, не заменяется возвращенным черновиком
+	draft, _ = s.Take(1, 10)
+	s.Put(1, Draft{MessageID: 11})
+	s.Restore(1, draft)
+	if got, _ := s.Get(1); got.MessageID != 11 {
+		t.Errorf("Restore() replaced the newer draft with message %d", got.MessageID)
+	}
+}
@@ -0,0 +1,112 @@
+package imageprep
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+)
+
+// Box - рамка в долях ширины и высоты изображения
+type Box struct {
+	X, Y, Width, Height float64
+	// Label - положительный номер, который подписывается в углу рамки
+	Label int
+}
+
+// palette - цвета рамок; соседние номера получают разные цвета
+var palette = []color.RGBA{
+	{R: 230, G: 25, B: 75, A: 255},
+	{R: 60, G: 180, B: 75, A: 255},
+	{R: 0, G: 130, B: 200, A: 255},
+	{R: 245, G: 130, B: 48, A: 255},
+	{R: 145, G: 30, B: 180, A: 255},
+	{R: 0, G: 160, B: 160, A: 255},
+	{R: 240, G: 50, B: 230, A: 255},
+	{R: 128, G: 128, B: 0, A: 255},
+}
+
+// digits - цифры шрифтом 3x5: по строке на элемент, старший из трех битов - левый пиксель.
+// Шрифт встроен, чтобы не зависеть от пакетов с растеризацией TrueType.
+var digits = [10][5]uint8{
+	{0b111, 0b101, 0b101, 0b101, 0b111},
+	{0b010, 0b110, 0b010, 0b010, 0b111},
+	{0b111, 0b001, 0b111, 0b100, 0b111},
+	{0b111, 0b001, 0b111, 0b001, 0b111},
+	{0b101, 0b101, 0b111, 0b001, 0b001},
+	{0b111, 0b100, 0b111, 0b001, 0b111},
+	{0b111, 0b100, 0b111, 0b101, 0b111},
+	{0b111, 0b001, 0b001, 0b001, 0b001},
+	{0b111, 0b101, 0b111, 0b101, 0b111},
+	{0b111, 0b101, 0b111, 0b001, 0b111},
+}
+
+// Annotate готовит изображение так же, как Process, и рисует на нем пронумерованные рамки.
+// Координаты рамок относятся к повернутому по EXIF изображению - тому, которое видела модель.
+func Annotate(data []byte, boxes []Box, opts Options) ([]byte, error) {
+	img, _, _, err := prepare(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	thickness := max(2, min(width, height)/250)
+	scale := max(2, min(width, height)/80)
+
+	for _, box := range boxes {
+		c := palette[(box.Label-1+len(palette))%len(palette)]
+		rect := image.Rect(
+			int(box.X*float64(width)), int(box.Y*float64(height)),
+			int((box.X+box.Width)*float64(width)), int((box.Y+box.Height)*float64(height)),
+		).Intersect(img.Bounds())
+		if rect.Empty() {
+			continue
+		}
+
+		drawFrame(img, rect, thickness, c)
+		drawLabel(img, rect.Min, strconv.Itoa(box.Label), scale, c)
+	}
+
+	return encodeJPEG(img, opts.Quality)
+}
+
+// drawFrame рисует контур прямоугольника линией заданной толщины внутрь
+func drawFrame(img *image.RGBA, rect image.Rectangle, thickness int, c color.RGBA) {
+	fill := image.NewUniform(c)
+	t := min(thickness, rect.Dx()/2, rect.Dy()/2)
+	t = max(t, 1)
+
+	draw.Draw(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+t), fill, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(rect.Min.X, rect.Max.Y-t, rect.Max.X, rect.Max.Y), fill, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+t, rect.Max.Y), fill, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(rect.Max.X-t, rect.Min.Y, rect.Max.X, rect.Max.Y), fill, image.Point{}, draw.Src)
+}
+
+// drawLabel рисует номер белыми цифрами на плашке цвета рамки. Плашка сдвигается
+// внутрь изображения, если рамка касается правого или нижнего края.
+func drawLabel(img *image.RGBA, at image.Point, text string, scale int, c color.RGBA) {
+	width := (len(text)*4 + 1) * scale
+	height := 7 * scale
+	bounds := img.Bounds()
+	at.X = max(bounds.Min.X, min(at.X, bounds.Max.X-width))
+	at.Y = max(bounds.Min.Y, min(at.Y, bounds.Max.Y-height))
+
+	draw.Draw(img, image.Rect(at.X, at.Y, at.X+width, at.Y+height), image.NewUniform(c), image.Point{}, draw.Src)
+
+	white := image.NewUniform(color.White)
+	x := at.X + scale
+	for _, r := range text {
+		glyph := digits[r-'0']
+		for row, bits := range glyph {
+			for col := 0; col < 3; col++ {
+				if bits&(0b100>>col) == 0 {
+					continue
+				}
+				px := x + col*scale
+				py := at.Y + scale + row*scale
+				draw.Draw(img, image.Rect(px, py, px+scale, py+scale), white, image.Point{}, draw.Src)
+			}
+		}
+		x += 4 * scale
+	}
+}
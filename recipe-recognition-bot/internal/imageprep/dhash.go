@@ -0,0 +1,45 @@
+package imageprep
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math/bits"
+)
+
+// DHash вычисляет разностный перцептивный хеш: изображение уменьшается до 9x8 в оттенках серого,
+// и каждый бит показывает, светлее ли пиксель своего правого соседа. Хеш устойчив к масштабу,
+// перекодированию и небольшим изменениям яркости, поэтому почти одинаковые фото дают близкие хеши.
+func DHash(img image.Image) uint64 {
+	small := downscale(toRGBA(img), 9, 8)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if luminance(small, x, y) > luminance(small, x+1, y) {
+				hash |= 1 << (y*8 + x)
+			}
+		}
+	}
+	return hash
+}
+
+// DHashBytes декодирует изображение и вычисляет его DHash
+func DHashBytes(data []byte) (uint64, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrUndecodable, err)
+	}
+	return DHash(img), nil
+}
+
+// Distance - расстояние Хэмминга между хешами: число различающихся битов
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// luminance - яркость пикселя по ITU-R BT.601 в целых числах
+func luminance(img *image.RGBA, x, y int) int {
+	p := img.Pix[y*img.Stride+x*4:]
+	return 299*int(p[0]) + 587*int(p[1]) + 114*int(p[2])
+}
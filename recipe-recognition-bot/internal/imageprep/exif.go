@@ -0,0 +1,102 @@
+package imageprep
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Значения тега EXIF Orientation: как повернуть и отразить сохраненное изображение для показа
+const (
+	orientationNormal      = 1
+	orientationFlipH       = 2
+	orientationRotate180   = 3
+	orientationFlipV       = 4
+	orientationTranspose   = 5
+	orientationRotate90CW  = 6
+	orientationTransverse  = 7
+	orientationRotate90CCW = 8
+)
+
+const exifOrientationTag = 0x0112
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// jpegOrientation читает тег Orientation из сегмента APP1 JPEG-файла.
+// Для файлов без EXIF и при любых ошибках разбора возвращает orientationNormal.
+func jpegOrientation(data []byte) int {
+	// SOI
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return orientationNormal
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return orientationNormal
+		}
+		marker := data[pos+1]
+		// Байты-заполнители 0xFF допустимы между сегментами
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		// SOS: дальше идут сжатые данные, метаданных уже не будет
+		if marker == 0xDA {
+			return orientationNormal
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 || pos+2+length > len(data) {
+			return orientationNormal
+		}
+		segment := data[pos+4 : pos+2+length]
+
+		// APP1 с EXIF
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifHeader) {
+			return tiffOrientation(segment[len(exifHeader):])
+		}
+		pos += 2 + length
+	}
+	return orientationNormal
+}
+
+// tiffOrientation ищет тег Orientation в первом IFD TIFF-структуры EXIF
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return orientationNormal
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return orientationNormal
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return orientationNormal
+	}
+
+	// Запись IFD: тег (2), тип (2), количество (4), значение (4)
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return orientationNormal
+		}
+		if order.Uint16(tiff[entry:entry+2]) != exifOrientationTag {
+			continue
+		}
+
+		value := int(order.Uint16(tiff[entry+8 : entry+10]))
+		if value < orientationNormal || value > orientationRotate90CCW {
+			return orientationNormal
+		}
+		return value
+	}
+	return orientationNormal
+}
@@ -0,0 +1,80 @@
+package imageprep
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+// exifSegment собирает сегмент APP1 с EXIF, в первом IFD которого один тег Orientation
+func exifSegment(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	entry := tiff[10:]
+	order.PutUint16(entry[0:], exifOrientationTag)
+	order.PutUint16(entry[2:], 3) // SHORT
+	order.PutUint32(entry[4:], 1)
+	order.PutUint16(entry[8:], orientation)
+
+	payload := append(append([]byte(nil), exifHeader...), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// withSegments вставляет сегменты сразу после маркера SOI
+func withSegments(jpegData []byte, segments ...[]byte) []byte {
+	result := append([]byte(nil), jpegData[:2]...)
+	for _, segment := range segments {
+		result = append(result, segment...)
+	}
+	return append(result, jpegData[2:]...)
+}
+
+func encodeTestJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	plain := encodeTestJPEG(t, 4, 2)
+	app0 := []byte{0xFF, 0xE0, 0x00, 0x07, 'J', 'F', 'I', 'F', 0}
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"без EXIF", plain, orientationNormal},
+		{"Intel, поворот на 90°", withSegments(plain, exifSegment(binary.LittleEndian, 6)), orientationRotate90CW},
+		{"Motorola, поворот на 270°", withSegments(plain, exifSegment(binary.BigEndian, 8)), orientationRotate90CCW},
+		{"EXIF после APP0", withSegments(plain, app0, exifSegment(binary.BigEndian, 3)), orientationRotate180},
+		{"байты-заполнители", withSegments(plain, []byte{0xFF}, exifSegment(binary.LittleEndian, 2)), orientationFlipH},
+		{"недопустимое значение", withSegments(plain, exifSegment(binary.LittleEndian, 9)), orientationNormal},
+		{"оборванный сегмент", exifSegment(binary.LittleEndian, 6)[:12], orientationNormal},
+		{"обрезанный файл", withSegments(plain, exifSegment(binary.LittleEndian, 6))[:20], orientationNormal},
+		{"не JPEG", []byte("\x89PNG\r\n\x1a\n"), orientationNormal},
+		{"пустые данные", nil, orientationNormal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
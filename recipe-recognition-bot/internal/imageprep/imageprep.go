@@ -0,0 +1,119 @@
+package imageprep
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // регистрация декодера GIF
+	"image/jpeg"
+	_ "image/png" // регистрация декодера PNG
+
+	_ "golang.org/x/image/webp" // регистрация декодера WEBP
+)
+
+// ErrUndecodable - изображение не удалось декодировать: файл поврежден или формат не поддерживается
+var ErrUndecodable = errors.New("image format cannot be decoded")
+
+const (
+	defaultQuality = 85
+	// maxPixels защищает от изображений, которые при декодировании займут слишком много памяти
+	maxPixels = 50_000_000
+)
+
+// Options - параметры подготовки изображения
+type Options struct {
+	// MaxDimension - ограничение на большую сторону после уменьшения; 0 - не уменьшать
+	MaxDimension int
+	// Quality - качество JPEG от 1 до 100
+	Quality int
+}
+
+// Result - подготовленное изображение
+type Result struct {
+	Data           []byte
+	MIMEType       string
+	Width, Height  int
+	OriginalWidth  int
+	OriginalHeight int
+	// Orientation - примененное значение тега EXIF Orientation
+	Orientation int
+}
+
+// Process поворачивает изображение по EXIF, уменьшает до MaxDimension и перекодирует в JPEG.
+// Результат не содержит метаданных исходного файла (EXIF, GPS, профили камер):
+// кодировщик стандартной библиотеки их не записывает.
+func Process(data []byte, opts Options) (*Result, error) {
+	img, config, orientation, err := prepare(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := encodeJPEG(img, opts.Quality)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Data:           encoded,
+		MIMEType:       "image/jpeg",
+		Width:          img.Bounds().Dx(),
+		Height:         img.Bounds().Dy(),
+		OriginalWidth:  config.Width,
+		OriginalHeight: config.Height,
+		Orientation:    orientation,
+	}, nil
+}
+
+// Decode декодирует изображение без поворота и уменьшения.
+// Изображения больше maxPixels не декодируются, чтобы не занять слишком много памяти.
+func Decode(data []byte) (image.Image, error) {
+	img, _, _, err := decode(data)
+	return img, err
+}
+
+func decode(data []byte) (image.Image, image.Config, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, config, "", fmt.Errorf("%w: %v", ErrUndecodable, err)
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, config, format, fmt.Errorf("image is too large: %dx%d", config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, config, format, fmt.Errorf("failed to decode %s image: %w", format, err)
+	}
+	return img, config, format, nil
+}
+
+// prepare декодирует изображение, уменьшает до MaxDimension и поворачивает по EXIF
+func prepare(data []byte, opts Options) (*image.RGBA, image.Config, int, error) {
+	img, config, format, err := decode(data)
+	if err != nil {
+		return nil, config, 0, err
+	}
+
+	orientation := orientationNormal
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	// Уменьшаем до поворота: так поворачивается уже небольшое изображение
+	rgba := toRGBA(img)
+	width, height := fitSize(rgba.Bounds().Dx(), rgba.Bounds().Dy(), opts.MaxDimension)
+	return orient(downscale(rgba, width, height), orientation), config, orientation, nil
+}
+
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	if quality <= 0 || quality > 100 {
+		quality = defaultQuality
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,78 @@
+package imageprep
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// testWEBP - изображение WEBP (VP8) размером 1x1
+const testWEBP = "UklGRiIAAABXRUJQVlA4IBYAAAAwAQCdASoBAAEADsD+JaQAA3AAAAAA"
+
+func TestProcess(t *testing.T) {
+	webp, err := base64.StdEncoding.DecodeString(testWEBP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                 string
+		data                 []byte
+		opts                 Options
+		wantW, wantH         int
+		wantOrientation      int
+		wantOrigW, wantOrigH int
+	}{
+		{
+			name:  "уменьшение",
+			data:  encodeTestJPEG(t, 400, 200),
+			opts:  Options{MaxDimension: 100},
+			wantW: 100, wantH: 50, wantOrientation: orientationNormal,
+			wantOrigW: 400, wantOrigH: 200,
+		},
+		{
+			name:  "поворот по EXIF",
+			data:  withSegments(encodeTestJPEG(t, 40, 20), exifSegment(binary.LittleEndian, orientationRotate90CW)),
+			wantW: 20, wantH: 40, wantOrientation: orientationRotate90CW,
+			wantOrigW: 40, wantOrigH: 20,
+		},
+		{
+			name:  "WEBP",
+			data:  webp,
+			wantW: 1, wantH: 1, wantOrientation: orientationNormal,
+			wantOrigW: 1, wantOrigH: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Process(tt.data, tt.opts)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if result.Width != tt.wantW || result.Height != tt.wantH || result.Orientation != tt.wantOrientation {
+				t.Errorf("Process() = %dx%d orientation %d, want %dx%d orientation %d",
+					result.Width, result.Height, result.Orientation, tt.wantW, tt.wantH, tt.wantOrientation)
+			}
+			if result.OriginalWidth != tt.wantOrigW || result.OriginalHeight != tt.wantOrigH {
+				t.Errorf("original size = %dx%d, want %dx%d", result.OriginalWidth, result.OriginalHeight, tt.wantOrigW, tt.wantOrigH)
+			}
+			if result.MIMEType != "image/jpeg" || !bytes.HasPrefix(result.Data, []byte{0xFF, 0xD8}) {
+				t.Errorf("result is not a JPEG: %s", result.MIMEType)
+			}
+			if bytes.Contains(result.Data, exifHeader) {
+				t.Error("result keeps the EXIF segment")
+			}
+		})
+	}
+}
+
+func TestProcessUndecodable(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("not an image"), []byte("\x89PNG\r\n\x1a\n")} {
+		if _, err := Process(data, Options{}); !errors.Is(err, ErrUndecodable) {
+			t.Errorf("Process(%q) error = %v, want ErrUndecodable", data, err)
+		}
+	}
+}
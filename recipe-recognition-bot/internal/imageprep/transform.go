@@ -0,0 +1,107 @@
+package imageprep
+
+import (
+	"image"
+	"image/draw"
+)
+
+// toRGBA переводит изображение в RGBA, накладывая прозрачные области на белый фон:
+// в JPEG нет альфа-канала, а черный фон сбивает модели распознавания
+func toRGBA(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+	return dst
+}
+
+// fitSize возвращает размеры, при которых большая сторона не превышает maxDimension, с сохранением пропорций
+func fitSize(width, height, maxDimension int) (int, int) {
+	if maxDimension <= 0 || (width <= maxDimension && height <= maxDimension) {
+		return width, height
+	}
+	if width >= height {
+		return maxDimension, max(1, height*maxDimension/width)
+	}
+	return max(1, width*maxDimension/height), maxDimension
+}
+
+// downscale уменьшает изображение усреднением по площади: каждый пиксель результата -
+// среднее всех исходных пикселей, которые он покрывает. Для уменьшения это дает
+// качество не хуже билинейной интерполяции и не требует внешних библиотек.
+func downscale(src *image.RGBA, width, height int) *image.RGBA {
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+	if width == srcW && height == srcH {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := y * srcH / height
+		y1 := max(y0+1, (y+1)*srcH/height)
+		for x := 0; x < width; x++ {
+			x0 := x * srcW / width
+			x1 := max(x0+1, (x+1)*srcW/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// orient поворачивает и отражает изображение согласно тегу EXIF Orientation
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation == orientationNormal {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dstW, dstH := w, h
+	if orientation >= orientationTranspose {
+		dstW, dstH = h, w
+	}
+
+	// source возвращает координаты исходного пикселя для пикселя результата (x, y)
+	var source func(x, y int) (int, int)
+	switch orientation {
+	case orientationFlipH:
+		source = func(x, y int) (int, int) { return w - 1 - x, y }
+	case orientationRotate180:
+		source = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case orientationFlipV:
+		source = func(x, y int) (int, int) { return x, h - 1 - y }
+	case orientationTranspose:
+		source = func(x, y int) (int, int) { return y, x }
+	case orientationRotate90CW:
+		source = func(x, y int) (int, int) { return y, h - 1 - x }
+	case orientationTransverse:
+		source = func(x, y int) (int, int) { return w - 1 - y, h - 1 - x }
+	case orientationRotate90CCW:
+		source = func(x, y int) (int, int) { return w - 1 - y, x }
+	default:
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			sx, sy := source(x, y)
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:sy*src.Stride+sx*4+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,91 @@
+package imageprep
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+// labeledImage создает изображение, в красном канале каждого пикселя которого записана его метка
+func labeledImage(rows [][]uint8) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x, label := range row {
+			img.Pix[y*img.Stride+x*4] = label
+		}
+	}
+	return img
+}
+
+func labels(img *image.RGBA) [][]uint8 {
+	rows := make([][]uint8, img.Bounds().Dy())
+	for y := range rows {
+		rows[y] = make([]uint8, img.Bounds().Dx())
+		for x := range rows[y] {
+			rows[y][x] = img.Pix[y*img.Stride+x*4]
+		}
+	}
+	return rows
+}
+
+func TestOrient(t *testing.T) {
+	// Сохраненное изображение:
+	//   A B C
+	//   D E F
+	const (
+		A = iota + 1
+		B
+		C
+		D
+		E
+		F
+	)
+	src := [][]uint8{{A, B, C}, {D, E, F}}
+
+	tests := []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{orientationNormal, [][]uint8{{A, B, C}, {D, E, F}}},
+		{orientationFlipH, [][]uint8{{C, B, A}, {F, E, D}}},
+		{orientationRotate180, [][]uint8{{F, E, D}, {C, B, A}}},
+		{orientationFlipV, [][]uint8{{D, E, F}, {A, B, C}}},
+		{orientationTranspose, [][]uint8{{A, D}, {B, E}, {C, F}}},
+		{orientationRotate90CW, [][]uint8{{D, A}, {E, B}, {F, C}}},
+		{orientationTransverse, [][]uint8{{F, C}, {E, B}, {D, A}}},
+		{orientationRotate90CCW, [][]uint8{{C, F}, {B, E}, {A, D}}},
+		{0, [][]uint8{{A, B, C}, {D, E, F}}},
+	}
+
+	for _, tt := range tests {
+		got := labels(orient(labeledImage(src), tt.orientation))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("orient(%d) = %v, want %v", tt.orientation, got, tt.want)
+		}
+	}
+}
+
+func TestFitSize(t *testing.T) {
+	tests := []struct {
+		width, height, max int
+		wantW, wantH       int
+	}{
+		{4000, 3000, 1024, 1024, 768},
+		{3000, 4000, 1024, 768, 1024},
+		{2000, 2000, 1000, 1000, 1000},
+		{800, 600, 1024, 800, 600},
+		{1024, 1024, 1024, 1024, 1024},
+		{4000, 3000, 0, 4000, 3000},
+		{4000, 3000, -1, 4000, 3000},
+		// Очень узкая полоса не схлопывается в ноль пикселей
+		{10000, 2, 100, 100, 1},
+		{2, 10000, 100, 1, 100},
+	}
+
+	for _, tt := range tests {
+		w, h := fitSize(tt.width, tt.height, tt.max)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("fitSize(%d, %d, %d) = %dx%d, want %dx%d", tt.width, tt.height, tt.max, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
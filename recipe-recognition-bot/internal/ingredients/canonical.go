@@ -0,0 +1,110 @@
+package ingredients
+
+import "strings"
+
+// synonyms сводит разные названия одного продукта к одному.
+// Ключи и значения - нормализованные названия; формы числа учитываются отдельно через stem.
+var synonyms = map[string]string{
+	"томат":              "помидор",
+	"черри":              "помидор",
+	"картошка":           "картофель",
+	"огурец":             "огурцы",
+	"куриное яйцо":       "яйцо",
+	"яйцо куриное":       "яйцо",
+	"куриные яйца":       "яйцо",
+	"филе курицы":        "куриное филе",
+	"куриная грудка":     "куриное филе",
+	"репчатый лук":       "лук",
+	"лук репчатый":       "лук",
+	"сливочное масло":    "масло сливочное",
+	"подсолнечное масло": "масло растительное",
+	"растительное масло": "масло растительное",
+	"eggs":               "яйцо",
+	"egg":                "яйцо",
+	"tomato":             "помидор",
+	"cheese":             "сыр",
+	"milk":               "молоко",
+}
+
+// synonymKeys - синонимы, приведенные к ключам сравнения
+var synonymKeys = func() map[string]string {
+	keys := make(map[string]string, len(synonyms))
+	for variant, canonical := range synonyms {
+		keys[stemPhrase(variant)] = stemPhrase(canonical)
+	}
+	return keys
+}()
+
+// russianEndings - окончания, отбрасываемые при сравнении, от длинных к коротким
+var russianEndings = []string{"ами", "ями", "ов", "ев", "ей", "ы", "и", "а", "я", "о", "е", "ь", "й"}
+
+// Key возвращает ключ сравнения продукта: одинаковый для разных регистров,
+// форм единственного и множественного числа и известных синонимов.
+// Ключ служит только для сравнения и не показывается пользователю.
+func Key(name string) string {
+	key := stemPhrase(Normalize(name))
+	if canonical, ok := synonymKeys[key]; ok {
+		return canonical
+	}
+	return key
+}
+
+// Merge объединяет несколько списков продуктов, оставляя первое встретившееся название каждого продукта
+func Merge(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var merged []string
+
+	for _, list := range lists {
+		for _, item := range list {
+			name := Normalize(item)
+			key := Key(name)
+			if !Valid(name) || seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, name)
+		}
+	}
+	return merged
+}
+
+// stemPhrase отбрасывает окончания у каждого слова названия
+func stemPhrase(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		words[i] = stem(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// stem - упрощенное отбрасывание окончаний: достаточно, чтобы "помидор" и "помидоры",
+// "яйцо" и "яйца", "egg" и "eggs" совпадали, но не претендует на морфологический анализ
+func stem(word string) string {
+	runes := []rune(word)
+	if len(runes) <= 3 {
+		return word
+	}
+
+	if isLatin(runes[0]) {
+		switch {
+		case strings.HasSuffix(word, "oes"):
+			return strings.TrimSuffix(word, "es")
+		case strings.HasSuffix(word, "ies"):
+			return strings.TrimSuffix(word, "ies") + "y"
+		case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+			return strings.TrimSuffix(word, "s")
+		}
+		return word
+	}
+
+	for _, ending := range russianEndings {
+		if strings.HasSuffix(word, ending) && len(runes)-len([]rune(ending)) >= 3 {
+			return strings.TrimSuffix(word, ending)
+		}
+	}
+	return word
+}
+
+func isLatin(r rune) bool {
+	return r >= 'a' && r <= 'z'
+}
@@ -0,0 +1,69 @@
+package ingredients
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// MaxItems - сколько продуктов принимается из одного списка
+	MaxItems = 30
+	// MaxWords - продукт длиннее считается фразой, а не названием
+	MaxWords = 5
+	// MaxCharacters - ограничение на длину названия продукта
+	MaxCharacters = 60
+)
+
+var (
+	// listMarker - маркер пункта списка: "-", "*", "•", "1.", "1)"
+	listMarker = regexp.MustCompile(`^(?:[-*•–—]|\d+[.)])\s+`)
+	// leadingQuantity - количество перед названием: "2 яйца", "200 г сыра", "1.5л молока"
+	leadingQuantity = regexp.MustCompile(`^\d+(?:[.,]\d+)?\s*(?:шт|штук[аи]?|г|гр|кг|мл|л|pcs|g|kg|ml|l)?\.?\s+`)
+)
+
+// Normalize приводит название продукта к каноническому виду:
+// нижний регистр, "е" вместо "ё", без маркера списка, количества, кавычек и лишних пробелов.
+// Пустая строка означает, что названия нет.
+func Normalize(name string) string {
+	name = strings.TrimSpace(name)
+	name = listMarker.ReplaceAllString(name, "")
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, "ё", "е")
+	name = leadingQuantity.ReplaceAllString(name, "")
+	name = strings.Join(strings.Fields(name), " ")
+	return strings.Trim(name, `"'«»*.,;:!()`)
+}
+
+// Valid сообщает, похоже ли нормализованное название на продукт, а не на фразу
+func Valid(name string) bool {
+	return name != "" &&
+		len([]rune(name)) <= MaxCharacters &&
+		len(strings.Fields(name)) <= MaxWords &&
+		!strings.HasSuffix(name, "?")
+}
+
+// Clean нормализует названия, отбрасывает неподходящие и повторяющиеся (с учетом Key)
+// и оставляет не больше limit продуктов
+func Clean(items []string, limit int) []string {
+	seen := make(map[string]bool, len(items))
+	cleaned := make([]string, 0, len(items))
+
+	for _, item := range items {
+		// Пояснения модели вида "Продукты:" не являются продуктами
+		if strings.HasSuffix(strings.TrimSpace(item), ":") {
+			continue
+		}
+
+		name := Normalize(item)
+		key := Key(name)
+		if !Valid(name) || seen[key] {
+			continue
+		}
+		seen[key] = true
+		cleaned = append(cleaned, name)
+		if len(cleaned) == limit {
+			break
+		}
+	}
+	return cleaned
+}
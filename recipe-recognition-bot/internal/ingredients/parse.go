@@ -0,0 +1,13 @@
+package ingredients
+
+import "regexp"
+
+// separators - разделители продуктов в свободном тексте: запятые, точки с запятой, переводы строк
+// и союз "и", которым продукты соединяют в расшифровках голосовых сообщений
+var separators = regexp.MustCompile(`(?i)[,;\n]+|\s+и\s+`)
+
+// Parse разбирает список продуктов, набранный пользователем:
+// "яйца, помидоры и сыр" или по одному продукту в строке, в том числе с маркерами списка
+func Parse(text string) []string {
+	return Clean(separators.Split(text, -1), MaxItems)
+}
@@ -0,0 +1,91 @@
+package inventory
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unit - единица измерения количества
+type Unit struct {
+	Name string
+	// dimension - величина: масса, объем или штуки; сравниваются только количества одной величины
+	dimension string
+	// factor - множитель для перевода в базовую единицу величины: граммы, миллилитры, штуки
+	factor float64
+}
+
+var units = map[string]Unit{
+	"шт":    {"шт", "count", 1},
+	"штук":  {"шт", "count", 1},
+	"штуки": {"шт", "count", 1},
+	"штука": {"шт", "count", 1},
+	"pcs":   {"шт", "count", 1},
+	"г":     {"г", "mass", 1},
+	"гр":    {"г", "mass", 1},
+	"грамм": {"г", "mass", 1},
+	"g":     {"г", "mass", 1},
+	"кг":    {"кг", "mass", 1000},
+	"kg":    {"кг", "mass", 1000},
+	"мл":    {"мл", "volume", 1},
+	"ml":    {"мл", "volume", 1},
+	"л":     {"л", "volume", 1000},
+	"литр":  {"л", "volume", 1000},
+	"l":     {"л", "volume", 1000},
+}
+
+// Amount - количество, которое удалось разобрать: "6 шт", "0,5 л"
+type Amount struct {
+	Value float64
+	Unit  Unit
+}
+
+func (a Amount) String() string {
+	return strings.TrimSpace(FormatNumber(a.Value) + " " + a.Unit.Name)
+}
+
+// Add складывает количества одной величины, результат - в единицах a: "1 л" + "500 мл" = "1,5 л".
+// ok = false - величины разные ("2 шт" и "200 г") и количества не сложить.
+func (a Amount) Add(b Amount) (Amount, bool) {
+	if a.Unit.dimension != b.Unit.dimension {
+		return Amount{}, false
+	}
+	return Amount{Value: a.Value + b.Value*b.Unit.factor/a.Unit.factor, Unit: a.Unit}, true
+}
+
+// Sub вычитает из a количество b той же величины, результат - в единицах a и может быть отрицательным
+func (a Amount) Sub(b Amount) (Amount, bool) {
+	return a.Add(Amount{Value: -b.Value, Unit: b.Unit})
+}
+
+// amountPattern - число и единица, возможно с пояснением "около" или "~": "около 500 г", "~2 шт", "1,5л"
+var amountPattern = regexp.MustCompile(`^(?:около|примерно|~)?\s*(\d+(?:[.,]\d+)?)\s*([\p{L}]*)\.?$`)
+
+// ParseAmount разбирает количество продукта. Число без единицы считается числом штук.
+func ParseAmount(text string) (Amount, bool) {
+	m := amountPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(text)))
+	if m == nil {
+		return Amount{}, false
+	}
+
+	value, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+	if err != nil {
+		return Amount{}, false
+	}
+
+	unit := units["шт"]
+	if m[2] != "" {
+		var ok bool
+		if unit, ok = units[m[2]]; !ok {
+			return Amount{}, false
+		}
+	}
+	return Amount{Value: value, Unit: unit}, true
+}
+
+// FormatNumber выводит число с точностью до сотых, без лишних нулей и с десятичной запятой
+func FormatNumber(v float64) string {
+	v = math.Round(v*100) / 100
+	return strings.Replace(strconv.FormatFloat(v, 'f', -1, 64), ".", ",", 1)
+}
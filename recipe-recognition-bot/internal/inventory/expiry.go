@@ -0,0 +1,140 @@
+package inventory
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shelfLife - типичный срок хранения открытого или свежего продукта в холодильнике.
+// Ключи - начала слов названия, как в словаре аллергенов: "творог" подходит и к "творожный";
+// ключи из нескольких слов ищутся в названии целиком. Категории проверяются от скоропортящихся:
+// "зеленый лук" относится к зелени, а не к луку.
+var shelfLife = []struct {
+	prefixes []string
+	days     int
+}{
+	{[]string{"фарш", "куриц", "курин", "индейк", "рыб", "лосос", "семг", "форел", "кревет", "кальмар", "мидии"}, 2},
+	{[]string{"мяс", "говя", "свин", "баран", "печень", "печенк"}, 3},
+	{[]string{"ягод", "клубник", "малин", "черник", "салат", "шпинат", "руккол"}, 3},
+	{[]string{"творог", "творож", "сливк", "молок", "кефир", "ряженк", "гриб", "шампиньон", "зелен", "укроп", "петрушк", "кинз", "базилик", "хлеб", "батон", "лаваш"}, 4},
+	{[]string{"банан", "авокадо", "персик", "груш"}, 5},
+	{[]string{"сметан", "йогурт", "помидор", "томат", "огурц", "огурец", "колбас", "сосиск", "ветчин", "бекон"}, 7},
+	{[]string{"перец болгарск", "болгарский перец", "перец сладк", "сладкий перец", "кабачк", "баклажан", "брокколи", "цветная"}, 10},
+	{[]string{"сыр"}, 14},
+	{[]string{"морков", "свекл", "капуст", "яблок", "апельсин", "мандарин", "лимон", "грейпфрут"}, 21},
+	{[]string{"яйц", "яиц"}, 25},
+	{[]string{"масло сливочн", "сливочное масло", "картоф", "картошк", "лук"}, 30},
+	{[]string{"чеснок", "имбир", "тыкв"}, 60},
+}
+
+// EstimateShelfLife оценивает срок хранения продукта по его категории.
+// ok = false - продукт хранится долго (крупы, консервы) или категория неизвестна.
+func EstimateShelfLife(name string) (time.Duration, bool) {
+	name = strings.ReplaceAll(strings.ToLower(name), "ё", "е")
+	words := strings.Fields(name)
+
+	for _, category := range shelfLife {
+		for _, prefix := range category.prefixes {
+			if strings.Contains(prefix, " ") {
+				if strings.Contains(name, prefix) {
+					return time.Duration(category.days) * 24 * time.Hour, true
+				}
+				continue
+			}
+			for _, word := range words {
+				if strings.HasPrefix(word, prefix) {
+					return time.Duration(category.days) * 24 * time.Hour, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// WithEstimatedExpiry проставляет оценку срока годности продуктам, у которых он не указан
+func WithEstimatedExpiry(items []Item, now time.Time) []Item {
+	for i := range items {
+		if !items[i].ExpiresAt.IsZero() {
+			continue
+		}
+		if shelfLife, ok := EstimateShelfLife(items[i].Name); ok {
+			items[i].ExpiresAt = now.Add(shelfLife)
+			items[i].ExpiryEstimated = true
+		}
+	}
+	return items
+}
+
+// ExpiryHorizon - конец дня через days дней после now: продукты со сроком до этого момента
+// считаются истекающими. Сравнение по концу дня включает и сроки, введенные датой без времени.
+func ExpiryHorizon(now time.Time, days int) time.Time {
+	year, month, day := now.Date()
+	return time.Date(year, month, day+days+1, 0, 0, 0, 0, now.Location())
+}
+
+// Expiring возвращает продукты, срок годности которых истекает до until, в том числе уже истекший
+func Expiring(items []Item, until time.Time) []Item {
+	var expiring []Item
+	for _, item := range items {
+		if !item.ExpiresAt.IsZero() && item.ExpiresAt.Before(until) {
+			expiring = append(expiring, item)
+		}
+	}
+	return expiring
+}
+
+// ErrInvalidExpiry - срок годности не удалось разобрать
+var ErrInvalidExpiry = errors.New("invalid expiry date")
+
+var (
+	// expiryDays - срок в днях: "3", "3 дня", "5 дней"
+	expiryDays = regexp.MustCompile(`^(\d{1,3})(?:\s*(?:д|дн|день|дня|дней))?$`)
+	// expiryDate - дата: "25.10", "25.10.2026", "25.10.26"
+	expiryDate = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})(?:\.(\d{2}|\d{4}))?$`)
+)
+
+// ParseExpiry разбирает срок годности, введенный пользователем: дату или число дней от now.
+// Дата без года, уже прошедшая больше месяца назад, относится к следующему году.
+// Срок истекает в конце указанного дня.
+func ParseExpiry(text string, now time.Time) (time.Time, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	endOfDay := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day+1, 0, 0, 0, 0, now.Location()).Add(-time.Second)
+	}
+
+	if m := expiryDays.FindStringSubmatch(text); m != nil {
+		days, _ := strconv.Atoi(m[1])
+		return endOfDay(now.Year(), now.Month(), now.Day()+days), nil
+	}
+
+	m := expiryDate.FindStringSubmatch(text)
+	if m == nil {
+		return time.Time{}, ErrInvalidExpiry
+	}
+	day, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, ErrInvalidExpiry
+	}
+
+	year := now.Year()
+	if m[3] != "" {
+		year, _ = strconv.Atoi(m[3])
+		if year < 100 {
+			year += 2000
+		}
+	}
+
+	expires := endOfDay(year, time.Month(month), day)
+	// time.Date нормализует 31.02 в март: такой даты нет
+	if expires.Day() != day {
+		return time.Time{}, ErrInvalidExpiry
+	}
+	if m[3] == "" && expires.Before(now.AddDate(0, -1, 0)) {
+		expires = endOfDay(year+1, time.Month(month), day)
+	}
+	return expires, nil
+}
@@ -0,0 +1,87 @@
+package inventory
+
+import (
+	"time"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+)
+
+// Item - продукт, который есть у пользователя дома
+type Item struct {
+	ID   int64
+	Name string
+	// Quantity - примерное количество, оцененное по фото: "6 шт", "около 500 г"
+	Quantity string
+	AddedAt  time.Time
+	// ExpiresAt - срок годности; нулевое значение - срок неизвестен
+	ExpiresAt time.Time
+	// ExpiryEstimated - срок оценен по категории продукта, а не указан пользователем
+	ExpiryEstimated bool
+}
+
+// Usage - изменение продукта после приготовления рецепта
+type Usage struct {
+	Item Item
+	// Remaining - оставшееся количество; пустая строка - продукт израсходован
+	Remaining string
+}
+
+// Used сообщает, что продукт израсходован целиком
+func (u Usage) Used() bool {
+	return u.Remaining == ""
+}
+
+// Consume сопоставляет ингредиенты рецепта с продуктами пользователя и вычисляет, что от них останется.
+// Если количества в продукте и в рецепте удается сравнить, из продукта вычитается количество по рецепту;
+// иначе продукт считается израсходованным. Ингредиенты "по вкусу" и из запасов (соль, масло) не учитываются.
+func Consume(items []Item, used []recipes.Ingredient) []Usage {
+	byKey := make(map[string]Item, len(items))
+	for _, item := range items {
+		byKey[ingredients.Key(item.Name)] = item
+	}
+
+	var usages []Usage
+	for _, ingredient := range used {
+		if ingredient.Pantry {
+			continue
+		}
+		key := ingredients.Key(ingredient.Name)
+		item, ok := byKey[key]
+		if !ok {
+			continue
+		}
+		// Один продукт может встретиться в рецепте дважды, но списывается один раз
+		delete(byKey, key)
+
+		remaining, ok := subtract(item.Quantity, ingredient)
+		if !ok {
+			continue
+		}
+		usages = append(usages, Usage{Item: item, Remaining: remaining})
+	}
+	return usages
+}
+
+// subtract вычитает количество ингредиента из количества продукта.
+// ok = false - ингредиент добавляется по вкусу и продукт не расходуется.
+func subtract(available string, ingredient recipes.Ingredient) (remaining string, ok bool) {
+	if ingredient.Quantity <= 0 {
+		return available, false
+	}
+
+	have, ok := ParseAmount(available)
+	if !ok {
+		return "", true
+	}
+	need, ok := ParseAmount(ingredient.Amount())
+	if !ok {
+		return "", true
+	}
+
+	left, ok := have.Sub(need)
+	if !ok || left.Value <= 0 {
+		return "", true
+	}
+	return left.String(), true
+}
@@ -0,0 +1,137 @@
+package llmjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Normalizer вызывается после разбора, перед проверкой
+type Normalizer interface {
+	Normalize()
+}
+
+// Validator выполняет дополнительную проверку, которую нельзя выразить тегами
+type Validator interface {
+	Validate() error
+}
+
+// Decode извлекает JSON из ответа модели, при необходимости исправляет его,
+// разбирает в v и проверяет по схеме Go-структуры.
+//
+// Поля с тегом `llm:"required"` обязательны: строка не должна быть пустой,
+// срез - пустым, число - нулевым. Возвращаемые ошибки: ErrNoJSON, *SyntaxError, *ValidationError.
+func Decode(content string, v any) error {
+	fragment, err := Extract(content)
+	if err != nil {
+		return err
+	}
+
+	if !json.Valid([]byte(fragment)) {
+		fragment = Repair(fragment)
+	}
+
+	if err := json.Unmarshal([]byte(fragment), v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return &ValidationError{Problems: []Problem{{
+				Path:   typeErr.Field,
+				Reason: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value),
+			}}}
+		}
+		return &SyntaxError{Fragment: fragment, Err: err}
+	}
+
+	if n, ok := v.(Normalizer); ok {
+		n.Normalize()
+	}
+
+	problems := checkRequired(reflect.ValueOf(v), "")
+	if validator, ok := v.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			problems = append(problems, Problem{Reason: err.Error()})
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// checkRequired рекурсивно проверяет поля с тегом `llm:"required"`
+func checkRequired(v reflect.Value, path string) []Problem {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var problems []Problem
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, ok := jsonName(field)
+			if !ok {
+				continue
+			}
+			fieldPath := joinPath(path, name)
+			value := v.Field(i)
+
+			if hasOption(field.Tag.Get("llm"), "required") && isEmpty(value) {
+				problems = append(problems, Problem{Path: fieldPath, Reason: "required"})
+				continue
+			}
+			problems = append(problems, checkRequired(value, fieldPath)...)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			problems = append(problems, checkRequired(v.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return problems
+}
+
+// isEmpty считает пустыми нулевые значения и срезы без элементов
+func isEmpty(v reflect.Value) bool {
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Map {
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// jsonName возвращает имя поля в JSON; false для неэкспортируемых и пропускаемых полей
+func jsonName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func hasOption(tag, option string) bool {
+	for _, part := range strings.Split(tag, ",") {
+		if strings.TrimSpace(part) == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,318 @@
+package llmjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "чистый объект",
+			content: `{"items": ["молоко"]}`,
+			want:    `{"items": ["молоко"]}`,
+		},
+		{
+			name:    "блок кода json",
+			content: "Вот ответ:\n```json\n{\"items\": [\"сыр\"]}\n```\nГотово.",
+			want:    `{"items": ["сыр"]}`,
+		},
+		{
+			name:    "блок кода без языка",
+			content: "```\n[\"яйца\", \"мука\"]\n```",
+			want:    `["яйца", "мука"]`,
+		},
+		{
+			name:    "блок кода без JSON, JSON в тексте",
+			content: "```\nнет данных\n```\nИтог: {\"items\": []}",
+			want:    `{"items": []}`,
+		},
+		{
+			name:    "текст вокруг JSON",
+			content: `На фото я вижу: {"items": ["хлеб"]} Надеюсь, это поможет!`,
+			want:    `{"items": ["хлеб"]}`,
+		},
+		{
+			name:    "скобки внутри строк",
+			content: `{"name": "соус {острый}", "note": "]"} и ещё {"x": 1}`,
+			want:    `{"name": "соус {острый}", "note": "]"}`,
+		},
+		{
+			name:    "оборванный ответ",
+			content: `Ответ: {"items": ["молоко", "сы`,
+			want:    `{"items": ["молоко", "сы`,
+		},
+		{
+			name:    "оборванный блок кода",
+			content: "```json\n{\"items\": [\"молоко\"",
+			want:    `{"items": ["молоко"`,
+		},
+		{
+			name:    "нет JSON",
+			content: "На изображении нет продуктов.",
+			wantErr: ErrNoJSON,
+		},
+		{
+			name:    "пустой ответ",
+			content: "",
+			wantErr: ErrNoJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Extract(tt.content)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Extract() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Extract() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		want     string
+	}{
+		{
+			name:     "корректный JSON не меняется",
+			fragment: `{"a": [1, 2], "b": "c"}`,
+			want:     `{"a": [1, 2], "b": "c"}`,
+		},
+		{
+			name:     "висячая запятая в массиве",
+			fragment: `["молоко", "сыр",]`,
+			want:     `["молоко", "сыр"]`,
+		},
+		{
+			name:     "висячая запятая в объекте",
+			fragment: "{\"a\": 1,\n}",
+			want:     `{"a": 1}`,
+		},
+		{
+			name:     "одинарные кавычки",
+			fragment: `{'items': ['молоко', 'сыр']}`,
+			want:     `{"items": ["молоко", "сыр"]}`,
+		},
+		{
+			name:     "двойная кавычка внутри одинарных",
+			fragment: `{'name': 'сыр "Российский"'}`,
+			want:     `{"name": "сыр \"Российский\""}`,
+		},
+		{
+			name:     "экранированный апостроф",
+			fragment: `{'name': 'O\'Neil'}`,
+			want:     `{"name": "O'Neil"}`,
+		},
+		{
+			name:     "типографские кавычки",
+			fragment: `{“name”: “сыр”}`,
+			want:     `{"name": "сыр"}`,
+		},
+		{
+			name:     "литералы Python",
+			fragment: `{"a": True, "b": False, "c": None, "d": "True"}`,
+			want:     `{"a": true, "b": false, "c": null, "d": "True"}`,
+		},
+		{
+			name:     "перевод строки внутри строки",
+			fragment: "{\"a\": \"раз\nдва\"}",
+			want:     `{"a": "раз\nдва"}`,
+		},
+		{
+			name:     "оборванный массив",
+			fragment: `["молоко", "сыр"`,
+			want:     `["молоко", "сыр"]`,
+		},
+		{
+			name:     "оборванный массив после запятой",
+			fragment: `["молоко", "сыр",`,
+			want:     `["молоко", "сыр"]`,
+		},
+		{
+			name:     "оборванная строка",
+			fragment: `{"items": ["молоко", "сы`,
+			want:     `{"items": ["молоко", "сы"]}`,
+		},
+		{
+			name:     "оборванный объект после двоеточия",
+			fragment: `{"a": 1, "b":`,
+			want:     `{"a": 1}`,
+		},
+		{
+			name:     "оборванный объект после ключа",
+			fragment: `{"a": 1, "b"`,
+			want:     `{"a": 1}`,
+		},
+		{
+			name:     "вложенные оборванные объекты",
+			fragment: `{"items": [{"name": "сыр", "confidence": 0.9}, {"name": "мол`,
+			want:     `{"items": [{"name": "сыр", "confidence": 0.9}, {"name": "мол"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Repair(tt.fragment)
+			if !json.Valid([]byte(got)) {
+				t.Fatalf("Repair() = %q, not valid JSON", got)
+			}
+			assertSameJSON(t, got, tt.want)
+		})
+	}
+}
+
+// assertSameJSON сравнивает JSON без учета пробелов
+func assertSameJSON(t *testing.T, got, want string) {
+	t.Helper()
+	var gotValue, wantValue any
+	if err := json.Unmarshal([]byte(got), &gotValue); err != nil {
+		t.Fatalf("unmarshal %q: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("unmarshal %q: %v", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+type testIngredient struct {
+	Name   string `json:"name" llm:"required"`
+	Amount string `json:"amount"`
+}
+
+type testRecipe struct {
+	Title       string           `json:"title" llm:"required"`
+	Servings    int              `json:"servings"`
+	Ingredients []testIngredient `json:"ingredients" llm:"required"`
+	Skipped     string           `json:"-" llm:"required"`
+}
+
+func (r *testRecipe) Normalize() {
+	if r.Servings == 0 {
+		r.Servings = 1
+	}
+}
+
+func (r *testRecipe) Validate() error {
+	if r.Servings > 100 {
+		return errors.New("too many servings")
+	}
+	return nil
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		want     testRecipe
+		wantErr  error
+		problems []string
+	}{
+		{
+			name:    "блок кода с висячей запятой",
+			content: "```json\n{\"title\": \"Омлет\", \"servings\": 2, \"ingredients\": [{\"name\": \"яйца\", \"amount\": \"3 шт\"},]}\n```",
+			want:    testRecipe{Title: "Омлет", Servings: 2, Ingredients: []testIngredient{{Name: "яйца", Amount: "3 шт"}}},
+		},
+		{
+			name:    "одинарные кавычки и текст вокруг",
+			content: `Конечно! {'title': 'Салат', 'ingredients': [{'name': 'огурец'}]} Приятного аппетита.`,
+			want:    testRecipe{Title: "Салат", Servings: 1, Ingredients: []testIngredient{{Name: "огурец"}}},
+		},
+		{
+			name:    "оборванный ответ",
+			content: `{"title": "Каша", "ingredients": [{"name": "овсянка"}, {"name": "моло`,
+			want:    testRecipe{Title: "Каша", Servings: 1, Ingredients: []testIngredient{{Name: "овсянка"}, {Name: "моло"}}},
+		},
+		{
+			name:    "нет JSON",
+			content: "Извините, я не могу помочь.",
+			wantErr: ErrNoJSON,
+		},
+		{
+			name:     "нет обязательного поля",
+			content:  `{"ingredients": [{"name": "соль"}]}`,
+			wantErr:  &ValidationError{},
+			problems: []string{"title: required"},
+		},
+		{
+			name:     "пустой обязательный срез и вложенное поле",
+			content:  `{"title": "Суп", "ingredients": []}`,
+			wantErr:  &ValidationError{},
+			problems: []string{"ingredients: required"},
+		},
+		{
+			name:     "обязательное поле в элементе массива",
+			content:  `{"title": "Суп", "ingredients": [{"name": "вода"}, {"amount": "1 л"}]}`,
+			wantErr:  &ValidationError{},
+			problems: []string{"ingredients[1].name: required"},
+		},
+		{
+			name:     "неверный тип поля",
+			content:  `{"title": "Суп", "servings": "два", "ingredients": [{"name": "вода"}]}`,
+			wantErr:  &ValidationError{},
+			problems: []string{"servings: expected int, got string"},
+		},
+		{
+			name:     "ошибка Validate",
+			content:  `{"title": "Пир", "servings": 500, "ingredients": [{"name": "хлеб"}]}`,
+			wantErr:  &ValidationError{},
+			problems: []string{"too many servings"},
+		},
+		{
+			name:    "неисправимый JSON",
+			content: `{"title": "Суп" "ingredients": []}`,
+			wantErr: &SyntaxError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testRecipe
+			err := Decode(tt.content, &got)
+
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Decode() = %+v, want %+v", got, tt.want)
+				}
+			case *ValidationError:
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("Decode() error = %v, want *ValidationError", err)
+				}
+				var problems []string
+				for _, problem := range validationErr.Problems {
+					problems = append(problems, problem.String())
+				}
+				if !reflect.DeepEqual(problems, tt.problems) {
+					t.Errorf("problems = %q, want %q", problems, tt.problems)
+				}
+			case *SyntaxError:
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Fatalf("Decode() error = %v, want *SyntaxError", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("Decode() error = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package llmjson
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoJSON - в ответе модели не найдено ни объекта, ни массива JSON
+var ErrNoJSON = errors.New("llmjson: no JSON found in response")
+
+// SyntaxError - найденный фрагмент не удалось разобрать даже после исправлений
+type SyntaxError struct {
+	// Fragment - JSON после попытки исправления
+	Fragment string
+	Err      error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("llmjson: invalid JSON: %v", e.Err)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Problem - одно нарушение схемы
+type Problem struct {
+	// Path - путь к полю в нотации JSON, например "ingredients[2].name"
+	Path   string
+	Reason string
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Reason
+	}
+	return p.Path + ": " + p.Reason
+}
+
+// ValidationError - JSON корректен синтаксически, но не соответствует схеме
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		parts = append(parts, problem.String())
+	}
+	return "llmjson: schema violation: " + strings.Join(parts, "; ")
+}
@@ -0,0 +1,68 @@
+package llmjson
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fencedBlock находит блоки кода Markdown: ```json ... ``` или ``` ... ```
+var fencedBlock = regexp.MustCompile("(?s)```[a-zA-Z]*\\s*\\n?(.*?)(?:```|$)")
+
+// Extract находит JSON в ответе модели. Сначала просматриваются блоки кода Markdown,
+// затем весь текст. Возвращается первый объект или массив; если он оборван,
+// возвращается остаток текста, который затем может исправить Repair.
+func Extract(content string) (string, error) {
+	for _, match := range fencedBlock.FindAllStringSubmatch(content, -1) {
+		if fragment, ok := scanValue(match[1]); ok {
+			return fragment, nil
+		}
+	}
+
+	if fragment, ok := scanValue(content); ok {
+		return fragment, nil
+	}
+	return "", ErrNoJSON
+}
+
+// scanValue выделяет первый сбалансированный объект или массив, учитывая строки
+func scanValue(text string) (string, bool) {
+	start := strings.IndexAny(text, "{[")
+	if start == -1 {
+		return "", false
+	}
+
+	depth := 0
+	var quote byte
+	escaped := false
+
+	for i := start; i < len(text); i++ {
+		c := text[i]
+
+		if quote != 0 {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == quote:
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return text[start : i+1], true
+			}
+		}
+	}
+
+	// Ответ оборвался: отдаем остаток целиком
+	return strings.TrimSpace(text[start:]), true
+}
@@ -0,0 +1,204 @@
+package llmjson
+
+import (
+	"strings"
+)
+
+var smartQuotes = strings.NewReplacer(
+	"“", `"`, "”", `"`, "„", `"`,
+	"‘", "'", "’", "'",
+)
+
+// Repair исправляет типичные дефекты JSON в ответах моделей:
+// типографские и одинарные кавычки, висячие запятые, литералы Python
+// (True/False/None) и оборванный конец ответа (незакрытые строки и скобки).
+func Repair(fragment string) string {
+	fragment = smartQuotes.Replace(strings.TrimSpace(fragment))
+
+	var out strings.Builder
+	out.Grow(len(fragment) + 8)
+
+	var stack []byte
+	inString := false
+	singleQuoted := false
+	escaped := false
+
+	for i := 0; i < len(fragment); i++ {
+		c := fragment[i]
+
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+				// \' допустимо в одинарных кавычках, но не в JSON
+				if c == '\'' {
+					trimLast(&out)
+				}
+				out.WriteByte(c)
+			case c == '\\':
+				escaped = true
+				out.WriteByte(c)
+			case singleQuoted && c == '\'':
+				inString = false
+				out.WriteByte('"')
+			case singleQuoted && c == '"':
+				out.WriteString(`\"`)
+			case !singleQuoted && c == '"':
+				inString = false
+				out.WriteByte(c)
+			case c == '\n':
+				out.WriteString(`\n`)
+			default:
+				out.WriteByte(c)
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString, singleQuoted = true, false
+			out.WriteByte(c)
+		case '\'':
+			inString, singleQuoted = true, true
+			out.WriteByte('"')
+		case '{':
+			stack = append(stack, '}')
+			out.WriteByte(c)
+		case '[':
+			stack = append(stack, ']')
+			out.WriteByte(c)
+		case '}', ']':
+			dropTrailingComma(&out)
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			out.WriteByte(c)
+		default:
+			if word, ok := pythonLiteral(fragment[i:]); ok {
+				out.WriteString(word.json)
+				i += len(word.python) - 1
+				continue
+			}
+			out.WriteByte(c)
+		}
+	}
+
+	// Ответ оборван внутри строки
+	if inString {
+		if escaped {
+			trimLast(&out)
+		}
+		out.WriteByte('"')
+	}
+
+	if len(stack) == 0 {
+		return out.String()
+	}
+
+	result := dropDanglingTail(out.String())
+	for i := len(stack) - 1; i >= 0; i-- {
+		result += string(stack[i])
+	}
+	return result
+}
+
+type literal struct {
+	python, json string
+}
+
+var pythonLiterals = []literal{{"True", "true"}, {"False", "false"}, {"None", "null"}}
+
+// pythonLiteral распознает True/False/None как отдельное слово
+func pythonLiteral(rest string) (literal, bool) {
+	for _, l := range pythonLiterals {
+		if strings.HasPrefix(rest, l.python) {
+			if len(rest) == len(l.python) || !isWordByte(rest[len(l.python)]) {
+				return l, true
+			}
+		}
+	}
+	return literal{}, false
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// dropTrailingComma удаляет запятую перед закрывающей скобкой
+func dropTrailingComma(out *strings.Builder) {
+	s := strings.TrimRight(out.String(), " \t\r\n")
+	if strings.HasSuffix(s, ",") {
+		out.Reset()
+		out.WriteString(s[:len(s)-1])
+	}
+}
+
+func trimLast(out *strings.Builder) {
+	s := out.String()
+	out.Reset()
+	out.WriteString(s[:len(s)-1])
+}
+
+// dropDanglingTail убирает незавершенный хвост оборванного ответа:
+// висячую запятую, ключ без значения или двоеточие
+func dropDanglingTail(s string) string {
+	for {
+		trimmed := strings.TrimRight(s, " \t\r\n")
+		switch {
+		case strings.HasSuffix(trimmed, ","):
+			s = trimmed[:len(trimmed)-1]
+		case strings.HasSuffix(trimmed, ":"):
+			// Удаляем ключ вместе с двоеточием
+			s = trimmed[:len(trimmed)-1]
+			s = strings.TrimRight(s, " \t\r\n")
+			if strings.HasSuffix(s, `"`) {
+				if start := strings.LastIndex(s[:len(s)-1], `"`); start != -1 {
+					s = s[:start]
+				}
+			}
+		case isDanglingKey(trimmed):
+			s = trimmed[:strings.LastIndex(trimmed[:len(trimmed)-1], `"`)]
+		default:
+			return trimmed
+		}
+	}
+}
+
+// isDanglingKey проверяет, что объект оборван сразу после ключа: {"a": 1, "b"
+func isDanglingKey(s string) bool {
+	if !strings.HasSuffix(s, `"`) || len(s) < 2 {
+		return false
+	}
+	start := strings.LastIndex(s[:len(s)-1], `"`)
+	if start <= 0 {
+		return false
+	}
+	before := strings.TrimRight(s[:start], " \t\r\n")
+	return strings.HasSuffix(before, "{") || strings.HasSuffix(before, ",") && enclosingIsObject(before)
+}
+
+// enclosingIsObject определяет, что ближайшая незакрытая скобка - фигурная
+func enclosingIsObject(s string) bool {
+	depth := 0
+	inString := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == '"' && (i == 0 || s[i-1] != '\\') {
+			inString = !inString
+			continue
+		}
+		if inString {
+			continue
+		}
+		switch c {
+		case '}', ']':
+			depth++
+		case '{', '[':
+			if depth == 0 {
+				return c == '{'
+			}
+			depth--
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package llmjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+// Schema - JSON-схема ответа, построенная по Go-структуре
+type Schema struct {
+	Name       string
+	Definition *jsonschema.Definition
+}
+
+// SchemaFor строит схему по типу значения v. Учитываются теги json (имена, "-"),
+// `llm:"required"` (обязательные поля), `description` и `enum` (значения через "|").
+func SchemaFor(name string, v any) (*Schema, error) {
+	definition, err := reflectDefinition(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{Name: name, Definition: definition}, nil
+}
+
+// MustSchemaFor - как SchemaFor, но паникует при ошибке. Для инициализации пакетных переменных.
+func MustSchemaFor(name string, v any) *Schema {
+	schema, err := SchemaFor(name, v)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// ResponseFormat возвращает параметр response_format для OpenAI-совместимых API
+func (s *Schema) ResponseFormat() *openai.ChatCompletionResponseFormat {
+	return &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+			Name:   s.Name,
+			Schema: s.Definition,
+		},
+	}
+}
+
+// JSON возвращает схему в виде JSON, например для поля format в Ollama
+func (s *Schema) JSON() json.RawMessage {
+	data, _ := json.Marshal(s.Definition)
+	return data
+}
+
+func reflectDefinition(t reflect.Type) (*jsonschema.Definition, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &jsonschema.Definition{Type: jsonschema.String}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonschema.Definition{Type: jsonschema.Integer}, nil
+	case reflect.Float32, reflect.Float64:
+		return &jsonschema.Definition{Type: jsonschema.Number}, nil
+	case reflect.Bool:
+		return &jsonschema.Definition{Type: jsonschema.Boolean}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := reflectDefinition(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &jsonschema.Definition{Type: jsonschema.Array, Items: items}, nil
+	case reflect.Struct:
+		return reflectObject(t)
+	default:
+		return nil, fmt.Errorf("llmjson: unsupported type %s", t)
+	}
+}
+
+func reflectObject(t reflect.Type) (*jsonschema.Definition, error) {
+	definition := &jsonschema.Definition{
+		Type:                 jsonschema.Object,
+		Properties:           make(map[string]jsonschema.Definition),
+		AdditionalProperties: false,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+
+		property, err := reflectDefinition(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+		property.Description = field.Tag.Get("description")
+		if enum := field.Tag.Get("enum"); enum != "" {
+			property.Enum = strings.Split(enum, "|")
+		}
+
+		definition.Properties[name] = *property
+		if hasOption(field.Tag.Get("llm"), "required") {
+			definition.Required = append(definition.Required, name)
+		}
+	}
+	return definition, nil
+}
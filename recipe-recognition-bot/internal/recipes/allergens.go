@@ -0,0 +1,202 @@
+package recipes
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// keywordGroup - запрещенные продукты одного вида. keywords - начала слов, подобранные так,
+// чтобы совпадать с формами слова ("орех", "орехи", "ореховый"), но не с похожими продуктами
+// ("кур" совпало бы с курагой, поэтому "куриц" и "курин"). Короткие слова, начало которых
+// встречается в других названиях ("ром" и "ромашка"), перечислены в words и сравниваются целиком.
+type keywordGroup struct {
+	keywords []string
+	words    []string
+	// exceptions - слова, которые снимают запрет с соседнего слова: "кокосовое молоко", "икра из кабачков".
+	// Исключение действует только на свою группу: "курица в кокосовом соусе" остается мясом.
+	exceptions []string
+}
+
+// forbiddenKeywords - группы продуктов, запрещенные диетой или аллергией
+type forbiddenKeywords []keywordGroup
+
+var (
+	meatKeywords = []string{
+		"мяс", "говя", "свин", "бекон", "ветчин", "колбас", "сосиск", "сардельк", "фарш",
+		"куриц", "курин", "индейк", "индюш", "утк", "утин", "гусь", "гуся", "баранин", "бараний", "ягнят",
+		"телят", "кролик", "печенк", "печеноч", "грудинк", "окорок", "хамон", "салями", "пепперони", "желатин",
+		"meat", "beef", "pork", "bacon", "sausage", "chicken", "turkey", "duck", "lamb", "gelatin",
+	}
+	meatWords    = []string{"сало", "печень", "ham"}
+	fishKeywords = []string{
+		"рыб", "лосос", "семг", "форел", "тунец", "тунц", "треск", "сельд", "селедк", "скумбри",
+		"шпрот", "анчоус", "горбуш", "минта", "судак", "икр",
+		"fish", "salmon", "trout", "tuna", "herring", "mackerel", "anchov",
+	}
+	fishWords = []string{"кета", "кеты", "cod"}
+	// vegetableExceptions - овощная и грибная "икра"
+	vegetableExceptions = []string{"кабачк", "баклажан", "овощн", "грибн"}
+	shellfishKeywords   = []string{
+		"кревет", "краб", "кальмар", "мидии", "мидий", "устриц", "гребеш", "омар", "лобстер", "морепродукт", "осьминог",
+		"shrimp", "prawn", "crab", "squid", "mussel", "oyster", "scallop", "lobster", "octopus",
+	}
+	milkKeywords = []string{
+		"молок", "молоч", "сливк", "сливоч", "сметан", "творог", "творож", "сырн", "кефир", "йогурт",
+		"ряженк", "простокваш", "брынз", "моцарел", "пармезан", "маскарпоне", "рикотт", "сгущ",
+		"milk", "cream", "butter", "cheese", "yogurt", "kefir",
+	}
+	// milkWords - формы слова "сыр": по началу "сыр" совпали бы и "сырой", "сырые"
+	milkWords = []string{
+		"сыр", "сыра", "сыру", "сыром", "сыре", "сыры", "сыров", "сырам", "сырами", "сырах",
+		"сырок", "сырка", "сырки", "сырков",
+	}
+	plantMilkExceptions = []string{
+		"кокос", "миндал", "соев", "овсян", "рисов", "арахисов", "орехов",
+		"coconut", "almond", "soy", "oat", "peanut",
+	}
+	eggKeywords = []string{"яйц", "яиц", "яичн", "желток", "желтк", "белков", "майонез", "egg", "mayo"}
+	eggWords    = []string{"белок", "белки", "белка"}
+	nutKeywords = []string{
+		"орех", "миндал", "фундук", "кешью", "фисташ", "пекан", "макадами", "пралине", "нутелл",
+		"nut", "almond", "hazelnut", "cashew", "pistachio", "pecan", "walnut",
+	}
+)
+
+var (
+	meatGroup      = keywordGroup{keywords: meatKeywords, words: meatWords}
+	fishGroup      = keywordGroup{keywords: fishKeywords, words: fishWords, exceptions: vegetableExceptions}
+	shellfishGroup = keywordGroup{keywords: shellfishKeywords}
+	milkGroup      = keywordGroup{keywords: milkKeywords, words: milkWords, exceptions: plantMilkExceptions}
+	eggGroup       = keywordGroup{keywords: eggKeywords, words: eggWords}
+	honeyGroup     = keywordGroup{keywords: []string{"honey"}, words: []string{"мед", "меда", "медом"}}
+)
+
+// restrictionKeywords - словарь запретов для каждой диеты и аллергена
+var restrictionKeywords = map[string]forbiddenKeywords{
+	DietVegetarian: {meatGroup, fishGroup, shellfishGroup},
+	DietVegan:      {meatGroup, fishGroup, shellfishGroup, milkGroup, eggGroup, honeyGroup},
+	DietHalal: {{
+		keywords: []string{"свин", "бекон", "ветчин", "хамон", "желатин", "коньяк", "водк", "ликер", "pork", "bacon", "wine", "beer"},
+		words:    []string{"сало", "вино", "вина", "вином", "пиво", "пива", "ром", "рома", "ham", "rum"},
+	}},
+	DietLactoseFree: {milkGroup},
+	DietGlutenFree: {{keywords: []string{
+		"пшени", "мук", "хлеб", "батон", "макарон", "спагетти", "лапш", "манк", "булгур", "кускус", "ячм",
+		"перлов", "ржан", "рожь", "овсян", "сухар", "панировк", "тест", "лаваш", "пельмен", "вермишел",
+		"печенье", "бисквит", "блин", "пирог", "пирож", "соевый соус",
+		"wheat", "flour", "bread", "pasta", "noodle", "barley", "rye", "couscous",
+	}}},
+	AllergenNuts:      {{keywords: nutKeywords, exceptions: []string{"мускат", "nutmeg"}}},
+	AllergenPeanuts:   {{keywords: []string{"арахис", "peanut"}}},
+	AllergenMilk:      {milkGroup},
+	AllergenEggs:      {eggGroup},
+	AllergenFish:      {fishGroup},
+	AllergenShellfish: {shellfishGroup},
+	AllergenSoy:       {{keywords: []string{"соя", "сои", "соев", "тофу", "эдамаме", "мисо", "soy", "tofu", "edamame", "miso"}}},
+	AllergenSesame:    {{keywords: []string{"кунжут", "тахин", "хумус", "sesame", "tahini", "hummus"}}},
+}
+
+// Violation - ингредиент рецепта, запрещенный диетой или аллергией
+type Violation struct {
+	Ingredient  string
+	Restriction string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s (%s)", v.Ingredient, RestrictionName(v.Restriction))
+}
+
+// CheckPreferences ищет в ингредиентах рецепта продукты, запрещенные ограничениями пользователя.
+// Проверка словарная: она ловит явные ошибки модели, но не заменяет чтение состава продуктов.
+func CheckPreferences(recipe *Recipe, prefs Preferences) []Violation {
+	var violations []Violation
+	for _, ingredient := range recipe.Ingredients {
+		words := ingredientWords(ingredient.Name)
+		for _, restriction := range prefs.restrictions() {
+			if forbidden, ok := restrictionKeywords[restriction]; ok && forbidden.matches(words) {
+				violations = append(violations, Violation{Ingredient: ingredient.Name, Restriction: restriction})
+			}
+		}
+	}
+	return violations
+}
+
+// matches проверяет, запрещает ли название хотя бы одна группа
+func (f forbiddenKeywords) matches(words []string) bool {
+	for _, group := range f {
+		if group.matches(words) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches проверяет, есть ли в названии запрещенное слово или слово, начинающееся с ключевого,
+// рядом с которым нет исключения группы. Ключевые слова из нескольких слов ("соевый соус")
+// ищутся в названии целиком.
+func (g keywordGroup) matches(words []string) bool {
+	phrase := strings.Join(words, " ")
+	for _, keyword := range g.keywords {
+		if strings.Contains(keyword, " ") && strings.Contains(phrase, keyword) {
+			return true
+		}
+	}
+	for i, word := range words {
+		if g.forbids(word) && !g.excused(words, i) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g keywordGroup) forbids(word string) bool {
+	if slices.Contains(g.words, word) {
+		return true
+	}
+	for _, keyword := range g.keywords {
+		if !strings.Contains(keyword, " ") && strings.HasPrefix(word, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// excused проверяет, что рядом с i-м словом стоит исключение группы
+func (g keywordGroup) excused(words []string, i int) bool {
+	for _, j := range neighbours(words, i) {
+		for _, exception := range g.exceptions {
+			if strings.HasPrefix(words[j], exception) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// neighbours - индексы слов слева и справа от i-го; предлог "из" пропускается: "икра из кабачков"
+func neighbours(words []string, i int) []int {
+	var result []int
+	if j := i - 1; j >= 0 {
+		if words[j] == "из" && j > 0 {
+			j--
+		}
+		result = append(result, j)
+	}
+	if j := i + 1; j < len(words) {
+		if words[j] == "из" && j+1 < len(words) {
+			j++
+		}
+		result = append(result, j)
+	}
+	return result
+}
+
+// ingredientWords разбивает название ингредиента на слова в нижнем регистре, "е" вместо "ё"
+func ingredientWords(name string) []string {
+	name = strings.ReplaceAll(strings.ToLower(name), "ё", "е")
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-'
+	})
+}
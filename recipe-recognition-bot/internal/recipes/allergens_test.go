@@ -0,0 +1,50 @@
+package recipes
+
+import "testing"
+
+func TestCheckPreferences(t *testing.T) {
+	tests := []struct {
+		ingredient  string
+		restriction string
+		forbidden   bool
+	}{
+		{"курица", DietVegan, true},
+		{"курага", DietVegan, false},
+		{"кокосовое молоко", DietVegan, false},
+		{"молоко овсяное", DietLactoseFree, false},
+		{"миндальное молоко", AllergenMilk, false},
+		{"коровье молоко", AllergenMilk, true},
+		{"сыр твёрдый", AllergenMilk, true},
+		{"сырники", DietVegan, true},
+		{"сырный соус", DietLactoseFree, true},
+		{"сырой картофель", DietVegan, false},
+		{"сырые яйца", DietLactoseFree, false},
+		{"сырая свекла", AllergenMilk, false},
+		// Исключение для молока не снимает запрет с мяса
+		{"курица в кокосовом соусе", DietVegan, true},
+		// Исключение действует только на соседнее слово
+		{"сливочное масло с миндалем", DietVegan, true},
+		{"сливочное масло с миндалем", AllergenNuts, true},
+		{"кабачковая икра", DietVegetarian, false},
+		{"икра из баклажанов", AllergenFish, false},
+		{"икра лососевая", DietVegetarian, true},
+		{"баклажаны с лососем", AllergenFish, true},
+		{"мускатный орех", AllergenNuts, false},
+		{"грецкий орех", AllergenNuts, true},
+		{"соевый соус", DietGlutenFree, true},
+		{"ромашковый чай", DietHalal, false},
+		{"ром", DietHalal, true},
+		{"мед", DietVegan, true},
+		{"яичный белок", AllergenEggs, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ingredient+"/"+tt.restriction, func(t *testing.T) {
+			recipe := &Recipe{Ingredients: []Ingredient{{Name: tt.ingredient}}}
+			prefs := Preferences{Diets: []string{tt.restriction}}
+			if got := len(CheckPreferences(recipe, prefs)) > 0; got != tt.forbidden {
+				t.Errorf("CheckPreferences(%q, %s) forbidden = %v, want %v", tt.ingredient, tt.restriction, got, tt.forbidden)
+			}
+		})
+	}
+}
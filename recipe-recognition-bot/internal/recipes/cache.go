@@ -0,0 +1,187 @@
+package recipes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+)
+
+// CachedRecipe - рецепт в кэше в виде хранимого документа
+type CachedRecipe struct {
+	Document []byte
+	Model    string
+}
+
+// RecipeCache хранит сгенерированные рецепты по ключу набора продуктов
+type RecipeCache interface {
+	// CachedRecipes возвращает неистекшие варианты рецептов для ключа
+	CachedRecipes(ctx context.Context, key string) ([]CachedRecipe, error)
+	// CacheRecipe добавляет вариант и оставляет для ключа не больше maxVariants самых новых
+	CacheRecipe(ctx context.Context, key string, recipe CachedRecipe, ttl time.Duration, maxVariants int) error
+	DeleteExpiredCachedRecipes(ctx context.Context) (int64, error)
+}
+
+// CacheOptions - параметры кэша рецептов
+type CacheOptions struct {
+	TTL time.Duration
+	// MaxVariants - сколько разных рецептов хранить для одного набора продуктов
+	MaxVariants int
+}
+
+// CachingGenerator отдает готовый рецепт для уже встречавшегося набора продуктов.
+// Пока вариантов для набора меньше MaxVariants, блюда из AvoidTitles дополняют кэш новыми вариантами;
+// Request.Force всегда генерирует новый рецепт. Ошибки кэша не мешают генерации.
+type CachingGenerator struct {
+	next   Generator
+	cache  RecipeCache
+	opts   CacheOptions
+	logger *zap.Logger
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewCachingGenerator оборачивает бэкенд генерации кэшем рецептов
+func NewCachingGenerator(next Generator, cache RecipeCache, opts CacheOptions, logger *zap.Logger) *CachingGenerator {
+	if opts.MaxVariants <= 0 {
+		opts.MaxVariants = 1
+	}
+	return &CachingGenerator{next: next, cache: cache, opts: opts, logger: logger}
+}
+
+// Stats возвращает число попаданий и промахов кэша с момента запуска
+func (c *CachingGenerator) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+func (c *CachingGenerator) GenerateRecipe(ctx context.Context, req Request) (*Recipe, error) {
+	key := CacheKey(req)
+
+	if !req.Force {
+		if recipe := c.lookup(ctx, key, req.AvoidTitles); recipe != nil {
+			c.hits.Add(1)
+			c.logStats("Recipe cache hit", key)
+			return recipe, nil
+		}
+	}
+
+	c.misses.Add(1)
+	c.logStats("Recipe cache miss", key)
+
+	recipe, err := c.next.GenerateRecipe(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := EncodeDocument(recipe)
+	if err == nil {
+		err = c.cache.CacheRecipe(ctx, key, CachedRecipe{Document: document, Model: recipe.Model},
+			c.opts.TTL, c.opts.MaxVariants)
+	}
+	if err != nil {
+		c.logger.Warn("Failed to cache recipe", zap.String("key", key), zap.Error(err))
+	}
+	return recipe, nil
+}
+
+// lookup выбирает случайный вариант, которого нет среди уже предложенных блюд
+func (c *CachingGenerator) lookup(ctx context.Context, key string, avoid []string) *Recipe {
+	cached, err := c.cache.CachedRecipes(ctx, key)
+	if err != nil {
+		c.logger.Warn("Recipe cache lookup failed", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+
+	avoided := make(map[string]bool, len(avoid))
+	for _, title := range avoid {
+		avoided[strings.ToLower(title)] = true
+	}
+
+	var candidates []*Recipe
+	for _, entry := range cached {
+		recipe, err := DecodeDocument(entry.Document, SchemaVersion)
+		if err != nil {
+			c.logger.Warn("Skipping broken cached recipe", zap.String("key", key), zap.Error(err))
+			continue
+		}
+		if avoided[strings.ToLower(recipe.Title)] {
+			continue
+		}
+		recipe.Model = entry.Model
+		candidates = append(candidates, recipe)
+	}
+
+	// Пока вариантов мало, просьба о другом блюде пополняет кэш
+	if len(candidates) == 0 || (len(avoid) > 0 && len(cached) < c.opts.MaxVariants) {
+		return nil
+	}
+	return candidates[rand.IntN(len(candidates))]
+}
+
+// Run периодически удаляет истекшие рецепты из кэша до отмены контекста
+func (c *CachingGenerator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := c.cache.DeleteExpiredCachedRecipes(ctx)
+			if err != nil {
+				c.logger.Warn("Failed to delete expired cached recipes", zap.Error(err))
+				continue
+			}
+			if removed > 0 {
+				c.logger.Debug("Expired cached recipes deleted", zap.Int64("count", removed))
+			}
+		}
+	}
+}
+
+func (c *CachingGenerator) logStats(msg, key string) {
+	hits, misses := c.Stats()
+	c.logger.Info(msg,
+		zap.String("key", key[:12]),
+		zap.Int64("hits", hits),
+		zap.Int64("misses", misses))
+}
+
+// CacheKey строит ключ кэша: одинаковый для наборов продуктов, отличающихся порядком,
+// регистром, формой числа и синонимами, и разный для разных версий документа рецепта
+// и разных пользовательских предпочтений
+func CacheKey(req Request) string {
+	seen := make(map[string]bool, len(req.Products))
+	keys := make([]string, 0, len(req.Products))
+	for _, product := range req.Products {
+		key := ingredients.Key(product)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("v%d\n%s\n%s",
+		SchemaVersion, strings.Join(keys, "\n"), req.preferencesFingerprint())))
+	return hex.EncodeToString(sum[:])
+}
+
+// preferencesFingerprint - часть запроса, кроме продуктов, от которой зависит рецепт:
+// ограничения питания, запасы пользователя и продукты, которые нужно использовать в первую очередь
+func (r Request) preferencesFingerprint() string {
+	return r.Preferences.Fingerprint() + "\n" + productsFingerprint("staples", r.Staples) +
+		"\n" + productsFingerprint("priority", r.Priority)
+}
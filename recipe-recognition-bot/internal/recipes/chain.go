@@ -0,0 +1,104 @@
+package recipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ModelSpec описывает одно звено цепочки моделей в формате "провайдер:модель"
+type ModelSpec struct {
+	Provider string
+	Model    string
+}
+
+func (s ModelSpec) String() string {
+	return s.Provider + ":" + s.Model
+}
+
+// ParseModelSpecs разбирает список звеньев вида "openai:deepseek/deepseek-chat:free".
+// Имя модели может само содержать двоеточия, поэтому провайдер отделяется по первому.
+func ParseModelSpecs(values []string) ([]ModelSpec, error) {
+	specs := make([]ModelSpec, 0, len(values))
+	for _, value := range values {
+		provider, model, ok := strings.Cut(strings.TrimSpace(value), ":")
+		if !ok || provider == "" || model == "" {
+			return nil, fmt.Errorf("invalid model spec %q: expected provider:model", value)
+		}
+		specs = append(specs, ModelSpec{Provider: provider, Model: model})
+	}
+	return specs, nil
+}
+
+// chainLink - звено цепочки с именем для логов
+type chainLink struct {
+	name      string
+	generator Generator
+}
+
+// ChainGenerator по очереди опрашивает модели, пока одна из них не вернет корректный рецепт
+type ChainGenerator struct {
+	links  []chainLink
+	logger *zap.Logger
+}
+
+// NewChainGenerator собирает цепочку моделей. Звенья с тем же провайдером, что и base,
+// наследуют его адрес API; остальные используют адрес по умолчанию своего провайдера.
+func NewChainGenerator(baseProvider string, base Options, specs []ModelSpec, logger *zap.Logger) (*ChainGenerator, error) {
+	if len(specs) == 0 {
+		return nil, errors.New("empty model chain")
+	}
+
+	chain := &ChainGenerator{logger: logger}
+	for _, spec := range specs {
+		opts := base
+		opts.Model = spec.Model
+		if spec.Provider != baseProvider {
+			opts.BaseURL = ""
+		}
+
+		generator, err := New(spec.Provider, opts, logger)
+		if err != nil {
+			return nil, fmt.Errorf("model chain link %s: %w", spec, err)
+		}
+		chain.links = append(chain.links, chainLink{name: spec.String(), generator: generator})
+	}
+	return chain, nil
+}
+
+func (c *ChainGenerator) GenerateRecipe(ctx context.Context, req Request) (*Recipe, error) {
+	var attempts []error
+
+	for i, link := range c.links {
+		recipe, err := link.generator.GenerateRecipe(ctx, req)
+		if err == nil {
+			if i > 0 {
+				c.logger.Info("Рецепт получен от резервной модели",
+					zap.String("link", link.name), zap.Int("attempt", i+1))
+			}
+			return recipe, nil
+		}
+
+		var genErr *GenerationError
+		if !errors.As(err, &genErr) {
+			genErr = newGenerationError(link.name, "", err)
+		}
+		attempts = append(attempts, genErr)
+
+		c.logger.Warn("Модель не смогла сгенерировать рецепт",
+			zap.String("link", link.name),
+			zap.Int("attempt", i+1),
+			zap.String("kind", string(genErr.Kind)),
+			zap.Error(err))
+
+		// Отмена запроса не повод опрашивать следующие модели
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("все модели цепочки завершились ошибкой: %w", errors.Join(attempts...))
+}
@@ -0,0 +1,157 @@
+package recipes
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion - текущая версия JSON-документа рецепта, хранимого в БД.
+// Версия 0 означает, что документа нет и доступен только старый Markdown.
+const SchemaVersion = 1
+
+// EncodeDocument сериализует рецепт для хранения
+func EncodeDocument(recipe *Recipe) ([]byte, error) {
+	return json.Marshal(recipe)
+}
+
+// DecodeDocument восстанавливает рецепт из хранимого документа
+func DecodeDocument(data []byte, version int32) (*Recipe, error) {
+	if len(data) == 0 || version <= 0 {
+		return nil, fmt.Errorf("recipe document is missing")
+	}
+	if version > SchemaVersion {
+		return nil, fmt.Errorf("unsupported recipe document version %d", version)
+	}
+
+	var recipe Recipe
+	if err := json.Unmarshal(data, &recipe); err != nil {
+		return nil, fmt.Errorf("decode recipe document: %w", err)
+	}
+	return &recipe, nil
+}
+
+var (
+	numberedLine = regexp.MustCompile(`^\d+[.)]\s*`)
+	stepDuration = regexp.MustCompile(`\s*\(⏱ (\d+) мин\)$`)
+	metaNumber   = regexp.MustCompile(`(\d+)`)
+)
+
+// ParseMarkdown восстанавливает рецепт из Markdown, ранее сохраненного FormatRecipe.
+// Понимает как старый формат со свободным текстом инструкций, так и структурированный.
+// Разбор выполняется по возможности: нераспознанный текст попадает в шаги приготовления.
+func ParseMarkdown(title, content string) *Recipe {
+	recipe := &Recipe{Title: title}
+
+	const (
+		sectionHeader = iota
+		sectionIngredients
+		sectionSteps
+	)
+	section := sectionHeader
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "🍳"):
+			if t := strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "🍳")), "*"); t != "" && recipe.Title == "" {
+				recipe.Title = t
+			}
+			continue
+		case line == "*Ингредиенты:*":
+			section = sectionIngredients
+			continue
+		case line == "*Инструкции:*" || line == "*Приготовление:*":
+			section = sectionSteps
+			continue
+		}
+
+		switch section {
+		case sectionHeader:
+			parseMetaLine(recipe, line)
+		case sectionIngredients:
+			if strings.HasPrefix(line, pantryMark) {
+				continue
+			}
+			recipe.Ingredients = append(recipe.Ingredients, parseIngredientLine(numberedLine.ReplaceAllString(line, "")))
+		case sectionSteps:
+			step := Step{Text: numberedLine.ReplaceAllString(line, "")}
+			if m := stepDuration.FindStringSubmatch(step.Text); m != nil {
+				step.DurationMinutes, _ = strconv.Atoi(m[1])
+				step.Text = strings.TrimSpace(stepDuration.ReplaceAllString(step.Text, ""))
+			}
+			recipe.Steps = append(recipe.Steps, step)
+		}
+	}
+
+	// Совсем нераспознанный текст сохраняем целиком, чтобы ничего не потерять
+	if len(recipe.Ingredients) == 0 && len(recipe.Steps) == 0 && strings.TrimSpace(content) != "" {
+		recipe.Steps = []Step{{Text: strings.TrimSpace(content)}}
+	}
+
+	recipe.Normalize()
+	return recipe
+}
+
+// parseMetaLine разбирает строки с порциями, временем, сложностью, кухней и калорийностью
+func parseMetaLine(recipe *Recipe, line string) {
+	number := 0
+	if m := metaNumber.FindString(line); m != "" {
+		number, _ = strconv.Atoi(m)
+	}
+
+	switch {
+	case strings.Contains(line, "Порций:"):
+		recipe.Servings = number
+	case strings.Contains(line, "Подготовка:"):
+		recipe.PrepTimeMinutes = number
+	case strings.Contains(line, "Готовка:"):
+		recipe.CookTimeMinutes = number
+	case strings.Contains(line, "Калорийность:"):
+		recipe.Calories = number
+	case strings.Contains(line, "Сложность:"):
+		name := strings.TrimSpace(line[strings.Index(line, ":")+1:])
+		for key, value := range difficultyNames {
+			if value == name {
+				recipe.Difficulty = key
+			}
+		}
+	case strings.Contains(line, "Кухня:"):
+		recipe.Cuisine = strings.TrimSpace(line[strings.Index(line, ":")+1:])
+	}
+}
+
+// parseIngredientLine разбирает строку вида "Яйца — 3 шт (по желанию)" или "Соль — по вкусу 🏠"
+func parseIngredientLine(line string) Ingredient {
+	var ingredient Ingredient
+	if strings.HasSuffix(line, pantryMark) {
+		ingredient.Pantry = true
+		line = strings.TrimSpace(strings.TrimSuffix(line, pantryMark))
+	}
+	if strings.HasSuffix(line, "(по желанию)") {
+		ingredient.Optional = true
+		line = strings.TrimSpace(strings.TrimSuffix(line, "(по желанию)"))
+	}
+
+	name, amount, found := strings.Cut(line, " — ")
+	ingredient.Name = strings.TrimSpace(name)
+	if !found {
+		return ingredient
+	}
+
+	amount = strings.TrimSpace(amount)
+	quantity, unit, _ := strings.Cut(amount, " ")
+	if q, err := strconv.ParseFloat(strings.Replace(quantity, ",", ".", 1), 64); err == nil {
+		ingredient.Quantity = q
+		ingredient.Unit = strings.TrimSpace(unit)
+	} else {
+		ingredient.Unit = amount
+	}
+	return ingredient
+}
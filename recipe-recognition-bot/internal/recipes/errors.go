@@ -0,0 +1,82 @@
+package recipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/resilience"
+)
+
+var (
+	// ErrMalformedResponse - ответ модели не удалось разобрать как JSON рецепта
+	ErrMalformedResponse = errors.New("некорректный ответ модели")
+	// ErrIncompleteRecipe - в рецепте отсутствуют обязательные поля
+	ErrIncompleteRecipe = errors.New("неполный рецепт")
+)
+
+// ErrorKind классифицирует неудачную попытку генерации
+type ErrorKind string
+
+const (
+	ErrorKindRateLimited ErrorKind = "rate_limited"
+	ErrorKindServer      ErrorKind = "server_error"
+	ErrorKindParse       ErrorKind = "parse_failure"
+	ErrorKindIncomplete  ErrorKind = "incomplete_recipe"
+	ErrorKindTimeout     ErrorKind = "timeout"
+	ErrorKindCircuitOpen ErrorKind = "circuit_open"
+	ErrorKindCanceled    ErrorKind = "canceled"
+	ErrorKindOther       ErrorKind = "other"
+)
+
+// GenerationError описывает неудачную попытку генерации конкретной моделью
+type GenerationError struct {
+	Provider string
+	Model    string
+	Kind     ErrorKind
+	Err      error
+}
+
+func (e *GenerationError) Error() string {
+	return fmt.Sprintf("%s/%s (%s): %v", e.Provider, e.Model, e.Kind, e.Err)
+}
+
+func (e *GenerationError) Unwrap() error {
+	return e.Err
+}
+
+// newGenerationError оборачивает ошибку бэкенда с классификацией
+func newGenerationError(provider, model string, err error) *GenerationError {
+	return &GenerationError{
+		Provider: provider,
+		Model:    model,
+		Kind:     classifyError(err),
+		Err:      err,
+	}
+}
+
+// classifyError определяет вид ошибки по HTTP-статусу или типу ошибки разбора
+func classifyError(err error) ErrorKind {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorKindCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindTimeout
+	case errors.Is(err, resilience.ErrCircuitOpen):
+		return ErrorKindCircuitOpen
+	case errors.Is(err, ErrIncompleteRecipe):
+		return ErrorKindIncomplete
+	case errors.Is(err, ErrMalformedResponse):
+		return ErrorKindParse
+	}
+
+	status := resilience.StatusCode(err)
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrorKindRateLimited
+	case status >= http.StatusInternalServerError:
+		return ErrorKindServer
+	}
+	return ErrorKindOther
+}
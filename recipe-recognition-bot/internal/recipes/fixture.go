@@ -0,0 +1,58 @@
+package recipes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("fixture", func(opts Options, logger *zap.Logger) (Generator, error) {
+		return NewFixtureGenerator(), nil
+	})
+}
+
+// FixtureGenerator - детерминированный бэкенд без сетевых запросов для тестов и локальной отладки
+type FixtureGenerator struct{}
+
+// NewFixtureGenerator создает бэкенд, собирающий рецепт прямо из списка продуктов
+func NewFixtureGenerator() *FixtureGenerator {
+	return &FixtureGenerator{}
+}
+
+func (f *FixtureGenerator) GenerateRecipe(ctx context.Context, req Request) (*Recipe, error) {
+	products := req.Products
+	if len(products) == 0 {
+		return nil, fmt.Errorf("пустой список продуктов")
+	}
+
+	ingredients := make([]Ingredient, 0, len(products))
+	for _, product := range products {
+		ingredients = append(ingredients, Ingredient{Name: product, Quantity: 1, Unit: "шт"})
+	}
+	for _, staple := range req.Staples {
+		ingredients = append(ingredients, Ingredient{Name: staple, Unit: "по вкусу", Pantry: true})
+	}
+
+	// Каждый следующий вариант получает новое название, чтобы не совпадать с уже предложенными
+	title := "Блюдо из: " + strings.Join(products, ", ")
+	if len(req.AvoidTitles) > 0 {
+		title = fmt.Sprintf("%s (вариант %d)", title, len(req.AvoidTitles)+1)
+	}
+
+	return &Recipe{
+		Title:           title,
+		Servings:        2,
+		PrepTimeMinutes: 10,
+		CookTimeMinutes: 15,
+		Difficulty:      DifficultyEasy,
+		Ingredients:     ingredients,
+		Steps: []Step{
+			{Text: "Подготовьте и нарежьте все ингредиенты."},
+			{Text: "Смешайте ингредиенты и готовьте до готовности.", DurationMinutes: 15},
+		},
+		Model: "fixture",
+	}, nil
+}
@@ -2,127 +2,179 @@ package recipes
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
-	"github.com/sashabaranov/go-openai"
 	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/llmjson"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/resilience"
 )
 
-type RecipeGenerator struct {
-	client *openai.Client
-	logger *zap.Logger
+const defaultMaxTokens = 1000
+
+// systemPrompt задает роль модели для всех бэкендов генерации
+const systemPrompt = "Ты - эксперт кулинарии. Генерируешь рецепты из доступных продуктов."
+
+// Request - запрос на генерацию рецепта
+type Request struct {
+	// Products - доступные продукты
+	Products []string
+	// Priority - продукты, которые нужно использовать в первую очередь: у них истекает срок годности
+	Priority []string
+	// Staples - запасы, которые всегда есть у пользователя: соль, масло, вода
+	Staples []string
+	// AvoidTitles - уже предложенные блюда, которые не нужно повторять
+	AvoidTitles []string
+	// Force - сгенерировать новый рецепт, даже если подходящий есть в кэше
+	Force bool
+	// Preferences - диеты, аллергии и ограничение калорийности пользователя
+	Preferences Preferences
 }
 
-type Recipe struct {
-	Title        string   `json:"title"`
-	Ingredients  []string `json:"ingredients"`
-	Instructions string   `json:"instructions"`
+// Generator генерирует рецепт по запросу
+type Generator interface {
+	GenerateRecipe(ctx context.Context, req Request) (*Recipe, error)
 }
 
-func NewRecipeGenerator(apiKey string, logger *zap.Logger) *RecipeGenerator {
-	// Создаем конфигурацию для OpenRouter вместо OpenAI
-	config := openai.DefaultConfig(apiKey)
-	config.BaseURL = "https://openrouter.ai/api/v1"
-	// Проверка ключа
-	if apiKey == "" {
-		logger.Error("API ключ OpenRouter пустой")
-		// Возможно выбросить ошибку здесь
-	}
-	return &RecipeGenerator{
-		client: openai.NewClientWithConfig(config),
-		logger: logger,
-	}
+// Options содержит параметры подключения к бэкенду генерации.
+// Пустые значения заменяются значениями по умолчанию конкретного бэкенда.
+type Options struct {
+	APIKey      string
+	BaseURL     string
+	Model       string
+	Temperature float32
+	MaxTokens   int
+	Resilience  resilience.Policy
+	// StructuredOutput включает передачу JSON-схемы ответа, если провайдер это поддерживает
+	StructuredOutput bool
 }
 
-func (g *RecipeGenerator) GenerateRecipe(ctx context.Context, products []string) (*Recipe, error) {
-	productsList := strings.Join(products, ", ")
-	g.logger.Info("Генерация рецепта", zap.Strings("продукты", products))
-
-	prompt := fmt.Sprintf(`Вот список продуктов: %s
+// Factory создает бэкенд генерации по параметрам
+type Factory func(opts Options, logger *zap.Logger) (Generator, error)
 
-Ты - повар!
-Задача: создать полный рецепт блюда, используя только эти продукты, рецепт должен быть в формате JSON.
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
 
-Формат ответа - строго JSON (дается для примера):
-{
-  "title": "Название блюда",
-  "ingredients": ["ингредиент 1 с количеством", "ингредиент 2 с количеством", "..."],
-  "instructions": "Пошаговые инструкции по приготовлению"
-}
+// Register регистрирует бэкенд генерации под указанным именем
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
 
-Важно: верни ТОЛЬКО JSON без дополнительного текста!`, productsList)
-
-	g.logger.Debug("Отправка запроса в OpenRouter", zap.String("prompt", prompt))
-
-	resp, err := g.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: "deepseek/deepseek-chat:free",
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: "Ты - эксперт кулинарии. Генерируешь рецепты из доступных продуктов.",
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			MaxTokens: 1000,
-		},
-	)
-
-	if err != nil {
-		g.logger.Error("Ошибка запроса к OpenRouter", zap.Error(err))
-		return nil, fmt.Errorf("ошибка API: %w", err)
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("recipes: backend %q already registered", name))
 	}
+	registry[name] = factory
+}
 
-	content := resp.Choices[0].Message.Content
-	g.logger.Debug("Ответ от OpenRouter", zap.String("content", content))
-
-	jsonStart := strings.Index(content, "{")
-	jsonEnd := strings.LastIndex(content, "}")
+// Providers возвращает отсортированный список зарегистрированных бэкендов
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
 
-	if jsonStart == -1 || jsonEnd == -1 || jsonEnd <= jsonStart {
-		g.logger.Error("Некорректный JSON в ответе",
-			zap.Int("jsonStart", jsonStart),
-			zap.Int("jsonEnd", jsonEnd),
-			zap.String("content", content))
-		return nil, fmt.Errorf("некорректный ответ от API: JSON не найден")
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
 
-	jsonContent := content[jsonStart : jsonEnd+1]
-	g.logger.Debug("Извлеченный JSON", zap.String("json", jsonContent))
+// New создает бэкенд генерации по имени провайдера
+func New(provider string, opts Options, logger *zap.Logger) (Generator, error) {
+	registryMu.RLock()
+	factory, ok := registry[provider]
+	registryMu.RUnlock()
 
-	var recipe Recipe
-	if err := json.Unmarshal([]byte(jsonContent), &recipe); err != nil {
-		g.logger.Error("Ошибка парсинга JSON", zap.Error(err), zap.String("json", jsonContent))
-		return nil, fmt.Errorf("ошибка парсинга ответа: %w", err)
+	if !ok {
+		return nil, fmt.Errorf("unknown recipe provider %q (available: %v)", provider, Providers())
 	}
+	return factory(opts, logger)
+}
 
-	if recipe.Title == "" || len(recipe.Ingredients) == 0 || recipe.Instructions == "" {
-		g.logger.Error("Неполный рецепт", zap.Any("recipe", recipe))
-		return nil, fmt.Errorf("неполный рецепт от API: отсутствуют обязательные поля")
+// buildPrompt формирует пользовательский запрос на генерацию рецепта
+func buildPrompt(req Request) string {
+	productsList := strings.Join(req.Products, ", ")
+
+	var constraints strings.Builder
+	allowed := "только эти продукты"
+	if len(req.Staples) > 0 {
+		constraints.WriteString("\nВсегда есть дома, можно использовать при необходимости: ")
+		constraints.WriteString(strings.Join(req.Staples, ", "))
+		constraints.WriteString(".\n")
+		allowed = "только эти продукты и то, что есть дома"
+	}
+	if len(req.Priority) > 0 {
+		constraints.WriteString("\nУ этих продуктов истекает срок годности, обязательно используй их в первую очередь: ")
+		constraints.WriteString(strings.Join(req.Priority, ", "))
+		constraints.WriteString(".\n")
 	}
+	if len(req.AvoidTitles) > 0 {
+		constraints.WriteString("\nНе предлагай эти блюда, нужно другое: ")
+		constraints.WriteString(strings.Join(req.AvoidTitles, "; "))
+		constraints.WriteString(".\n")
+	}
+	constraints.WriteString(req.Preferences.promptConstraints())
 
-	g.logger.Info("Рецепт успешно сгенерирован", zap.String("title", recipe.Title))
-	return &recipe, nil
+	return fmt.Sprintf(`Вот список продуктов: %s
+%s
+Ты - повар!
+Задача: создать полный рецепт блюда, используя %s, рецепт должен быть в формате JSON.
+
+Формат ответа - строго JSON (дается для примера):
+{
+  "title": "Название блюда",
+  "servings": 2,
+  "prep_time_minutes": 10,
+  "cook_time_minutes": 20,
+  "difficulty": "easy",
+  "cuisine": "русская",
+  "calories_per_serving": 350,
+  "ingredients": [
+    {"name": "яйца", "quantity": 3, "unit": "шт"},
+    {"name": "соль", "quantity": 0, "unit": "по вкусу", "pantry": true},
+    {"name": "зелень", "quantity": 0, "unit": "по вкусу", "optional": true}
+  ],
+  "steps": [
+    {"text": "Первый шаг приготовления", "duration_minutes": 5},
+    {"text": "Второй шаг приготовления"}
+  ]
 }
 
-func (g *RecipeGenerator) FormatRecipe(recipe *Recipe) string {
-	var sb strings.Builder
+Правила:
+- difficulty - одно из: "easy", "medium", "hard";
+- quantity - число; если количество не измеряется, укажи 0 и поясни в unit;
+- pantry: true - только у ингредиентов из того, что есть дома, а не из списка продуктов;
+- время указывается в минутах целыми числами;
+- calories_per_serving - примерная калорийность одной порции в ккал, целое число;
+- шаги перечисляются по порядку, duration_minutes указывается для шагов, которые требуют ожидания.
 
-	sb.WriteString(fmt.Sprintf("🍳 *%s*\n\n", recipe.Title))
+Важно: верни ТОЛЬКО JSON без дополнительного текста!`, productsList, constraints.String(), allowed)
+}
 
-	sb.WriteString("*Ингредиенты:*\n")
-	for i, ingredient := range recipe.Ingredients {
-		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, ingredient))
-	}
+// recipeSchema описывает ожидаемый ответ модели для structured output
+var recipeSchema = llmjson.MustSchemaFor("recipe", Recipe{})
 
-	sb.WriteString("\n*Инструкции:*\n")
-	sb.WriteString(recipe.Instructions)
+// parseRecipe извлекает и проверяет рецепт из текстового ответа модели на запрос req
+func parseRecipe(content string, req Request, logger *zap.Logger) (*Recipe, error) {
+	var recipe Recipe
+	err := llmjson.Decode(content, &recipe)
+
+	var validationErr *llmjson.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		logger.Error("Неполный рецепт", zap.Int("content_length", len(content)), zap.Error(err))
+		return nil, fmt.Errorf("%w: %v", ErrIncompleteRecipe, err)
+	case err != nil:
+		logger.Error("Некорректный JSON в ответе", zap.Int("content_length", len(content)), zap.Error(err))
+		return nil, fmt.Errorf("%w: %v", ErrMalformedResponse, err)
+	}
 
-	return sb.String()
+	recipe.markPantry(req)
+	return &recipe, nil
 }
@@ -0,0 +1,155 @@
+package recipes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/resilience"
+)
+
+const (
+	defaultOllamaBaseURL = "http://localhost:11434"
+	defaultOllamaModel   = "llama3.1"
+)
+
+func init() {
+	Register("ollama", func(opts Options, logger *zap.Logger) (Generator, error) {
+		return NewOllamaGenerator(opts, logger), nil
+	})
+}
+
+// OllamaGenerator генерирует рецепты через локальный HTTP API в стиле Ollama (/api/chat)
+type OllamaGenerator struct {
+	httpClient  *resilience.HTTPClient
+	executor    *resilience.Executor
+	logger      *zap.Logger
+	baseURL     string
+	model       string
+	temperature float32
+	maxTokens   int
+	structured  bool
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   json.RawMessage `json:"format,omitempty"`
+	Options  map[string]any  `json:"options,omitempty"`
+}
+
+type ollamaChatResponse struct {
+	Message ollamaMessage `json:"message"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// NewOllamaGenerator создает клиент локального бэкенда генерации
+func NewOllamaGenerator(opts Options, logger *zap.Logger) *OllamaGenerator {
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = defaultOllamaBaseURL
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = defaultOllamaModel
+	}
+
+	maxTokens := opts.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
+
+	return &OllamaGenerator{
+		httpClient:  resilience.NewHTTPClient(&http.Client{}),
+		executor:    resilience.New("recipes/ollama/"+model, opts.Resilience, logger),
+		logger:      logger,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		model:       model,
+		temperature: opts.Temperature,
+		maxTokens:   maxTokens,
+		structured:  opts.StructuredOutput,
+	}
+}
+
+func (g *OllamaGenerator) GenerateRecipe(ctx context.Context, req Request) (*Recipe, error) {
+	g.logger.Info("Генерация рецепта локальной моделью", zap.Strings("продукты", req.Products), zap.String("model", g.model))
+
+	var format json.RawMessage
+	if g.structured {
+		format = recipeSchema.JSON()
+	}
+
+	body, err := json.Marshal(ollamaChatRequest{
+		Model:  g.model,
+		Format: format,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: buildPrompt(req)},
+		},
+		Options: map[string]any{
+			"temperature": g.temperature,
+			"num_predict": g.maxTokens,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody []byte
+	err = g.executor.Do(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/api/chat", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := g.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		respBody, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return resilience.NewStatusError(resp, respBody)
+		}
+		return nil
+	})
+	if err != nil {
+		g.logger.Error("Ошибка запроса к локальной модели", zap.Error(err))
+		return nil, newGenerationError("ollama", g.model, fmt.Errorf("ошибка API: %w", err))
+	}
+
+	var chatResp ollamaChatResponse
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		return nil, newGenerationError("ollama", g.model, fmt.Errorf("%w: %v", ErrMalformedResponse, err))
+	}
+	if chatResp.Error != "" {
+		return nil, newGenerationError("ollama", g.model, fmt.Errorf("ошибка API: %s", chatResp.Error))
+	}
+
+	recipe, err := parseRecipe(chatResp.Message.Content, req, g.logger)
+	if err != nil {
+		return nil, newGenerationError("ollama", g.model, err)
+	}
+	recipe.Model = g.model
+
+	g.logger.Info("Рецепт успешно сгенерирован", zap.String("title", recipe.Title))
+	return recipe, nil
+}
@@ -0,0 +1,121 @@
+package recipes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/sashabaranov/go-openai"
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/resilience"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://openrouter.ai/api/v1"
+	defaultOpenAIModel   = "deepseek/deepseek-chat:free"
+)
+
+func init() {
+	Register("openai", func(opts Options, logger *zap.Logger) (Generator, error) {
+		return NewOpenAIGenerator(opts, logger), nil
+	})
+}
+
+// OpenAIGenerator генерирует рецепты через OpenAI-совместимый API (по умолчанию OpenRouter)
+type OpenAIGenerator struct {
+	client      *openai.Client
+	executor    *resilience.Executor
+	logger      *zap.Logger
+	model       string
+	temperature float32
+	maxTokens   int
+	structured  bool
+}
+
+func NewOpenAIGenerator(opts Options, logger *zap.Logger) *OpenAIGenerator {
+	config := openai.DefaultConfig(opts.APIKey)
+	config.BaseURL = defaultOpenAIBaseURL
+	if opts.BaseURL != "" {
+		config.BaseURL = opts.BaseURL
+	}
+	config.HTTPClient = resilience.NewHTTPClient(&http.Client{})
+	// Проверка ключа
+	if opts.APIKey == "" {
+		logger.Error("API ключ для генерации рецептов пустой")
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+
+	maxTokens := opts.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
+
+	return &OpenAIGenerator{
+		client:      openai.NewClientWithConfig(config),
+		executor:    resilience.New("recipes/openai/"+model, opts.Resilience, logger),
+		logger:      logger,
+		model:       model,
+		temperature: opts.Temperature,
+		maxTokens:   maxTokens,
+		structured:  opts.StructuredOutput,
+	}
+}
+
+func (g *OpenAIGenerator) GenerateRecipe(ctx context.Context, req Request) (*Recipe, error) {
+	g.logger.Info("Генерация рецепта", zap.Strings("продукты", req.Products), zap.String("model", g.model))
+
+	prompt := buildPrompt(req)
+	g.logger.Debug("Отправка запроса модели", zap.String("prompt", prompt))
+
+	chatReq := openai.ChatCompletionRequest{
+		Model: g.model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: systemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: prompt,
+			},
+		},
+		Temperature: g.temperature,
+		MaxTokens:   g.maxTokens,
+	}
+	if g.structured {
+		chatReq.ResponseFormat = recipeSchema.ResponseFormat()
+	}
+
+	var resp openai.ChatCompletionResponse
+	err := g.executor.Do(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = g.client.CreateChatCompletion(ctx, chatReq)
+		return err
+	})
+
+	if err != nil {
+		g.logger.Error("Ошибка запроса к модели", zap.Error(err))
+		return nil, newGenerationError("openai", g.model, fmt.Errorf("ошибка API: %w", err))
+	}
+
+	if len(resp.Choices) == 0 {
+		return nil, newGenerationError("openai", g.model, fmt.Errorf("%w: нет вариантов ответа", ErrMalformedResponse))
+	}
+
+	content := resp.Choices[0].Message.Content
+	g.logger.Debug("Ответ модели", zap.Int("content_length", len(content)))
+
+	recipe, err := parseRecipe(content, req, g.logger)
+	if err != nil {
+		return nil, newGenerationError("openai", g.model, err)
+	}
+	recipe.Model = g.model
+
+	g.logger.Info("Рецепт успешно сгенерирован", zap.String("title", recipe.Title))
+	return recipe, nil
+}
@@ -0,0 +1,58 @@
+package recipes
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+)
+
+// DefaultStaples - базовые запасы, которые есть почти на любой кухне
+var DefaultStaples = []string{"соль", "сахар", "перец черный молотый", "масло растительное", "вода"}
+
+// productKeys возвращает ключи сравнения продуктов без повторов
+func productKeys(products []string) map[string]bool {
+	keys := make(map[string]bool, len(products))
+	for _, product := range products {
+		if key := ingredients.Key(product); key != "" {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// productsFingerprint - устойчивое к порядку и формам слов представление списка продуктов для ключа кэша
+func productsFingerprint(name string, products []string) string {
+	if len(products) == 0 {
+		return ""
+	}
+	keys := slices.Sorted(func(yield func(string) bool) {
+		for key := range productKeys(products) {
+			if !yield(key) {
+				return
+			}
+		}
+	})
+	return name + "=" + strings.Join(keys, ",")
+}
+
+// markPantry отмечает ингредиенты из запасов пользователя. Модель отмечает их сама,
+// но может ошибиться в обе стороны: продукт из запасов, совпавший с распознанным,
+// к запасам не относится, а совпавший только с запасами - относится.
+func (r *Recipe) markPantry(req Request) {
+	products := productKeys(req.Products)
+	staples := productKeys(req.Staples)
+
+	for i := range r.Ingredients {
+		ingredient := &r.Ingredients[i]
+		key := ingredients.Key(ingredient.Name)
+		switch {
+		case products[key]:
+			ingredient.Pantry = false
+		case staples[key]:
+			ingredient.Pantry = true
+		case len(req.Staples) == 0:
+			ingredient.Pantry = false
+		}
+	}
+}
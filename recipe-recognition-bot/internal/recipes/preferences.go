@@ -0,0 +1,128 @@
+package recipes
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Диеты
+const (
+	DietVegetarian  = "vegetarian"
+	DietVegan       = "vegan"
+	DietHalal       = "halal"
+	DietLactoseFree = "lactose_free"
+	DietGlutenFree  = "gluten_free"
+)
+
+// Аллергены
+const (
+	AllergenNuts      = "nuts"
+	AllergenPeanuts   = "peanuts"
+	AllergenMilk      = "milk"
+	AllergenEggs      = "eggs"
+	AllergenFish      = "fish"
+	AllergenShellfish = "shellfish"
+	AllergenSoy       = "soy"
+	AllergenSesame    = "sesame"
+)
+
+// Restriction - диета или аллерген с названием для пользователя
+type Restriction struct {
+	ID   string
+	Name string
+}
+
+// Diets - поддерживаемые диеты в порядке показа
+var Diets = []Restriction{
+	{DietVegetarian, "вегетарианство"},
+	{DietVegan, "веганство"},
+	{DietHalal, "халяль"},
+	{DietLactoseFree, "без лактозы"},
+	{DietGlutenFree, "без глютена"},
+}
+
+// Allergens - поддерживаемые аллергены в порядке показа
+var Allergens = []Restriction{
+	{AllergenNuts, "орехи"},
+	{AllergenPeanuts, "арахис"},
+	{AllergenMilk, "молоко"},
+	{AllergenEggs, "яйца"},
+	{AllergenFish, "рыба"},
+	{AllergenShellfish, "морепродукты"},
+	{AllergenSoy, "соя"},
+	{AllergenSesame, "кунжут"},
+}
+
+// RestrictionName возвращает название диеты или аллергена для пользователя
+func RestrictionName(id string) string {
+	for _, r := range slices.Concat(Diets, Allergens) {
+		if r.ID == id {
+			return r.Name
+		}
+	}
+	return id
+}
+
+// Preferences - ограничения питания пользователя, учитываемые при генерации
+type Preferences struct {
+	Diets     []string
+	Allergens []string
+	// MaxCalories - калорийность порции, которую не нужно превышать; 0 - без ограничения
+	MaxCalories int
+}
+
+// Empty сообщает, что ограничений нет
+func (p Preferences) Empty() bool {
+	return len(p.Diets) == 0 && len(p.Allergens) == 0 && p.MaxCalories <= 0
+}
+
+// restrictions - все диеты и аллергены пользователя
+func (p Preferences) restrictions() []string {
+	return slices.Concat(p.Diets, p.Allergens)
+}
+
+// Fingerprint - устойчивое к порядку представление ограничений для ключа кэша
+func (p Preferences) Fingerprint() string {
+	if p.Empty() {
+		return ""
+	}
+
+	diets := slices.Sorted(slices.Values(p.Diets))
+	allergens := slices.Sorted(slices.Values(p.Allergens))
+	return fmt.Sprintf("diets=%s;allergens=%s;kcal=%d",
+		strings.Join(diets, ","), strings.Join(allergens, ","), p.MaxCalories)
+}
+
+// promptConstraints - ограничения пользователя для запроса модели
+func (p Preferences) promptConstraints() string {
+	var sb strings.Builder
+	if len(p.Diets) > 0 {
+		sb.WriteString("\nРецепт должен подходить для диет: ")
+		sb.WriteString(restrictionNames(p.Diets))
+		sb.WriteString(".")
+	}
+	if len(p.Allergens) > 0 {
+		sb.WriteString("\nУ пользователя аллергия, НЕ используй даже в малых количествах: ")
+		sb.WriteString(restrictionNames(p.Allergens))
+		sb.WriteString(". Если такие продукты есть в списке, пропусти их.")
+	}
+	if p.MaxCalories > 0 {
+		sb.WriteString("\nКалорийность одной порции - не более ")
+		sb.WriteString(strconv.Itoa(p.MaxCalories))
+		sb.WriteString(" ккал.")
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func restrictionNames(ids []string) string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, RestrictionName(id))
+	}
+	return strings.Join(names, ", ")
+}
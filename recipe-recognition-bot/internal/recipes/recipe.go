@@ -0,0 +1,262 @@
+package recipes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Уровни сложности рецепта
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+var difficultyNames = map[string]string{
+	DifficultyEasy:   "легко",
+	DifficultyMedium: "средне",
+	DifficultyHard:   "сложно",
+}
+
+// Recipe - структурированный рецепт
+type Recipe struct {
+	Title           string       `json:"title" llm:"required"`
+	Servings        int          `json:"servings"`
+	PrepTimeMinutes int          `json:"prep_time_minutes"`
+	CookTimeMinutes int          `json:"cook_time_minutes"`
+	Difficulty      string       `json:"difficulty" enum:"easy|medium|hard"`
+	Cuisine         string       `json:"cuisine"`
+	Calories        int          `json:"calories_per_serving,omitempty"`
+	Ingredients     []Ingredient `json:"ingredients" llm:"required"`
+	Steps           []Step       `json:"steps" llm:"required"`
+
+	// Model - модель, которая в итоге сгенерировала рецепт
+	Model string `json:"-"`
+	// Warnings - предупреждения для пользователя, например о нарушении его ограничений питания
+	Warnings []string `json:"-"`
+}
+
+// Ingredient - ингредиент с количеством. Quantity = 0 означает "по вкусу" или не указано.
+type Ingredient struct {
+	Name     string  `json:"name" llm:"required"`
+	Quantity float64 `json:"quantity,omitempty"`
+	Unit     string  `json:"unit,omitempty"`
+	Optional bool    `json:"optional,omitempty"`
+	// Pantry - ингредиент из запасов пользователя, а не из распознанных продуктов
+	Pantry bool `json:"pantry,omitempty"`
+}
+
+// Step - шаг приготовления с необязательной длительностью
+type Step struct {
+	Text            string `json:"text" llm:"required"`
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+}
+
+// UnmarshalJSON принимает как объект, так и строку с названием ингредиента,
+// а количество - как число или строку ("1,5", "по вкусу")
+func (i *Ingredient) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*i = Ingredient{Name: name}
+		return nil
+	}
+
+	var raw struct {
+		Name     string          `json:"name"`
+		Quantity json.RawMessage `json:"quantity"`
+		Unit     string          `json:"unit"`
+		Optional bool            `json:"optional"`
+		Pantry   bool            `json:"pantry"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*i = Ingredient{Name: raw.Name, Unit: raw.Unit, Optional: raw.Optional, Pantry: raw.Pantry}
+	if len(raw.Quantity) == 0 || string(raw.Quantity) == "null" {
+		return nil
+	}
+
+	if err := json.Unmarshal(raw.Quantity, &i.Quantity); err == nil {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw.Quantity, &text); err != nil {
+		return fmt.Errorf("ingredient %q: invalid quantity %s", raw.Name, raw.Quantity)
+	}
+	text = strings.TrimSpace(text)
+	if q, err := strconv.ParseFloat(strings.Replace(text, ",", ".", 1), 64); err == nil {
+		i.Quantity = q
+	} else if i.Unit == "" {
+		i.Unit = text
+	}
+	return nil
+}
+
+// UnmarshalJSON принимает как объект, так и строку с текстом шага
+func (s *Step) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*s = Step{Text: text}
+		return nil
+	}
+
+	type plain Step
+	var step plain
+	if err := json.Unmarshal(data, &step); err != nil {
+		return err
+	}
+	*s = Step(step)
+	return nil
+}
+
+// Normalize приводит необязательные поля к допустимым значениям
+func (r *Recipe) Normalize() {
+	r.Title = strings.TrimSpace(r.Title)
+	r.Cuisine = strings.TrimSpace(r.Cuisine)
+
+	r.Difficulty = strings.ToLower(strings.TrimSpace(r.Difficulty))
+	if _, ok := difficultyNames[r.Difficulty]; !ok {
+		r.Difficulty = ""
+	}
+
+	ingredients := r.Ingredients[:0]
+	for _, ingredient := range r.Ingredients {
+		ingredient.Name = strings.TrimSpace(ingredient.Name)
+		ingredient.Unit = strings.TrimSpace(ingredient.Unit)
+		if ingredient.Name != "" {
+			ingredients = append(ingredients, ingredient)
+		}
+	}
+	r.Ingredients = ingredients
+
+	steps := r.Steps[:0]
+	for _, step := range r.Steps {
+		step.Text = strings.TrimSpace(step.Text)
+		if step.Text != "" {
+			steps = append(steps, step)
+		}
+	}
+	r.Steps = steps
+}
+
+// Validate проверяет допустимость значений. Наличие обязательных полей
+// (помеченных тегом llm:"required") проверяет llmjson.Decode.
+func (r *Recipe) Validate() error {
+	var problems []error
+
+	if r.Servings < 0 || r.PrepTimeMinutes < 0 || r.CookTimeMinutes < 0 {
+		problems = append(problems, errors.New("отрицательные порции или время"))
+	}
+	if r.Calories < 0 {
+		problems = append(problems, errors.New("отрицательная калорийность"))
+	}
+	for _, ingredient := range r.Ingredients {
+		if ingredient.Quantity < 0 {
+			problems = append(problems, fmt.Errorf("отрицательное количество: %s", ingredient.Name))
+		}
+	}
+	for i, step := range r.Steps {
+		if step.DurationMinutes < 0 {
+			problems = append(problems, fmt.Errorf("отрицательная длительность шага %d", i+1))
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// pantryMark отмечает в рецепте ингредиенты из запасов пользователя
+const pantryMark = "🏠"
+
+// Amount - количество ингредиента с единицей: "3 шт", "по вкусу"; пустая строка - не указано
+func (i Ingredient) Amount() string {
+	if i.Quantity > 0 {
+		return strings.TrimSpace(formatQuantity(i.Quantity) + " " + i.Unit)
+	}
+	return i.Unit
+}
+
+// String форматирует ингредиент для отображения: "Яйца — 3 шт"
+func (i Ingredient) String() string {
+	var sb strings.Builder
+	sb.WriteString(i.Name)
+
+	if amount := i.Amount(); amount != "" {
+		sb.WriteString(" — ")
+		sb.WriteString(amount)
+	}
+	if i.Optional {
+		sb.WriteString(" (по желанию)")
+	}
+	if i.Pantry {
+		sb.WriteString(" " + pantryMark)
+	}
+	return sb.String()
+}
+
+// formatQuantity выводит количество без лишних нулей и с десятичной запятой
+func formatQuantity(q float64) string {
+	return strings.Replace(strconv.FormatFloat(q, 'f', -1, 64), ".", ",", 1)
+}
+
+// FormatRecipe форматирует рецепт в Markdown для отправки в Telegram
+func FormatRecipe(recipe *Recipe) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("🍳 *%s*\n\n", recipe.Title))
+
+	var meta []string
+	if recipe.Servings > 0 {
+		meta = append(meta, fmt.Sprintf("🍽 Порций: %d", recipe.Servings))
+	}
+	if recipe.PrepTimeMinutes > 0 {
+		meta = append(meta, fmt.Sprintf("🔪 Подготовка: %d мин", recipe.PrepTimeMinutes))
+	}
+	if recipe.CookTimeMinutes > 0 {
+		meta = append(meta, fmt.Sprintf("🔥 Готовка: %d мин", recipe.CookTimeMinutes))
+	}
+	if name, ok := difficultyNames[recipe.Difficulty]; ok {
+		meta = append(meta, "📊 Сложность: "+name)
+	}
+	if recipe.Cuisine != "" {
+		meta = append(meta, "🌍 Кухня: "+recipe.Cuisine)
+	}
+	if recipe.Calories > 0 {
+		meta = append(meta, fmt.Sprintf("⚡ Калорийность: %d ккал на порцию", recipe.Calories))
+	}
+	if len(meta) > 0 {
+		sb.WriteString(strings.Join(meta, "\n"))
+		sb.WriteString("\n\n")
+	}
+
+	for _, warning := range recipe.Warnings {
+		sb.WriteString("⚠️ ")
+		sb.WriteString(warning)
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString("*Ингредиенты:*\n")
+	pantry := false
+	for i, ingredient := range recipe.Ingredients {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, ingredient))
+		pantry = pantry || ingredient.Pantry
+	}
+	if pantry {
+		sb.WriteString(pantryMark + " — есть дома\n")
+	}
+
+	sb.WriteString("\n*Приготовление:*\n")
+	for i, step := range recipe.Steps {
+		if step.DurationMinutes > 0 {
+			sb.WriteString(fmt.Sprintf("%d. %s (⏱ %d мин)\n", i+1, step.Text, step.DurationMinutes))
+		} else {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, step.Text))
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,77 @@
+package recipes
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ValidatingGenerator проверяет рецепт по словарю запрещенных продуктов из предпочтений пользователя.
+// Если модель все же использовала запрещенный ингредиент, рецепт генерируется заново
+// не больше maxRegenerations раз; если нарушение осталось, рецепт получает предупреждение.
+type ValidatingGenerator struct {
+	next             Generator
+	maxRegenerations int
+	logger           *zap.Logger
+}
+
+// NewValidatingGenerator оборачивает бэкенд генерации проверкой ограничений питания
+func NewValidatingGenerator(next Generator, maxRegenerations int, logger *zap.Logger) *ValidatingGenerator {
+	return &ValidatingGenerator{next: next, maxRegenerations: max(0, maxRegenerations), logger: logger}
+}
+
+func (v *ValidatingGenerator) GenerateRecipe(ctx context.Context, req Request) (*Recipe, error) {
+	recipe, err := v.next.GenerateRecipe(ctx, req)
+	if err != nil || req.Preferences.Empty() {
+		return recipe, err
+	}
+
+	violations := CheckPreferences(recipe, req.Preferences)
+
+	// Кэш мог вернуть тот же рецепт, поэтому повторные запросы требуют нового и другого блюда
+	retry := req
+	retry.AvoidTitles = slices.Clip(req.AvoidTitles)
+	retry.Force = true
+
+	rejected := recipe
+	for attempt := 1; len(violations) > 0 && attempt <= v.maxRegenerations; attempt++ {
+		v.logger.Warn("Recipe violates user preferences, regenerating",
+			zap.String("title", rejected.Title),
+			zap.Stringers("violations", violations),
+			zap.Int("attempt", attempt))
+
+		retry.AvoidTitles = append(retry.AvoidTitles, rejected.Title)
+		candidate, err := v.next.GenerateRecipe(ctx, retry)
+		if err != nil {
+			// Рецепт с предупреждением полезнее ошибки
+			v.logger.Warn("Recipe regeneration failed", zap.Error(err))
+			break
+		}
+
+		// Новый рецепт тоже может нарушать ограничения; оставляем тот, где нарушений меньше
+		rejected = candidate
+		if candidateViolations := CheckPreferences(candidate, req.Preferences); len(candidateViolations) < len(violations) {
+			recipe, violations = candidate, candidateViolations
+		}
+	}
+
+	if len(violations) > 0 {
+		v.logger.Warn("Recipe still violates user preferences",
+			zap.String("title", recipe.Title),
+			zap.Stringers("violations", violations))
+		recipe.Warnings = append(recipe.Warnings, violationsWarning(violations))
+	}
+	return recipe, nil
+}
+
+// violationsWarning - предупреждение о продуктах, которые не подходят пользователю
+func violationsWarning(violations []Violation) string {
+	items := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		items = append(items, violation.String())
+	}
+	return "Рецепт может не подходить под ваши ограничения: " + strings.Join(items, ", ") +
+		". Замените эти продукты или попросите другой рецепт."
+}
@@ -0,0 +1,141 @@
+package reminders
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/inventory"
+)
+
+// Clock - источник времени планировщика. Реальные часы заменяются в тестах управляемыми,
+// чтобы проверять расписание без ожидания.
+type Clock interface {
+	Now() time.Time
+	// After возвращает канал, в который придет текущее время через d
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// SystemClock - реальные часы
+var SystemClock Clock = systemClock{}
+
+// Reminder - продукты одного пользователя, срок годности которых скоро истекает
+type Reminder struct {
+	UserID int32
+	// ChatID - личный чат с ботом, его ID совпадает с Telegram ID пользователя
+	ChatID int64
+	Items  []inventory.Item
+}
+
+// Store - хранилище продуктов и отметок об отправленных напоминаниях
+type Store interface {
+	// ExpiringItems возвращает продукты со сроком до expiresBefore, сгруппированные по пользователям,
+	// которым не напоминали начиная с remindedBefore
+	ExpiringItems(ctx context.Context, expiresBefore, remindedBefore time.Time) ([]Reminder, error)
+	// MarkReminded запоминает, что пользователю отправлено напоминание
+	MarkReminded(ctx context.Context, userID int32, at time.Time) error
+}
+
+// Notifier отправляет напоминание пользователю
+type Notifier func(ctx context.Context, reminder Reminder) error
+
+// Options - расписание напоминаний
+type Options struct {
+	// At - время ежедневной рассылки от начала суток по местному времени
+	At time.Duration
+	// Days - за сколько дней до конца срока напоминать о продукте
+	Days int
+}
+
+// Scheduler раз в день напоминает пользователям о продуктах, срок годности которых скоро истекает
+type Scheduler struct {
+	store  Store
+	notify Notifier
+	opts   Options
+	clock  Clock
+	logger *zap.Logger
+}
+
+// NewScheduler создает планировщик напоминаний
+func NewScheduler(store Store, notify Notifier, opts Options, clock Clock, logger *zap.Logger) *Scheduler {
+	return &Scheduler{store: store, notify: notify, opts: opts, clock: clock, logger: logger}
+}
+
+// Next возвращает время первой рассылки после now
+func (s *Scheduler) Next(now time.Time) time.Time {
+	next := s.slot(now, 0)
+	if !next.After(now) {
+		next = s.slot(now, 1)
+	}
+	return next
+}
+
+// slot - время рассылки через days дней после дня now. Время суток задается по часам,
+// а не отсчетом от полуночи: в день перехода на летнее время в сутках 23 часа.
+func (s *Scheduler) slot(now time.Time, days int) time.Time {
+	year, month, day := now.Date()
+	hour, minute := int(s.opts.At/time.Hour), int(s.opts.At%time.Hour/time.Minute)
+	return time.Date(year, month, day+days, hour, minute, 0, 0, now.Location())
+}
+
+// Run отправляет напоминания по расписанию до отмены контекста. Рассылка, пропущенная,
+// пока бот не работал, отправляется сразу после запуска: отметки в хранилище
+// не дают напомнить пользователю дважды за день.
+func (s *Scheduler) Run(ctx context.Context) {
+	if now := s.clock.Now(); !now.Before(s.slot(now, 0)) {
+		s.remind(ctx, now)
+	}
+
+	for {
+		now := s.clock.Now()
+		select {
+		case <-ctx.Done():
+			return
+		case tick := <-s.clock.After(s.Next(now).Sub(now)):
+			s.remind(ctx, tick)
+		}
+	}
+}
+
+func (s *Scheduler) remind(ctx context.Context, now time.Time) {
+	sent, err := s.Remind(ctx, now)
+	if err != nil {
+		s.logger.Warn("Failed to send expiry reminders", zap.Error(err))
+		return
+	}
+	if sent > 0 {
+		s.logger.Info("Expiry reminders sent", zap.Int("count", sent))
+	}
+}
+
+// Remind отправляет напоминания всем, у кого есть продукты с истекающим сроком и кому сегодня
+// еще не напоминали, и возвращает число отправленных. Ошибка отправки одному пользователю
+// не останавливает рассылку: ему напомнят при следующем запуске.
+func (s *Scheduler) Remind(ctx context.Context, now time.Time) (int, error) {
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	due, err := s.store.ExpiringItems(ctx, inventory.ExpiryHorizon(now, s.opts.Days), today)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, reminder := range due {
+		if err := s.notify(ctx, reminder); err != nil {
+			s.logger.Warn("Failed to send expiry reminder", zap.Int32("user_id", reminder.UserID), zap.Error(err))
+			continue
+		}
+		if err := s.store.MarkReminded(ctx, reminder.UserID, now); err != nil {
+			s.logger.Warn("Failed to mark expiry reminder", zap.Int32("user_id", reminder.UserID), zap.Error(err))
+		}
+		sent++
+	}
+	return sent, nil
+}
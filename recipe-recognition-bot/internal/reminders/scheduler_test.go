@@ -0,0 +1,303 @@
+package reminders
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/inventory"
+)
+
+// fakeClock - часы, время на которых идет только по команде теста
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers chan fakeTimer
+}
+
+// fakeTimer - ожидание, запрошенное планировщиком через After
+type fakeTimer struct {
+	d  time.Duration
+	ch chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, timers: make(chan fakeTimer, 1)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.timers <- fakeTimer{d: d, ch: ch}
+	return ch
+}
+
+// fire переводит часы на время ожидания и будит планировщик
+func (c *fakeClock) fire(timer fakeTimer) {
+	c.mu.Lock()
+	c.now = c.now.Add(timer.d)
+	now := c.now
+	c.mu.Unlock()
+	timer.ch <- now
+}
+
+// fakeStore повторяет условия запроса ListExpiringInventory
+type fakeStore struct {
+	mu       sync.Mutex
+	items    map[int32][]inventory.Item
+	reminded map[int32]time.Time
+}
+
+func newFakeStore(items map[int32][]inventory.Item) *fakeStore {
+	return &fakeStore{items: items, reminded: make(map[int32]time.Time)}
+}
+
+func (s *fakeStore) ExpiringItems(ctx context.Context, expiresBefore, remindedBefore time.Time) ([]Reminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Reminder
+	for userID, items := range s.items {
+		if at, ok := s.reminded[userID]; ok && !at.Before(remindedBefore) {
+			continue
+		}
+		reminder := Reminder{UserID: userID, ChatID: int64(userID) * 100}
+		for _, item := range items {
+			if !item.ExpiresAt.IsZero() && item.ExpiresAt.Before(expiresBefore) {
+				reminder.Items = append(reminder.Items, item)
+			}
+		}
+		if len(reminder.Items) > 0 {
+			due = append(due, reminder)
+		}
+	}
+	return due, nil
+}
+
+func (s *fakeStore) MarkReminded(ctx context.Context, userID int32, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reminded[userID] = at
+	return nil
+}
+
+// recorder запоминает отправленные напоминания
+type recorder struct {
+	sent chan Reminder
+}
+
+func newRecorder() *recorder {
+	return &recorder{sent: make(chan Reminder, 10)}
+}
+
+func (r *recorder) notify(ctx context.Context, reminder Reminder) error {
+	r.sent <- reminder
+	return nil
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s is not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestSchedulerNext(t *testing.T) {
+	moscow := mustLoadLocation(t, "Europe/Moscow")
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	tests := []struct {
+		name string
+		at   time.Duration
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "до рассылки",
+			at:   10 * time.Hour,
+			now:  time.Date(2024, 5, 10, 9, 59, 0, 0, moscow),
+			want: time.Date(2024, 5, 10, 10, 0, 0, 0, moscow),
+		},
+		{
+			name: "ровно во время рассылки",
+			at:   10 * time.Hour,
+			now:  time.Date(2024, 5, 10, 10, 0, 0, 0, moscow),
+			want: time.Date(2024, 5, 11, 10, 0, 0, 0, moscow),
+		},
+		{
+			name: "после рассылки",
+			at:   10*time.Hour + 30*time.Minute,
+			now:  time.Date(2024, 5, 10, 23, 0, 0, 0, moscow),
+			want: time.Date(2024, 5, 11, 10, 30, 0, 0, moscow),
+		},
+		{
+			name: "конец месяца",
+			at:   10 * time.Hour,
+			now:  time.Date(2024, 12, 31, 12, 0, 0, 0, moscow),
+			want: time.Date(2025, 1, 1, 10, 0, 0, 0, moscow),
+		},
+		{
+			name: "переход на летнее время",
+			at:   10 * time.Hour,
+			now:  time.Date(2024, 3, 30, 12, 0, 0, 0, berlin),
+			want: time.Date(2024, 3, 31, 10, 0, 0, 0, berlin),
+		},
+		{
+			name: "переход на зимнее время",
+			at:   10 * time.Hour,
+			now:  time.Date(2024, 10, 26, 12, 0, 0, 0, berlin),
+			want: time.Date(2024, 10, 27, 10, 0, 0, 0, berlin),
+		},
+		{
+			name: "в день перехода до рассылки",
+			at:   10 * time.Hour,
+			now:  time.Date(2024, 3, 31, 1, 0, 0, 0, berlin),
+			want: time.Date(2024, 3, 31, 10, 0, 0, 0, berlin),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(nil, nil, Options{At: tt.at}, nil, zap.NewNop())
+			if got := s.Next(tt.now); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulerRemindOncePerDay(t *testing.T) {
+	moscow := mustLoadLocation(t, "Europe/Moscow")
+	now := time.Date(2024, 5, 10, 10, 0, 0, 0, moscow)
+
+	store := newFakeStore(map[int32][]inventory.Item{
+		1: {
+			{Name: "молоко", ExpiresAt: time.Date(2024, 5, 11, 23, 59, 59, 0, moscow)},
+			{Name: "сыр", ExpiresAt: time.Date(2024, 5, 20, 23, 59, 59, 0, moscow)},
+		},
+		2: {{Name: "хлеб"}},
+	})
+	rec := newRecorder()
+	s := NewScheduler(store, rec.notify, Options{At: 10 * time.Hour, Days: 2}, newFakeClock(now), zap.NewNop())
+	ctx := context.Background()
+
+	sent, err := s.Remind(ctx, now)
+	if err != nil || sent != 1 {
+		t.Fatalf("Remind() = %d, %v; want 1, nil", sent, err)
+	}
+	reminder := <-rec.sent
+	if reminder.UserID != 1 || len(reminder.Items) != 1 || reminder.Items[0].Name != "молоко" {
+		t.Errorf("reminder = %+v, want молоко for user 1", reminder)
+	}
+
+	// Повторный запуск в тот же день, например после перезапуска бота, ничего не отправляет
+	if sent, err := s.Remind(ctx, now.Add(8*time.Hour)); err != nil || sent != 0 {
+		t.Errorf("second Remind() the same day = %d, %v; want 0, nil", sent, err)
+	}
+
+	// На следующий день продукт все еще истекает, и напоминание приходит снова
+	if sent, err := s.Remind(ctx, now.Add(24*time.Hour)); err != nil || sent != 1 {
+		t.Errorf("Remind() next day = %d, %v; want 1, nil", sent, err)
+	}
+}
+
+func TestSchedulerRunCatchesUp(t *testing.T) {
+	moscow := mustLoadLocation(t, "Europe/Moscow")
+	// Бот запущен после времени рассылки: сегодняшнее напоминание отправляется сразу
+	clock := newFakeClock(time.Date(2024, 5, 10, 12, 0, 0, 0, moscow))
+	store := newFakeStore(map[int32][]inventory.Item{
+		1: {{Name: "молоко", ExpiresAt: time.Date(2024, 5, 11, 23, 59, 59, 0, moscow)}},
+	})
+	rec := newRecorder()
+	s := NewScheduler(store, rec.notify, Options{At: 10 * time.Hour, Days: 2}, clock, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	if reminder := receive(t, rec.sent); reminder.UserID != 1 {
+		t.Errorf("catch-up reminder for user %d, want 1", reminder.UserID)
+	}
+
+	timer := receiveTimer(t, clock.timers)
+	if timer.d != 22*time.Hour {
+		t.Errorf("wait after catch-up = %v, want 22h", timer.d)
+	}
+	clock.fire(timer)
+	receive(t, rec.sent)
+
+	timer = receiveTimer(t, clock.timers)
+	if timer.d != 24*time.Hour {
+		t.Errorf("wait after daily reminder = %v, want 24h", timer.d)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after context cancellation")
+	}
+}
+
+func TestSchedulerRunWaitsForSlot(t *testing.T) {
+	moscow := mustLoadLocation(t, "Europe/Moscow")
+	clock := newFakeClock(time.Date(2024, 5, 10, 8, 0, 0, 0, moscow))
+	store := newFakeStore(map[int32][]inventory.Item{
+		1: {{Name: "молоко", ExpiresAt: time.Date(2024, 5, 11, 23, 59, 59, 0, moscow)}},
+	})
+	rec := newRecorder()
+	s := NewScheduler(store, rec.notify, Options{At: 10 * time.Hour, Days: 2}, clock, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	// До времени рассылки бот ничего не отправляет, а ждет ее
+	timer := receiveTimer(t, clock.timers)
+	if timer.d != 2*time.Hour {
+		t.Errorf("wait before first reminder = %v, want 2h", timer.d)
+	}
+	select {
+	case reminder := <-rec.sent:
+		t.Fatalf("reminder %+v sent before its time", reminder)
+	default:
+	}
+
+	clock.fire(timer)
+	receive(t, rec.sent)
+}
+
+func receive(t *testing.T, ch <-chan Reminder) Reminder {
+	t.Helper()
+	select {
+	case reminder := <-ch:
+		return reminder
+	case <-time.After(time.Second):
+		t.Fatal("reminder was not sent")
+		return Reminder{}
+	}
+}
+
+func receiveTimer(t *testing.T, ch <-chan fakeTimer) fakeTimer {
+	t.Helper()
+	select {
+	case timer := <-ch:
+		return timer
+	case <-time.After(time.Second):
+		t.Fatal("scheduler is not waiting for the next reminder")
+		return fakeTimer{}
+	}
+}
@@ -0,0 +1,136 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrCircuitOpen возвращается, пока выключатель разомкнут
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State - состояние автоматического выключателя
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker - автоматический выключатель для одного провайдера.
+// После FailureThreshold ошибок подряд запросы отклоняются на OpenTimeout,
+// затем пропускается один пробный запрос: успех замыкает выключатель, ошибка снова размыкает.
+type Breaker struct {
+	mu        sync.Mutex
+	name      string
+	threshold int
+	cooldown  time.Duration
+	logger    *zap.Logger
+	now       func() time.Time
+
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreaker создает замкнутый выключатель
+func NewBreaker(name string, threshold int, cooldown time.Duration, logger *zap.Logger) *Breaker {
+	return &Breaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// State возвращает текущее состояние выключателя
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow проверяет, можно ли выполнить запрос
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		// Пока пробный запрос не завершился, остальные отклоняются
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record учитывает результат запроса
+func (b *Breaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.failures = 0
+		if b.state != StateClosed {
+			b.setState(StateClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		b.setState(StateOpen)
+	}
+}
+
+// Release завершает запрос, не учитывая его результат: ошибка не говорит ни о сбое,
+// ни о восстановлении провайдера. В полуоткрытом состоянии пропускается следующий пробный запрос.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// setState меняет состояние и пишет переход в лог. Вызывается под мьютексом.
+func (b *Breaker) setState(state State) {
+	if b.state == state {
+		return
+	}
+	b.logger.Warn("Circuit breaker state changed",
+		zap.String("breaker", b.name),
+		zap.Stringer("from", b.state),
+		zap.Stringer("to", state),
+		zap.Int("failures", b.failures))
+	b.state = state
+}
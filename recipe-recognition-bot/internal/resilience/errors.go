@@ -0,0 +1,90 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// StatusError - неуспешный HTTP-ответ бэкенда
+type StatusError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter - задержка из заголовка Retry-After, если он был
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("статус %d: %s", e.StatusCode, e.Body)
+}
+
+// NewStatusError создает ошибку по HTTP-ответу с уже прочитанным телом
+func NewStatusError(resp *http.Response, body []byte) *StatusError {
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// StatusCode извлекает HTTP-статус из ошибок поддерживаемых клиентов, 0 если статуса нет
+func StatusCode(err error) int {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	return 0
+}
+
+// IsRetryable сообщает, имеет ли смысл повторить запрос после ошибки
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+
+	switch status := StatusCode(err); {
+	case status == 0:
+		// Сетевые ошибки и таймауты отдельной попытки
+		return true
+	case status == http.StatusTooManyRequests, status == http.StatusRequestTimeout:
+		return true
+	default:
+		return status >= http.StatusInternalServerError
+	}
+}
+
+// ParseRetryAfter разбирает заголовок Retry-After в секундах или в формате HTTP-даты
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// retryAfter возвращает задержку, запрошенную сервером
+func retryAfter(err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
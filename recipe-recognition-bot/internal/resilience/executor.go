@@ -0,0 +1,124 @@
+package resilience
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Executor выполняет вызовы LLM с повторами, дедлайном попытки и автоматическим выключателем
+type Executor struct {
+	name    string
+	policy  Policy
+	breaker *Breaker
+	logger  *zap.Logger
+}
+
+// New создает исполнитель для одного провайдера
+func New(name string, policy Policy, logger *zap.Logger) *Executor {
+	policy = policy.withDefaults()
+	return &Executor{
+		name:    name,
+		policy:  policy,
+		breaker: NewBreaker(name, policy.FailureThreshold, policy.OpenTimeout, logger),
+		logger:  logger,
+	}
+}
+
+// State возвращает состояние выключателя провайдера
+func (e *Executor) State() State {
+	return e.breaker.State()
+}
+
+// Do выполняет fn, повторяя попытки при временных ошибках.
+// Каждая попытка получает собственный контекст с дедлайном CallTimeout.
+func (e *Executor) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	var err error
+
+	for attempt := 1; attempt <= e.policy.MaxAttempts; attempt++ {
+		if err = e.breaker.Allow(); err != nil {
+			e.logger.Warn("LLM call rejected by circuit breaker",
+				zap.String("executor", e.name),
+				zap.Stringer("breaker_state", e.breaker.State()))
+			return err
+		}
+
+		hint := &retryHint{}
+		err = e.call(ctx, hint, fn)
+
+		if err == nil {
+			e.breaker.Record(true)
+			return nil
+		}
+
+		retryable := IsRetryable(err) && ctx.Err() == nil
+		if retryable {
+			e.breaker.Record(false)
+		} else {
+			// Ошибки клиента (4xx) и отмена запроса вызывающим не говорят о состоянии провайдера
+			e.breaker.Release()
+		}
+
+		e.logger.Warn("LLM call failed",
+			zap.String("executor", e.name),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", e.policy.MaxAttempts),
+			zap.Int("status", StatusCode(err)),
+			zap.Bool("retryable", retryable),
+			zap.Stringer("breaker_state", e.breaker.State()),
+			zap.Error(err))
+
+		if !retryable || attempt == e.policy.MaxAttempts {
+			return err
+		}
+
+		delay := e.backoff(attempt)
+		if serverDelay := max(retryAfter(err), hint.get()); serverDelay > 0 {
+			if serverDelay > e.policy.MaxDelay {
+				e.logger.Warn("Retry-After exceeds max delay, giving up",
+					zap.String("executor", e.name), zap.Duration("retry_after", serverDelay))
+				return err
+			}
+			delay = max(delay, serverDelay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return err
+}
+
+// call выполняет одну попытку с дедлайном CallTimeout. Если fn паникует, выключатель
+// освобождается: иначе незавершенный пробный запрос отклонял бы все следующие.
+func (e *Executor) call(ctx context.Context, hint *retryHint, fn func(ctx context.Context) error) error {
+	callCtx, cancel := context.WithTimeout(withHint(ctx, hint), e.policy.CallTimeout)
+	defer cancel()
+
+	returned := false
+	defer func() {
+		if !returned {
+			e.breaker.Release()
+		}
+	}()
+
+	err := fn(callCtx)
+	returned = true
+	return err
+}
+
+// backoff вычисляет экспоненциальную задержку с полным джиттером
+func (e *Executor) backoff(attempt int) time.Duration {
+	ceiling := e.policy.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > e.policy.MaxDelay {
+		ceiling = e.policy.MaxDelay
+	}
+	return time.Duration(rand.Int64N(int64(ceiling)) + 1)
+}
@@ -0,0 +1,135 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func newTestExecutor(threshold int) *Executor {
+	return New("test", Policy{
+		MaxAttempts:      1,
+		BaseDelay:        time.Millisecond,
+		MaxDelay:         time.Millisecond,
+		FailureThreshold: threshold,
+		OpenTimeout:      time.Minute,
+	}, zap.NewNop())
+}
+
+// openBreaker размыкает выключатель и переводит его часы за OpenTimeout,
+// чтобы следующий запрос стал пробным
+func openBreaker(t *testing.T, e *Executor) {
+	t.Helper()
+	serverErr := &StatusError{StatusCode: http.StatusBadGateway}
+	for e.State() != StateOpen {
+		e.Do(context.Background(), func(ctx context.Context) error { return serverErr })
+	}
+	opened := time.Now()
+	e.breaker.now = func() time.Time { return opened.Add(2 * time.Minute) }
+}
+
+func TestExecutorOpensOnServerErrors(t *testing.T) {
+	e := newTestExecutor(2)
+	serverErr := &StatusError{StatusCode: http.StatusServiceUnavailable}
+
+	for i := 0; i < 2; i++ {
+		if err := e.Do(context.Background(), func(ctx context.Context) error { return serverErr }); !errors.Is(err, serverErr) {
+			t.Fatalf("Do() error = %v, want %v", err, serverErr)
+		}
+	}
+	if e.State() != StateOpen {
+		t.Fatalf("state = %v, want open", e.State())
+	}
+	if err := e.Do(context.Background(), func(ctx context.Context) error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Do() on open breaker error = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestExecutorIgnoresNonProviderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  func() context.Context
+		err  error
+	}{
+		{
+			name: "ошибка клиента",
+			ctx:  context.Background,
+			err:  &StatusError{StatusCode: http.StatusBadRequest},
+		},
+		{
+			name: "запрос отменен вызывающим",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			err: &StatusError{StatusCode: http.StatusBadGateway},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestExecutor(2)
+			for i := 0; i < 5; i++ {
+				e.Do(tt.ctx(), func(ctx context.Context) error { return tt.err })
+			}
+			if e.State() != StateClosed {
+				t.Errorf("state = %v, want closed", e.State())
+			}
+
+			// Не засчитанные ошибки не обнуляют и счетчик ошибок провайдера
+			e.breaker.Record(false)
+			e.Do(tt.ctx(), func(ctx context.Context) error { return tt.err })
+			e.breaker.Record(false)
+			if e.State() != StateOpen {
+				t.Errorf("state = %v, want open after provider failures", e.State())
+			}
+		})
+	}
+}
+
+func TestExecutorProbeWithClientErrorKeepsHalfOpen(t *testing.T) {
+	e := newTestExecutor(1)
+	openBreaker(t, e)
+
+	clientErr := &StatusError{StatusCode: http.StatusBadRequest}
+	if err := e.Do(context.Background(), func(ctx context.Context) error { return clientErr }); !errors.Is(err, clientErr) {
+		t.Fatalf("probe error = %v, want %v", err, clientErr)
+	}
+	if e.State() != StateHalfOpen {
+		t.Fatalf("state = %v, want half-open", e.State())
+	}
+
+	// Следующий запрос снова пробный, и его успех замыкает выключатель
+	if err := e.Do(context.Background(), func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatalf("second probe error = %v", err)
+	}
+	if e.State() != StateClosed {
+		t.Errorf("state = %v, want closed", e.State())
+	}
+}
+
+func TestExecutorPanicReleasesProbe(t *testing.T) {
+	e := newTestExecutor(1)
+	openBreaker(t, e)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic was not propagated")
+			}
+		}()
+		e.Do(context.Background(), func(ctx context.Context) error { panic("boom") })
+	}()
+
+	if err := e.Do(context.Background(), func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatalf("Do() after panic error = %v, want probe to be allowed", err)
+	}
+	if e.State() != StateClosed {
+		t.Errorf("state = %v, want closed", e.State())
+	}
+}
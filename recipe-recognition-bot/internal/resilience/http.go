@@ -0,0 +1,70 @@
+package resilience
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HTTPDoer совместим с http.Client и openai.HTTPDoer
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// HTTPClient запоминает заголовок Retry-After ответов, чтобы Executor мог его учесть
+// даже когда клиентская библиотека не пробрасывает заголовки в ошибку.
+type HTTPClient struct {
+	doer HTTPDoer
+}
+
+// NewHTTPClient оборачивает HTTP-клиент
+func NewHTTPClient(doer HTTPDoer) *HTTPClient {
+	if doer == nil {
+		doer = &http.Client{}
+	}
+	return &HTTPClient{doer: doer}
+}
+
+func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if h := hintFromContext(req.Context()); h != nil {
+		if delay := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); delay > 0 {
+			h.set(delay)
+		}
+	}
+	return resp, nil
+}
+
+// retryHint передает Retry-After от HTTPClient к Executor через контекст попытки
+type retryHint struct {
+	mu    sync.Mutex
+	delay time.Duration
+}
+
+func (h *retryHint) set(delay time.Duration) {
+	h.mu.Lock()
+	h.delay = delay
+	h.mu.Unlock()
+}
+
+func (h *retryHint) get() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.delay
+}
+
+type hintKey struct{}
+
+func withHint(ctx context.Context, h *retryHint) context.Context {
+	return context.WithValue(ctx, hintKey{}, h)
+}
+
+func hintFromContext(ctx context.Context) *retryHint {
+	h, _ := ctx.Value(hintKey{}).(*retryHint)
+	return h
+}
@@ -0,0 +1,54 @@
+package resilience
+
+import "time"
+
+// Policy описывает параметры повторов, таймаутов и автоматического выключателя
+type Policy struct {
+	// MaxAttempts - максимальное число попыток, включая первую
+	MaxAttempts int
+	// BaseDelay и MaxDelay ограничивают экспоненциальную задержку между попытками
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// CallTimeout - дедлайн одной попытки
+	CallTimeout time.Duration
+	// FailureThreshold - число ошибок подряд, после которого выключатель размыкается
+	FailureThreshold int
+	// OpenTimeout - время в разомкнутом состоянии до пробного запроса
+	OpenTimeout time.Duration
+}
+
+// DefaultPolicy возвращает параметры по умолчанию
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:      3,
+		BaseDelay:        500 * time.Millisecond,
+		MaxDelay:         10 * time.Second,
+		CallTimeout:      60 * time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}
+}
+
+// withDefaults заполняет незаданные поля значениями по умолчанию
+func (p Policy) withDefaults() Policy {
+	def := DefaultPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = def.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = def.MaxDelay
+	}
+	if p.CallTimeout <= 0 {
+		p.CallTimeout = def.CallTimeout
+	}
+	if p.FailureThreshold <= 0 {
+		p.FailureThreshold = def.FailureThreshold
+	}
+	if p.OpenTimeout <= 0 {
+		p.OpenTimeout = def.OpenTimeout
+	}
+	return p
+}
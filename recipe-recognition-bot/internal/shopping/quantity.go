@@ -0,0 +1,107 @@
+package shopping
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/inventory"
+)
+
+// separator разделяет в списке количества, которые не сложить: "2 шт + 200 г"
+const separator = " + "
+
+// countedPattern - число с произвольной единицей, которую не знает inventory: "2 ст. л.", "3 зубчика"
+var countedPattern = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)\s*(.+)$`)
+
+// portion - одно слагаемое количества
+type portion struct {
+	// amount - количество в известных единицах: складывается с любым количеством той же величины
+	amount   inventory.Amount
+	measured bool
+	// value и unit - число с неизвестной единицей: складывается только с той же единицей
+	value float64
+	unit  string
+	// text - количество без числа: "по вкусу"
+	text string
+}
+
+func parsePortion(text string) portion {
+	if amount, ok := inventory.ParseAmount(text); ok {
+		return portion{amount: amount, measured: true}
+	}
+	if m := countedPattern.FindStringSubmatch(text); m != nil {
+		if value, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64); err == nil {
+			return portion{value: value, unit: strings.ToLower(m[2])}
+		}
+	}
+	return portion{text: strings.ToLower(text)}
+}
+
+// add прибавляет слагаемое той же единицы; false - единицы разные
+func (p *portion) add(other portion) bool {
+	switch {
+	case p.measured && other.measured:
+		sum, ok := p.amount.Add(other.amount)
+		if ok {
+			p.amount = sum
+		}
+		return ok
+	case p.measured || other.measured:
+		return false
+	case p.unit != "" && p.unit == other.unit:
+		p.value += other.value
+		return true
+	default:
+		return p.unit == "" && other.unit == "" && p.text == other.text
+	}
+}
+
+func (p portion) String() string {
+	switch {
+	case p.measured:
+		return p.amount.String()
+	case p.unit != "":
+		return inventory.FormatNumber(p.value) + " " + p.unit
+	default:
+		return p.text
+	}
+}
+
+// MergeQuantity складывает количества одного продукта. Количества одной величины суммируются
+// в единицах первого слагаемого ("1 л" и "500 мл" - "1,5 л"), разные перечисляются через "+".
+func MergeQuantity(a, b string) string {
+	var portions []portion
+	for _, part := range splitPortions(a, b) {
+		p := parsePortion(part)
+		merged := false
+		for i := range portions {
+			if portions[i].add(p) {
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			portions = append(portions, p)
+		}
+	}
+
+	parts := make([]string, 0, len(portions))
+	for _, p := range portions {
+		parts = append(parts, p.String())
+	}
+	return strings.Join(parts, separator)
+}
+
+// splitPortions разбивает количества на слагаемые, пропуская пустые
+func splitPortions(quantities ...string) []string {
+	var parts []string
+	for _, quantity := range quantities {
+		for _, part := range strings.Split(quantity, separator) {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+	return parts
+}
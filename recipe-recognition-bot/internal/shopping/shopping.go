@@ -0,0 +1,97 @@
+package shopping
+
+import (
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/inventory"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/recipes"
+)
+
+// Item - продукт в списке покупок
+type Item struct {
+	ID   int64
+	Name string
+	// Quantity - сколько купить: сумма количеств из рецептов, "1,5 л", "2 шт + 200 г"
+	Quantity string
+	// Checked - продукт куплен
+	Checked bool
+}
+
+// Missing возвращает ингредиенты рецепта, которых нет дома. Запасы пользователя (соль, масло)
+// считаются всегда доступными. Если продукт дома есть, но его меньше, чем нужно по рецепту,
+// покупается разница; если количества сравнить нельзя, продукта считается достаточно.
+// Необязательные ингредиенты в список не попадают.
+func Missing(used []recipes.Ingredient, staples []string, have []inventory.Item) []Item {
+	stapleKeys := make(map[string]bool, len(staples))
+	for _, staple := range staples {
+		stapleKeys[ingredients.Key(staple)] = true
+	}
+	available := make(map[string]string, len(have))
+	for _, item := range have {
+		available[ingredients.Key(item.Name)] = item.Quantity
+	}
+
+	var missing []Item
+	for _, ingredient := range used {
+		key := ingredients.Key(ingredient.Name)
+		if key == "" || ingredient.Optional || stapleKeys[key] {
+			continue
+		}
+
+		quantity := ingredient.Amount()
+		if haveQuantity, ok := available[key]; ok {
+			var lacking bool
+			if quantity, lacking = shortage(haveQuantity, quantity); !lacking {
+				continue
+			}
+		}
+		missing = append(missing, Item{Name: ingredient.Name, Quantity: quantity})
+	}
+	return Merge(nil, missing)
+}
+
+// shortage - сколько продукта не хватает до нужного количества; false - хватает
+func shortage(have, need string) (string, bool) {
+	haveAmount, ok := inventory.ParseAmount(have)
+	if !ok {
+		return "", false
+	}
+	needAmount, ok := inventory.ParseAmount(need)
+	if !ok {
+		return "", false
+	}
+	left, ok := needAmount.Sub(haveAmount)
+	if !ok || left.Value <= 0 {
+		return "", false
+	}
+	return left.String(), true
+}
+
+// Merge добавляет продукты в список и возвращает продукты, которые нужно сохранить.
+// Некупленный продукт, уже бывший в списке, получает сумму количеств; купленный
+// снова становится некупленным с новым количеством. Повторы в added тоже объединяются.
+func Merge(list []Item, added []Item) []Item {
+	existing := make(map[string]Item, len(list))
+	for _, item := range list {
+		existing[ingredients.Key(item.Name)] = item
+	}
+
+	index := make(map[string]int, len(added))
+	var merged []Item
+	for _, item := range added {
+		key := ingredients.Key(item.Name)
+		if key == "" {
+			continue
+		}
+		if i, ok := index[key]; ok {
+			merged[i].Quantity = MergeQuantity(merged[i].Quantity, item.Quantity)
+			continue
+		}
+
+		if old, ok := existing[key]; ok && !old.Checked {
+			item = Item{ID: old.ID, Name: old.Name, Quantity: MergeQuantity(old.Quantity, item.Quantity)}
+		}
+		index[key] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged
+}
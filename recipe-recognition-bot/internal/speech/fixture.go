@@ -0,0 +1,47 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+)
+
+// defaultFixtureText возвращается фикстурным бэкендом, если текст не задан
+const defaultFixtureText = "яйца, помидоры, сыр"
+
+func init() {
+	Register("fixture", func(opts Options, logger *zap.Logger) (Transcriber, error) {
+		return NewFixtureTranscriber(opts.FixtureText), nil
+	})
+}
+
+// FixtureTranscriber - детерминированный бэкенд без сетевых запросов для тестов и локальной отладки
+type FixtureTranscriber struct {
+	text string
+	err  error
+}
+
+// NewFixtureTranscriber создает бэкенд, всегда возвращающий заданный текст
+func NewFixtureTranscriber(text string) *FixtureTranscriber {
+	if text == "" {
+		text = defaultFixtureText
+	}
+	return &FixtureTranscriber{text: text}
+}
+
+// NewFailingFixtureTranscriber создает бэкенд, всегда возвращающий указанную ошибку
+func NewFailingFixtureTranscriber(err error) *FixtureTranscriber {
+	return &FixtureTranscriber{err: err}
+}
+
+func (f *FixtureTranscriber) Transcribe(ctx context.Context, audio io.Reader, filename string) (string, error) {
+	if _, err := io.Copy(io.Discard, audio); err != nil {
+		return "", fmt.Errorf("failed to read audio: %w", err)
+	}
+	if f.err != nil {
+		return "", f.err
+	}
+	return f.text, nil
+}
@@ -0,0 +1,103 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/resilience"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = openai.Whisper1
+	defaultLanguage      = "ru"
+)
+
+// transcriptionPrompt подсказывает модели словарь: речь идет о продуктах
+const transcriptionPrompt = "Список продуктов: яйца, помидоры, сыр, молоко, курица."
+
+func init() {
+	Register("openai", func(opts Options, logger *zap.Logger) (Transcriber, error) {
+		return NewOpenAITranscriber(opts, logger), nil
+	})
+}
+
+// OpenAITranscriber распознает речь через OpenAI-совместимый эндпоинт /audio/transcriptions (Whisper)
+type OpenAITranscriber struct {
+	client   *openai.Client
+	executor *resilience.Executor
+	logger   *zap.Logger
+	model    string
+	language string
+}
+
+// NewOpenAITranscriber создает бэкенд распознавания речи
+func NewOpenAITranscriber(opts Options, logger *zap.Logger) *OpenAITranscriber {
+	config := openai.DefaultConfig(opts.APIKey)
+	config.BaseURL = defaultOpenAIBaseURL
+	if opts.BaseURL != "" {
+		config.BaseURL = opts.BaseURL
+	}
+	config.HTTPClient = resilience.NewHTTPClient(&http.Client{})
+
+	model := opts.Model
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+
+	language := opts.Language
+	if language == "" {
+		language = defaultLanguage
+	}
+
+	return &OpenAITranscriber{
+		client:   openai.NewClientWithConfig(config),
+		executor: resilience.New("speech/openai/"+model, opts.Resilience, logger),
+		logger:   logger,
+		model:    model,
+		language: language,
+	}
+}
+
+func (o *OpenAITranscriber) Transcribe(ctx context.Context, audio io.Reader, filename string) (string, error) {
+	// Запись читается целиком: при повторной попытке тело запроса формируется заново
+	data, err := io.ReadAll(audio)
+	if err != nil {
+		return "", fmt.Errorf("failed to read audio: %w", err)
+	}
+
+	var resp openai.AudioResponse
+	err = o.executor.Do(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = o.client.CreateTranscription(ctx, openai.AudioRequest{
+			Model:    o.model,
+			FilePath: filename,
+			Reader:   bytes.NewReader(data),
+			Prompt:   transcriptionPrompt,
+			Language: o.language,
+			Format:   openai.AudioResponseFormatJSON,
+		})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("transcription failed: %w", err)
+	}
+
+	text := strings.TrimSpace(resp.Text)
+	o.logger.Debug("Voice transcribed",
+		zap.String("model", o.model),
+		zap.Int("audio_bytes", len(data)),
+		zap.Int("text_length", len(text)))
+
+	if text == "" {
+		return "", fmt.Errorf("empty transcription")
+	}
+	return text, nil
+}
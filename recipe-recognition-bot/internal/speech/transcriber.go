@@ -0,0 +1,75 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/resilience"
+)
+
+// Transcriber переводит голосовое сообщение в текст
+type Transcriber interface {
+	// Transcribe распознает речь; filename передает формат записи, например "voice.ogg"
+	Transcribe(ctx context.Context, audio io.Reader, filename string) (string, error)
+}
+
+// Options содержит параметры подключения к бэкенду распознавания речи.
+// Пустые значения заменяются значениями по умолчанию конкретного бэкенда.
+type Options struct {
+	APIKey  string
+	BaseURL string
+	Model   string
+	// Language - язык речи в формате ISO-639-1, подсказка для модели
+	Language    string
+	FixtureText string
+	Resilience  resilience.Policy
+}
+
+// Factory создает бэкенд распознавания речи по параметрам
+type Factory func(opts Options, logger *zap.Logger) (Transcriber, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register регистрирует бэкенд распознавания речи под указанным именем
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("speech: backend %q already registered", name))
+	}
+	registry[name] = factory
+}
+
+// Providers возвращает отсортированный список зарегистрированных бэкендов
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New создает бэкенд распознавания речи по имени провайдера
+func New(provider string, opts Options, logger *zap.Logger) (Transcriber, error) {
+	registryMu.RLock()
+	factory, ok := registry[provider]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown speech provider %q (available: %v)", provider, Providers())
+	}
+	return factory(opts, logger)
+}
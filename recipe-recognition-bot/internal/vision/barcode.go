@@ -0,0 +1,89 @@
+package vision
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/barcode"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/imageprep"
+)
+
+// ProductCatalog сопоставляет штрихкоды названиям продуктов
+type ProductCatalog interface {
+	// ProductNames возвращает названия известных товаров; неизвестных штрихкодов в результате нет
+	ProductNames(ctx context.Context, barcodes []string) (map[string]string, error)
+}
+
+// BarcodeRecognizer ищет на фото штрихкоды EAN-13 и UPC-A и добавляет к распознанным продуктам
+// товары из справочника: на упаковке штрихкод часто читается надежнее этикетки.
+// Изображение сканируется до уменьшения, чтобы не потерять тонкие полосы.
+type BarcodeRecognizer struct {
+	next    Recognizer
+	catalog ProductCatalog
+	logger  *zap.Logger
+}
+
+// NewBarcodeRecognizer оборачивает бэкенд распознавания поиском штрихкодов
+func NewBarcodeRecognizer(next Recognizer, catalog ProductCatalog, logger *zap.Logger) *BarcodeRecognizer {
+	return &BarcodeRecognizer{next: next, catalog: catalog, logger: logger}
+}
+
+func (b *BarcodeRecognizer) RecognizeProductsFromImage(ctx context.Context, imageData io.Reader) (*RecognizedItems, error) {
+	data, err := io.ReadAll(imageData)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения изображения: %w", err)
+	}
+
+	scanned := b.scan(ctx, data)
+
+	recognized, err := b.next.RecognizeProductsFromImage(ctx, bytes.NewReader(data))
+	if err != nil {
+		if len(scanned) == 0 {
+			return nil, err
+		}
+		b.logger.Warn("Recognition failed, using barcodes only", zap.Int("items", len(scanned)), zap.Error(err))
+		return &RecognizedItems{Items: scanned}, nil
+	}
+
+	// Товары по штрихкодам идут первыми: их названия точнее догадок модели
+	recognized.Items = MergeItems(scanned, recognized.Items)
+	return recognized, nil
+}
+
+// scan находит штрихкоды и возвращает известные справочнику товары.
+// Ошибки не мешают распознаванию: фото без кодов обычное дело.
+func (b *BarcodeRecognizer) scan(ctx context.Context, data []byte) []Item {
+	img, err := imageprep.Decode(data)
+	if err != nil {
+		if !errors.Is(err, imageprep.ErrUndecodable) {
+			b.logger.Warn("Failed to decode image for barcode scan", zap.Error(err))
+		}
+		return nil
+	}
+
+	codes := barcode.Scan(img)
+	if len(codes) == 0 {
+		return nil
+	}
+
+	names, err := b.catalog.ProductNames(ctx, codes)
+	if err != nil {
+		b.logger.Warn("Product catalog lookup failed", zap.Strings("barcodes", codes), zap.Error(err))
+		return nil
+	}
+
+	var items []Item
+	for _, code := range codes {
+		if name, ok := names[code]; ok {
+			items = append(items, Item{Name: name, Confidence: 1})
+		}
+	}
+	b.logger.Info("Barcodes scanned",
+		zap.Strings("barcodes", codes), zap.Int("known", len(items)))
+	return items
+}
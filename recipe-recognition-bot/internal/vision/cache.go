@@ -0,0 +1,121 @@
+package vision
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/imageprep"
+)
+
+// RecognitionCache хранит результаты распознавания по перцептивному хешу изображения
+type RecognitionCache interface {
+	// FindRecognition возвращает продукты ближайшего неистекшего изображения,
+	// если его хеш отличается не больше чем на maxDistance бит
+	FindRecognition(ctx context.Context, hash uint64, maxDistance int) ([]Item, bool, error)
+	SaveRecognition(ctx context.Context, hash uint64, items []Item, ttl time.Duration) error
+	DeleteExpiredRecognitions(ctx context.Context) (int64, error)
+}
+
+// CacheOptions - параметры кэша распознавания
+type CacheOptions struct {
+	TTL time.Duration
+	// MaxDistance - сколько бит из 64 могут различаться у хешей "одного и того же" фото
+	MaxDistance int
+}
+
+// CachingRecognizer не обращается к модели, если такое же или почти такое же фото уже распознавалось.
+// Ошибки кэша не мешают распознаванию: при них запрос уходит модели.
+type CachingRecognizer struct {
+	next   Recognizer
+	cache  RecognitionCache
+	opts   CacheOptions
+	logger *zap.Logger
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewCachingRecognizer оборачивает бэкенд распознавания кэшем по перцептивному хешу
+func NewCachingRecognizer(next Recognizer, cache RecognitionCache, opts CacheOptions, logger *zap.Logger) *CachingRecognizer {
+	return &CachingRecognizer{next: next, cache: cache, opts: opts, logger: logger}
+}
+
+// Stats возвращает число попаданий и промахов кэша с момента запуска
+func (c *CachingRecognizer) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+func (c *CachingRecognizer) RecognizeProductsFromImage(ctx context.Context, imageData io.Reader) (*RecognizedItems, error) {
+	data, err := io.ReadAll(imageData)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения изображения: %w", err)
+	}
+
+	hash, err := imageprep.DHashBytes(data)
+	if err != nil {
+		if !errors.Is(err, imageprep.ErrUndecodable) {
+			c.logger.Warn("Failed to hash image", zap.Error(err))
+		}
+		return c.next.RecognizeProductsFromImage(ctx, bytes.NewReader(data))
+	}
+
+	items, found, err := c.cache.FindRecognition(ctx, hash, c.opts.MaxDistance)
+	if err != nil {
+		c.logger.Warn("Recognition cache lookup failed", zap.Error(err))
+	}
+	if found {
+		c.hits.Add(1)
+		c.logStats("Recognition cache hit", hash)
+		return &RecognizedItems{Items: items}, nil
+	}
+
+	c.misses.Add(1)
+	c.logStats("Recognition cache miss", hash)
+
+	recognized, err := c.next.RecognizeProductsFromImage(ctx, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.cache.SaveRecognition(ctx, hash, recognized.Items, c.opts.TTL); err != nil {
+		c.logger.Warn("Failed to cache recognition", zap.Error(err))
+	}
+	return recognized, nil
+}
+
+// Run периодически удаляет истекшие записи кэша до отмены контекста
+func (c *CachingRecognizer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := c.cache.DeleteExpiredRecognitions(ctx)
+			if err != nil {
+				c.logger.Warn("Failed to delete expired recognitions", zap.Error(err))
+				continue
+			}
+			if removed > 0 {
+				c.logger.Debug("Expired recognitions deleted", zap.Int64("count", removed))
+			}
+		}
+	}
+}
+
+func (c *CachingRecognizer) logStats(msg string, hash uint64) {
+	hits, misses := c.Stats()
+	c.logger.Info(msg,
+		zap.String("image_hash", fmt.Sprintf("%016x", hash)),
+		zap.Int64("hits", hits),
+		zap.Int64("misses", misses))
+}
@@ -0,0 +1,57 @@
+package vision
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrUnsupportedImage - данные не являются изображением в формате, который понимают модели
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// supportedImageTypes - форматы, которые принимают OpenAI-совместимые и локальные модели
+var supportedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+	"image/gif":  true,
+}
+
+// heicBrands - марки контейнера ISO BMFF, которыми помечаются HEIC/HEIF-фото
+var heicBrands = [][]byte{
+	[]byte("heic"), []byte("heix"), []byte("hevc"), []byte("heim"),
+	[]byte("heis"), []byte("mif1"), []byte("msf1"),
+}
+
+// DetectImageType определяет MIME-тип изображения по содержимому, не доверяя имени файла и заявленному типу.
+// Для форматов, которые модели не принимают (в том числе HEIC), возвращает ErrUnsupportedImage.
+func DetectImageType(data []byte) (string, error) {
+	if isHEIC(data) {
+		return "image/heic", fmt.Errorf("%w: image/heic", ErrUnsupportedImage)
+	}
+
+	mimeType := http.DetectContentType(data)
+	if !supportedImageTypes[mimeType] {
+		return mimeType, fmt.Errorf("%w: %s", ErrUnsupportedImage, mimeType)
+	}
+	return mimeType, nil
+}
+
+// isHEIC проверяет заголовок ftyp контейнера ISO BMFF
+func isHEIC(data []byte) bool {
+	if len(data) < 12 || !bytes.Equal(data[4:8], []byte("ftyp")) {
+		return false
+	}
+	for _, brand := range heicBrands {
+		if bytes.Equal(data[8:12], brand) {
+			return true
+		}
+	}
+	return false
+}
+
+// dataURL кодирует изображение для передачи в поле image_url
+func dataURL(mimeType string, base64Data string) string {
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64Data)
+}
@@ -0,0 +1,117 @@
+package vision
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+)
+
+// RecognizedItems - продукты, распознанные на изображении
+type RecognizedItems struct {
+	Items []Item `json:"items" llm:"required"`
+}
+
+// Names возвращает названия распознанных продуктов
+func (r *RecognizedItems) Names() []string {
+	names := make([]string, 0, len(r.Items))
+	for _, item := range r.Items {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+// Item - распознанный продукт
+type Item struct {
+	Name string `json:"name"`
+	// Confidence - уверенность модели от 0 до 1; 0 - модель уверенность не указала
+	Confidence float64 `json:"confidence,omitempty" description:"from 0 to 1"`
+	// Quantity - примерное количество в свободной форме: "6 шт", "около 500 г"
+	Quantity string `json:"quantity,omitempty"`
+	// Box - где продукт находится на изображении, если модель это указала
+	Box *Box `json:"box,omitempty"`
+}
+
+// Box - рамка продукта в долях ширины и высоты изображения, отсчитываемых от левого верхнего угла
+type Box struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// UnmarshalJSON принимает и объект, и просто название продукта:
+// так отвечают модели, игнорирующие формат, и так хранятся старые записи кэша
+func (i *Item) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*i = Item{Name: name}
+		return nil
+	}
+
+	type plain Item
+	return json.Unmarshal(data, (*plain)(i))
+}
+
+// Uncertain сообщает, что модель указала уверенность ниже порога
+func (i Item) Uncertain(threshold float64) bool {
+	return i.Confidence > 0 && i.Confidence < threshold
+}
+
+// normalize приводит поля ответа модели к допустимым значениям
+func (i *Item) normalize() {
+	// Часть моделей указывает уверенность в процентах
+	if i.Confidence > 1 {
+		i.Confidence /= 100
+	}
+	i.Confidence = clamp(i.Confidence)
+	i.Quantity = strings.TrimSpace(i.Quantity)
+
+	if i.Box == nil {
+		return
+	}
+	x, y := clamp(i.Box.X), clamp(i.Box.Y)
+	width, height := clamp(i.Box.X+i.Box.Width)-x, clamp(i.Box.Y+i.Box.Height)-y
+	if width <= 0 || height <= 0 {
+		i.Box = nil
+		return
+	}
+	i.Box = &Box{X: x, Y: y, Width: width, Height: height}
+}
+
+func clamp(v float64) float64 {
+	return min(max(v, 0), 1)
+}
+
+// MergeItems объединяет списки продуктов с нескольких изображений. Одинаковые с учетом Key
+// продукты сливаются в один с наибольшей уверенностью; рамка остается от первого изображения.
+func MergeItems(lists ...[]Item) []Item {
+	index := make(map[string]int)
+	var merged []Item
+
+	for _, list := range lists {
+		for _, item := range list {
+			item.Name = ingredients.Normalize(item.Name)
+			if !ingredients.Valid(item.Name) {
+				continue
+			}
+
+			key := ingredients.Key(item.Name)
+			i, seen := index[key]
+			if !seen {
+				index[key] = len(merged)
+				merged = append(merged, item)
+				continue
+			}
+
+			// Уверенность 0 означает "не указана" и не должна понижать известную
+			if merged[i].Confidence > 0 && item.Confidence > merged[i].Confidence {
+				merged[i].Confidence = item.Confidence
+			}
+			if merged[i].Quantity == "" {
+				merged[i].Quantity = item.Quantity
+			}
+		}
+	}
+	return merged
+}
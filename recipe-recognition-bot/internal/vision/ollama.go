@@ -0,0 +1,157 @@
+package vision
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/resilience"
+)
+
+const (
+	defaultOllamaBaseURL = "http://localhost:11434"
+	defaultOllamaModel   = "llava"
+)
+
+func init() {
+	Register("ollama", func(opts Options, logger *zap.Logger) (Recognizer, error) {
+		return NewOllamaVision(opts, logger), nil
+	})
+}
+
+// OllamaVision распознает продукты через локальный HTTP API в стиле Ollama (/api/chat)
+type OllamaVision struct {
+	httpClient *resilience.HTTPClient
+	executor   *resilience.Executor
+	logger     *zap.Logger
+	baseURL    string
+	model      string
+	maxTokens  int
+	structured bool
+}
+
+type ollamaMessage struct {
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"`
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   json.RawMessage `json:"format,omitempty"`
+	Options  map[string]any  `json:"options,omitempty"`
+}
+
+type ollamaChatResponse struct {
+	Message ollamaMessage `json:"message"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// NewOllamaVision создает клиент локального бэкенда распознавания
+func NewOllamaVision(opts Options, logger *zap.Logger) *OllamaVision {
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = defaultOllamaBaseURL
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = defaultOllamaModel
+	}
+
+	maxTokens := opts.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
+
+	return &OllamaVision{
+		httpClient: resilience.NewHTTPClient(&http.Client{}),
+		executor:   resilience.New("vision/ollama/"+model, opts.Resilience, logger),
+		logger:     logger,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		model:      model,
+		maxTokens:  maxTokens,
+		structured: opts.StructuredOutput,
+	}
+}
+
+func (o *OllamaVision) RecognizeProductsFromImage(ctx context.Context, imageData io.Reader) (*RecognizedItems, error) {
+	data, err := io.ReadAll(imageData)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения изображения: %w", err)
+	}
+	// Ollama принимает изображения без MIME-типа, но неподдерживаемый формат лучше отсечь до запроса
+	if _, err := DetectImageType(data); err != nil {
+		return nil, err
+	}
+
+	var format json.RawMessage
+	if o.structured {
+		format = itemsSchema.JSON()
+	}
+
+	body, err := json.Marshal(ollamaChatRequest{
+		Model:  o.model,
+		Format: format,
+		Messages: []ollamaMessage{
+			{
+				Role:    "user",
+				Content: recognitionPrompt,
+				Images:  []string{base64.StdEncoding.EncodeToString(data)},
+			},
+		},
+		Options: map[string]any{"num_predict": o.maxTokens},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	o.logger.Debug("Отправка изображения в локальную модель",
+		zap.String("model", o.model), zap.Int("size", len(data)))
+
+	var respBody []byte
+	err = o.executor.Do(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/api/chat", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := o.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		respBody, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return resilience.NewStatusError(resp, respBody)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к локальной модели: %w", err)
+	}
+
+	var chatResp ollamaChatResponse
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		return nil, fmt.Errorf("ошибка разбора ответа локальной модели: %w", err)
+	}
+	if chatResp.Error != "" {
+		return nil, fmt.Errorf("ошибка локальной модели: %s", chatResp.Error)
+	}
+
+	return parseRecognizedItems(chatResp.Message.Content, o.logger)
+}
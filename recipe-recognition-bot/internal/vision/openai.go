@@ -3,119 +3,135 @@ package vision
 import (
 	"context"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"io"
-	"log"
-	"strings"
+	"net/http"
 
 	"github.com/sashabaranov/go-openai"
 	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/resilience"
 )
 
-type OpenAIVision struct {
-	client *openai.Client
-	logger *zap.Logger
+const (
+	defaultOpenAIBaseURL = "https://openrouter.ai/api/v1"
+	defaultOpenAIModel   = "qwen/qwen-2.5-vl-7b-instruct:free"
+	// Рамки и уверенность занимают несколько десятков токенов на каждый продукт
+	defaultMaxTokens = 800
+)
+
+// recognitionPrompt - общий для всех бэкендов запрос на распознавание.
+// Промт такой потому, что слишком много продуктов зацикливают нейросеть
+const recognitionPrompt = `List all food products in this image. 
+Return only JSON: {"items": [{"name": "product1", "confidence": 0.9, "quantity": "6 pcs", "box": {"x": 0.1, "y": 0.2, "width": 0.3, "height": 0.25}}]}.
+confidence is from 0 to 1. quantity and box are optional. box coordinates are fractions of the image width and height from the top left corner.
+Maximum 20 products.`
+
+func init() {
+	Register("openai", func(opts Options, logger *zap.Logger) (Recognizer, error) {
+		return NewOpenAIVision(opts, logger), nil
+	})
 }
 
-type RecognizedItems struct {
-	Items []string `json:"items"`
+// OpenAIVision распознает продукты через OpenAI-совместимый API (по умолчанию OpenRouter)
+type OpenAIVision struct {
+	client     *openai.Client
+	executor   *resilience.Executor
+	logger     *zap.Logger
+	model      string
+	maxTokens  int
+	structured bool
 }
 
-func NewOpenAIVision(apiKey string, logger *zap.Logger) *OpenAIVision {
-	// Создаем конфигурацию для OpenRouter вместо OpenAI
-	config := openai.DefaultConfig(apiKey)
-	config.BaseURL = "https://openrouter.ai/api/v1"
+func NewOpenAIVision(opts Options, logger *zap.Logger) *OpenAIVision {
+	config := openai.DefaultConfig(opts.APIKey)
+	config.BaseURL = defaultOpenAIBaseURL
+	if opts.BaseURL != "" {
+		config.BaseURL = opts.BaseURL
+	}
+	config.HTTPClient = resilience.NewHTTPClient(&http.Client{})
+
+	model := opts.Model
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+
+	maxTokens := opts.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
 
 	return &OpenAIVision{
-		client: openai.NewClientWithConfig(config),
-		logger: logger,
+		client:     openai.NewClientWithConfig(config),
+		executor:   resilience.New("vision/openai/"+model, opts.Resilience, logger),
+		logger:     logger,
+		model:      model,
+		maxTokens:  maxTokens,
+		structured: opts.StructuredOutput,
 	}
 }
 
 func (o *OpenAIVision) RecognizeProductsFromImage(ctx context.Context, imageData io.Reader) (*RecognizedItems, error) {
 	data, err := io.ReadAll(imageData)
 	if err != nil {
-		log.Println("Ошибка чтения изображения:", err)
+		o.logger.Error("Ошибка чтения изображения", zap.Error(err))
 		return nil, err
 	}
-	log.Println("Изображение прочитано, размер:", len(data))
 
-	base64Image := base64.StdEncoding.EncodeToString(data)
-	log.Println("Base64-кодирование выполнено, длина строки:", len(base64Image))
+	mimeType, err := DetectImageType(data)
+	if err != nil {
+		return nil, err
+	}
 
-	log.Println("Отправляю запрос в OpenRouter с изображением...")
+	base64Image := base64.StdEncoding.EncodeToString(data)
+	o.logger.Debug("Отправка изображения в модель",
+		zap.String("model", o.model), zap.Int("size", len(data)), zap.Int("base64_length", len(base64Image)))
 
-	// Создаем запрос с дополнительными параметрами для OpenRouter
 	req := openai.ChatCompletionRequest{
-		Model: "qwen/qwen-2.5-vl-7b-instruct:free", // Изменяем модель на Molmo
+		Model: o.model,
 		Messages: []openai.ChatCompletionMessage{
 			{
 				Role: openai.ChatMessageRoleUser,
 				MultiContent: []openai.ChatMessagePart{
 					{
 						Type: openai.ChatMessagePartTypeText,
-						Text: `List all food products in this image. 
-Return only JSON: {"items": ["product1", "product2"]}.
-Maximum 20 products.`,
-						//Промт такой потому, что слишком много продуктов зацикливают нейросеть
+						Text: recognitionPrompt,
 					},
 					{
 						Type: openai.ChatMessagePartTypeImageURL,
 						ImageURL: &openai.ChatMessageImageURL{
-							URL: fmt.Sprintf("data:image/jpeg;base64,%s", base64Image),
+							URL: dataURL(mimeType, base64Image),
 						},
 					},
 				},
 			},
 		},
-		MaxTokens: 300,
+		MaxTokens: o.maxTokens,
+	}
+	if o.structured {
+		req.ResponseFormat = itemsSchema.ResponseFormat()
 	}
 
-	// Проверяем, поддерживает ли используемая версия go-openai дополнительные HTTP заголовки
-	// Если нет, может потребоваться обновить библиотеку или использовать HTTP-клиент напрямую
-
-	resp, err := o.client.CreateChatCompletion(ctx, req)
+	var resp openai.ChatCompletionResponse
+	err = o.executor.Do(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = o.client.CreateChatCompletion(ctx, req)
+		return err
+	})
 
 	if err != nil {
-		log.Println("Ошибка при запросе в OpenRouter:", err)
+		o.logger.Error("Ошибка при запросе к модели", zap.String("model", o.model), zap.Error(err))
 		return nil, err
 	}
-	log.Println("Ответ получен от OpenRouter:", resp)
-
-	content := resp.Choices[0].Message.Content
-	log.Println("Ответ Molmo:", content)
-	jsonStart := strings.Index(content, "{")
-	jsonEnd := strings.LastIndex(content, "}")
-
-	if jsonStart == -1 || jsonEnd == -1 || jsonEnd <= jsonStart {
-		log.Println("JSON не найден в ответе, пытаемся извлечь продукты из текста...")
-		// Если JSON не найден, пытаемся создать его из текста
-		lines := strings.Split(content, "\n")
-		var items []string
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line != "" && !strings.Contains(line, "{") && !strings.Contains(line, "}") {
-				items = append(items, line)
-			}
-		}
-
-		if len(items) > 0 {
-			return &RecognizedItems{Items: items}, nil
-		}
-
-		return nil, fmt.Errorf("нет продуктов на изображении")
-	}
-
-	jsonContent := content[jsonStart : jsonEnd+1]
-	var recognized RecognizedItems
-	if err := json.Unmarshal([]byte(jsonContent), &recognized); err != nil {
-		return nil, err
-	}
-
-	if len(recognized.Items) == 0 {
-		return nil, fmt.Errorf("нет продуктов на изображении")
+	o.logger.Debug("Запрос к модели выполнен",
+		zap.String("id", resp.ID),
+		zap.Int("choices", len(resp.Choices)),
+		zap.Int("prompt_tokens", resp.Usage.PromptTokens),
+		zap.Int("completion_tokens", resp.Usage.CompletionTokens))
+
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("пустой ответ модели")
 	}
 
-	return &recognized, nil
+	return parseRecognizedItems(resp.Choices[0].Message.Content, o.logger)
 }
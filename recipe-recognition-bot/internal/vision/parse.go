@@ -0,0 +1,98 @@
+package vision
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/ingredients"
+	"github.com/TelegramBot/recipe-recognition-bot/internal/llmjson"
+)
+
+// maxItems - сколько продуктов принимается из одного ответа модели
+const maxItems = 20
+
+// itemsSchema описывает ожидаемый ответ модели для structured output
+var itemsSchema = llmjson.MustSchemaFor("recognized_items", RecognizedItems{})
+
+// listMarker - маркер пункта списка: "-", "*", "•", "1.", "1)"
+var listMarker = regexp.MustCompile(`^(?:[-*•]|\d+[.)])\s+`)
+
+// parseRecognizedItems извлекает список продуктов из текстового ответа модели
+func parseRecognizedItems(content string, logger *zap.Logger) (*RecognizedItems, error) {
+	// Ответ модели не логируется целиком: он бывает длинным и может пересказывать содержимое фото
+	logger.Debug("Ответ модели получен", zap.Int("content_length", len(content)))
+
+	var recognized RecognizedItems
+	err := llmjson.Decode(content, &recognized)
+	switch {
+	case err == nil:
+		recognized.Items = cleanItems(recognized.Items)
+	case errors.Is(err, llmjson.ErrNoJSON):
+		logger.Debug("JSON не найден в ответе, извлекаем список продуктов из текста")
+		recognized.Items = parsePlainList(content)
+	default:
+		return nil, fmt.Errorf("некорректный ответ модели: %w", err)
+	}
+
+	if len(recognized.Items) == 0 {
+		return nil, fmt.Errorf("нет продуктов на изображении")
+	}
+	return &recognized, nil
+}
+
+// parsePlainList разбирает ответ без JSON. Продуктами считаются только пункты
+// маркированного или нумерованного списка либо короткий список через запятую;
+// поясняющие фразы модели ("Here are the products:") отбрасываются.
+func parsePlainList(content string) []Item {
+	var items []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if listMarker.MatchString(line) {
+			items = append(items, listMarker.ReplaceAllString(line, ""))
+		}
+	}
+
+	if len(items) == 0 {
+		lines := strings.Split(strings.TrimSpace(content), "\n")
+		if len(lines) == 1 && strings.Contains(lines[0], ",") {
+			items = strings.Split(strings.TrimSuffix(lines[0], "."), ",")
+		}
+	}
+
+	// В простом списке нет ни уверенности, ни рамок - только названия
+	var recognized []Item
+	for _, name := range ingredients.Clean(items, maxItems) {
+		recognized = append(recognized, Item{Name: name})
+	}
+	return recognized
+}
+
+// cleanItems нормализует названия и поля продуктов, убирает пустые, слишком длинные
+// и повторяющиеся элементы
+func cleanItems(items []Item) []Item {
+	seen := make(map[string]bool, len(items))
+	cleaned := make([]Item, 0, len(items))
+
+	for _, item := range items {
+		if strings.HasSuffix(strings.TrimSpace(item.Name), ":") {
+			continue
+		}
+
+		item.Name = ingredients.Normalize(item.Name)
+		key := ingredients.Key(item.Name)
+		if !ingredients.Valid(item.Name) || seen[key] {
+			continue
+		}
+		seen[key] = true
+		item.normalize()
+		cleaned = append(cleaned, item)
+		if len(cleaned) == maxItems {
+			break
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,142 @@
+package vision
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/llmjson"
+)
+
+func TestParseRecognizedItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Item
+		wantErr bool
+	}{
+		{
+			name:    "объекты с уверенностью и количеством",
+			content: `{"items": [{"name": "Молоко", "confidence": 0.9, "quantity": " 1 л "}, {"name": "сыр", "confidence": 85}]}`,
+			want:    []Item{{Name: "молоко", Confidence: 0.9, Quantity: "1 л"}, {Name: "сыр", Confidence: 0.85}},
+		},
+		{
+			name:    "названия строками в блоке кода",
+			content: "```json\n{\"items\": [\"Яйца\", \"мука\",]}\n```",
+			want:    []Item{{Name: "яйца"}, {Name: "мука"}},
+		},
+		{
+			name:    "повторы и пояснения отбрасываются",
+			content: `{"items": ["Продукты:", "молоко", "Молоко", "", "очень длинная фраза вместо названия продукта на фото"]}`,
+			want:    []Item{{Name: "молоко"}},
+		},
+		{
+			name:    "рамка обрезается по границам изображения",
+			content: `{"items": [{"name": "хлеб", "box": {"x": 0.75, "y": -0.25, "width": 0.5, "height": 0.5}}]}`,
+			want:    []Item{{Name: "хлеб", Box: &Box{X: 0.75, Y: 0, Width: 0.25, Height: 0.25}}},
+		},
+		{
+			name:    "вырожденная рамка отбрасывается",
+			content: `{"items": [{"name": "хлеб", "box": {"x": 1.2, "y": 0, "width": 0.5, "height": 0.5}}]}`,
+			want:    []Item{{Name: "хлеб"}},
+		},
+		{
+			name:    "оборванный ответ",
+			content: `{"items": [{"name": "молоко"}, {"name": "творог"`,
+			want:    []Item{{Name: "молоко"}, {Name: "творог"}},
+		},
+		{
+			name:    "маркированный список без JSON",
+			content: "Here are the products:\n- Milk\n- Eggs",
+			want:    []Item{{Name: "milk"}, {Name: "eggs"}},
+		},
+		{
+			name:    "пустой список",
+			content: `{"items": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "нет продуктов",
+			content: "На изображении нет продуктов.",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRecognizedItems(tt.content, zap.NewNop())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseRecognizedItems() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRecognizedItems() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Items, tt.want) {
+				t.Errorf("parseRecognizedItems() = %+v, want %+v", got.Items, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRecognizedItemsInvalidJSON(t *testing.T) {
+	_, err := parseRecognizedItems(`{"items" ["молоко"]}`, zap.NewNop())
+	var syntaxErr *llmjson.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("error = %v, want wrapped *llmjson.SyntaxError", err)
+	}
+}
+
+func TestParsePlainList(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Item
+	}{
+		{
+			name:    "маркированный список",
+			content: "Вот что я вижу:\n- Молоко\n* яйца\n• Сыр",
+			want:    []Item{{Name: "молоко"}, {Name: "яйца"}, {Name: "сыр"}},
+		},
+		{
+			name:    "нумерованный список с количеством",
+			content: "1. 2 яйца\n2) 200 г муки\n3. Сахар.",
+			want:    []Item{{Name: "яйца"}, {Name: "муки"}, {Name: "сахар"}},
+		},
+		{
+			name:    "список через запятую",
+			content: "молоко, хлеб, Масло.",
+			want:    []Item{{Name: "молоко"}, {Name: "хлеб"}, {Name: "масло"}},
+		},
+		{
+			name:    "пояснения без маркеров отбрасываются",
+			content: "На фото продукты.\nМолоко\nХлеб",
+		},
+		{
+			name:    "несколько строк через запятую не считаются списком",
+			content: "Я вижу молоко, хлеб.\nИ ещё сыр, масло.",
+		},
+		{
+			name:    "повторы",
+			content: "- молоко\n- Молоко\n- молоко:",
+			want:    []Item{{Name: "молоко"}},
+		},
+		{
+			name:    "пустой ответ",
+			content: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parsePlainList(tt.content)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePlainList() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
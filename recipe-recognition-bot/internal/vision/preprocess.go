@@ -0,0 +1,60 @@
+package vision
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/imageprep"
+)
+
+// PreprocessingRecognizer подготавливает изображение перед передачей бэкенду:
+// поворачивает по EXIF, уменьшает, перекодирует в JPEG и удаляет метаданные.
+// Меньшее изображение сокращает расход токенов и время ответа модели.
+type PreprocessingRecognizer struct {
+	next   Recognizer
+	opts   imageprep.Options
+	logger *zap.Logger
+}
+
+// NewPreprocessingRecognizer оборачивает бэкенд распознавания подготовкой изображений
+func NewPreprocessingRecognizer(next Recognizer, opts imageprep.Options, logger *zap.Logger) *PreprocessingRecognizer {
+	return &PreprocessingRecognizer{next: next, opts: opts, logger: logger}
+}
+
+func (p *PreprocessingRecognizer) RecognizeProductsFromImage(ctx context.Context, imageData io.Reader) (*RecognizedItems, error) {
+	data, err := io.ReadAll(imageData)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения изображения: %w", err)
+	}
+
+	if _, err := DetectImageType(data); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := imageprep.Process(data, p.opts)
+	switch {
+	case errors.Is(err, imageprep.ErrUndecodable):
+		// Все поддерживаемые форматы декодируются, так что файл поврежден. Передавать его
+		// как есть нельзя: вместе с ним модели ушли бы метаданные, в том числе координаты GPS
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedImage, err)
+	case err != nil:
+		return nil, fmt.Errorf("image preprocessing failed: %w", err)
+	}
+
+	p.logger.Info("Image preprocessed",
+		zap.Int("original_bytes", len(data)),
+		zap.Int("processed_bytes", len(result.Data)),
+		zap.String("original_size", fmt.Sprintf("%dx%d", result.OriginalWidth, result.OriginalHeight)),
+		zap.String("processed_size", fmt.Sprintf("%dx%d", result.Width, result.Height)),
+		zap.Int("orientation", result.Orientation),
+		zap.Duration("duration", time.Since(start)))
+
+	return p.next.RecognizeProductsFromImage(ctx, bytes.NewReader(result.Data))
+}
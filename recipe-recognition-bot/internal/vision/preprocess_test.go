@@ -0,0 +1,58 @@
+package vision
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/imageprep"
+)
+
+// capturingRecognizer запоминает изображение, переданное модели
+type capturingRecognizer struct {
+	data []byte
+}
+
+func (c *capturingRecognizer) RecognizeProductsFromImage(ctx context.Context, imageData io.Reader) (*RecognizedItems, error) {
+	data, err := io.ReadAll(imageData)
+	c.data = data
+	return &RecognizedItems{Items: []Item{{Name: "молоко"}}}, err
+}
+
+func TestPreprocessingRecognizerSendsJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 20, 10))); err != nil {
+		t.Fatal(err)
+	}
+
+	next := &capturingRecognizer{}
+	p := NewPreprocessingRecognizer(next, imageprep.Options{MaxDimension: 10}, zap.NewNop())
+	if _, err := p.RecognizeProductsFromImage(context.Background(), &buf); err != nil {
+		t.Fatalf("RecognizeProductsFromImage() error = %v", err)
+	}
+
+	if mimeType, err := DetectImageType(next.data); err != nil || mimeType != "image/jpeg" {
+		t.Errorf("model received %s (%v), want image/jpeg", mimeType, err)
+	}
+}
+
+func TestPreprocessingRecognizerRejectsUndecodable(t *testing.T) {
+	// Заголовок JPEG без изображения: тип определяется, но декодировать нечего
+	corrupt := append([]byte{0xFF, 0xD8, 0xFF, 0xE1}, []byte("Exif\x00\x00GPS")...)
+
+	next := &capturingRecognizer{}
+	p := NewPreprocessingRecognizer(next, imageprep.Options{}, zap.NewNop())
+	_, err := p.RecognizeProductsFromImage(context.Background(), bytes.NewReader(corrupt))
+	if !errors.Is(err, ErrUnsupportedImage) {
+		t.Errorf("error = %v, want ErrUnsupportedImage", err)
+	}
+	if next.data != nil {
+		t.Error("undecodable image was passed to the model as is")
+	}
+}
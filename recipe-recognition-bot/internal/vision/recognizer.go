@@ -0,0 +1,75 @@
+package vision
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/TelegramBot/recipe-recognition-bot/internal/resilience"
+)
+
+// Recognizer распознает продукты на изображении
+type Recognizer interface {
+	RecognizeProductsFromImage(ctx context.Context, imageData io.Reader) (*RecognizedItems, error)
+}
+
+// Options содержит параметры подключения к бэкенду распознавания.
+// Пустые значения заменяются значениями по умолчанию конкретного бэкенда.
+type Options struct {
+	APIKey       string
+	BaseURL      string
+	Model        string
+	MaxTokens    int
+	FixtureItems []string
+	Resilience   resilience.Policy
+	// StructuredOutput включает передачу JSON-схемы ответа, если провайдер это поддерживает
+	StructuredOutput bool
+}
+
+// Factory создает бэкенд распознавания по параметрам
+type Factory func(opts Options, logger *zap.Logger) (Recognizer, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register регистрирует бэкенд распознавания под указанным именем
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("vision: backend %q already registered", name))
+	}
+	registry[name] = factory
+}
+
+// Providers возвращает отсортированный список зарегистрированных бэкендов
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New создает бэкенд распознавания по имени провайдера
+func New(provider string, opts Options, logger *zap.Logger) (Recognizer, error) {
+	registryMu.RLock()
+	factory, ok := registry[provider]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown vision provider %q (available: %v)", provider, Providers())
+	}
+	return factory(opts, logger)
+}
@@ -0,0 +1 @@
+ALTER TABLE recipe_bot.recipes DROP COLUMN IF EXISTS model;
@@ -0,0 +1,2 @@
+-- Модель, сгенерировавшая рецепт
+ALTER TABLE recipe_bot.recipes ADD COLUMN IF NOT EXISTS model TEXT;
@@ -0,0 +1 @@
+COMMENT ON COLUMN recipe_bot.recipes.ingredients IS NULL;
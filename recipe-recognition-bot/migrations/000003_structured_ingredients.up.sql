@@ -0,0 +1,2 @@
+-- ingredients теперь хранит объекты {name, quantity, unit, optional}
+COMMENT ON COLUMN recipe_bot.recipes.ingredients IS 'Список ингредиентов [{name, quantity, unit, optional}]';
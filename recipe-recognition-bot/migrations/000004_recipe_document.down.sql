@@ -0,0 +1,47 @@
+DROP INDEX IF EXISTS recipe_bot.idx_recipes_missing_document;
+
+-- Рецепты, сохраненные только документом, снова получают текст в формате FormatRecipe,
+-- чтобы после отката они показывались целиком
+UPDATE recipe_bot.recipes AS r
+SET recipe_content = concat_ws(E'\n',
+    '🍳 *' || r.recipe_title || '*',
+    '',
+    '🍽 Порций: ' || NULLIF(r.document->>'servings', '0'),
+    '🔪 Подготовка: ' || NULLIF(r.document->>'prep_time_minutes', '0') || ' мин',
+    '🔥 Готовка: ' || NULLIF(r.document->>'cook_time_minutes', '0') || ' мин',
+    '📊 Сложность: ' || CASE r.document->>'difficulty'
+        WHEN 'easy' THEN 'легко'
+        WHEN 'medium' THEN 'средне'
+        WHEN 'hard' THEN 'сложно'
+    END,
+    '🌍 Кухня: ' || NULLIF(r.document->>'cuisine', ''),
+    '⚡ Калорийность: ' || NULLIF(r.document->>'calories_per_serving', '0') || ' ккал на порцию',
+    '',
+    '*Ингредиенты:*',
+    (
+        SELECT string_agg(concat(
+            i.n, '. ', i.item->>'name',
+            ' — ' || NULLIF(trim(concat(replace(i.item->>'quantity', '.', ','), ' ', i.item->>'unit')), ''),
+            CASE WHEN (i.item->>'optional')::boolean THEN ' (по желанию)' END,
+            CASE WHEN (i.item->>'pantry')::boolean THEN ' 🏠' END
+        ), E'\n' ORDER BY i.n)
+        FROM jsonb_array_elements(CASE jsonb_typeof(r.document->'ingredients')
+            WHEN 'array' THEN r.document->'ingredients' ELSE '[]' END) WITH ORDINALITY AS i(item, n)
+    ),
+    '',
+    '*Приготовление:*',
+    (
+        SELECT string_agg(concat(
+            s.n, '. ', s.step->>'text',
+            ' (⏱ ' || NULLIF(s.step->>'duration_minutes', '0') || ' мин)'
+        ), E'\n' ORDER BY s.n)
+        FROM jsonb_array_elements(CASE jsonb_typeof(r.document->'steps')
+            WHEN 'array' THEN r.document->'steps' ELSE '[]' END) WITH ORDINALITY AS s(step, n)
+    )
+)
+WHERE r.recipe_content IS NULL AND r.document IS NOT NULL;
+
+ALTER TABLE recipe_bot.recipes
+    ALTER COLUMN recipe_content SET NOT NULL,
+    DROP COLUMN IF EXISTS schema_version,
+    DROP COLUMN IF EXISTS document;
@@ -0,0 +1,8 @@
+-- Полный структурированный документ рецепта; Markdown формируется при чтении
+ALTER TABLE recipe_bot.recipes
+    ADD COLUMN IF NOT EXISTS document JSONB,
+    ADD COLUMN IF NOT EXISTS schema_version INT NOT NULL DEFAULT 0,
+    ALTER COLUMN recipe_content DROP NOT NULL;
+
+-- Старые записи без документа заполняются командой backfill-recipes разбором recipe_content
+CREATE INDEX IF NOT EXISTS idx_recipes_missing_document ON recipe_bot.recipes(id) WHERE document IS NULL;
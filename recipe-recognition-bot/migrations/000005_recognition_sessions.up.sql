@@ -0,0 +1,8 @@
+-- Последний распознанный набор продуктов пользователя и уже предложенные по нему блюда
+CREATE TABLE IF NOT EXISTS recipe_bot.recognition_sessions (
+    user_id INT PRIMARY KEY REFERENCES recipe_bot.users(id) ON DELETE CASCADE,
+    items JSONB NOT NULL, -- список продуктов ["яйца", "сыр"]
+    suggested_titles JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+-- Состояние диалога в чате: редактируемый список продуктов и ожидаемый ввод
+CREATE TABLE IF NOT EXISTS recipe_bot.chat_states (
+    chat_id BIGINT PRIMARY KEY,
+    state TEXT NOT NULL DEFAULT '', -- editing_products, awaiting_product
+    products JSONB NOT NULL DEFAULT '[]'::jsonb, -- [{"name": "яйца", "selected": true}]
+    message_id INT NOT NULL DEFAULT 0, -- сообщение со списком продуктов и кнопками
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
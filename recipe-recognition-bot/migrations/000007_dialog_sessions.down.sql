@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS recipe_bot.chat_states (
+    chat_id BIGINT PRIMARY KEY,
+    state TEXT NOT NULL DEFAULT '',
+    products JSONB NOT NULL DEFAULT '[]'::jsonb,
+    message_id INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO recipe_bot.chat_states (chat_id, state, products, message_id, updated_at)
+SELECT
+    chat_id,
+    CASE state WHEN 'awaiting_product' THEN 'awaiting_product' ELSE 'editing_products' END,
+    COALESCE(data->'products', '[]'::jsonb),
+    COALESCE((data->>'message_id')::int, 0),
+    updated_at
+FROM recipe_bot.dialog_sessions
+WHERE flow = 'products';
+
+DROP TABLE IF EXISTS recipe_bot.dialog_sessions;
@@ -0,0 +1,24 @@
+-- Диалоги чатов: активный сценарий, его состояние и данные
+CREATE TABLE IF NOT EXISTS recipe_bot.dialog_sessions (
+    chat_id BIGINT PRIMARY KEY,
+    flow TEXT NOT NULL, -- имя сценария: products, preferences, ...
+    state TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}'::jsonb, -- данные сценария
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dialog_sessions_expires_at ON recipe_bot.dialog_sessions(expires_at);
+
+-- Переносим незавершенную правку списков продуктов в сценарий products
+INSERT INTO recipe_bot.dialog_sessions (chat_id, flow, state, data, expires_at)
+SELECT
+    chat_id,
+    'products',
+    CASE state WHEN 'awaiting_product' THEN 'awaiting_product' ELSE 'editing' END,
+    jsonb_build_object('products', products, 'message_id', message_id),
+    COALESCE(updated_at, NOW()) + INTERVAL '1 day'
+FROM recipe_bot.chat_states
+ON CONFLICT (chat_id) DO NOTHING;
+
+DROP TABLE IF EXISTS recipe_bot.chat_states;
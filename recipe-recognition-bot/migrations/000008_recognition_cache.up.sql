@@ -0,0 +1,12 @@
+-- Кэш распознавания фото по перцептивному хешу изображения
+CREATE TABLE IF NOT EXISTS recipe_bot.recognition_cache (
+    id BIGSERIAL PRIMARY KEY,
+    image_hash BIGINT NOT NULL, -- dHash изображения, 64 бита
+    items JSONB NOT NULL, -- распознанные продукты [{name, confidence, quantity, box}]
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_recognition_cache_expires_at ON recipe_bot.recognition_cache(expires_at);
+-- Повторно присланное фото находится по точному совпадению хеша без перебора таблицы
+CREATE INDEX IF NOT EXISTS idx_recognition_cache_image_hash ON recipe_bot.recognition_cache(image_hash);
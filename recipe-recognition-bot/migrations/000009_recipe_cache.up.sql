@@ -0,0 +1,12 @@
+-- Кэш сгенерированных рецептов по нормализованному набору продуктов
+CREATE TABLE IF NOT EXISTS recipe_bot.recipe_cache (
+    id BIGSERIAL PRIMARY KEY,
+    cache_key TEXT NOT NULL, -- sha256 набора продуктов, версии документа и предпочтений
+    document JSONB NOT NULL,
+    model TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_cache_key ON recipe_bot.recipe_cache(cache_key, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_recipe_cache_expires_at ON recipe_bot.recipe_cache(expires_at);
@@ -0,0 +1,8 @@
+-- Справочник упакованных товаров по штрихкодам (импортируется из Open Food Facts)
+CREATE TABLE IF NOT EXISTS recipe_bot.products (
+    barcode TEXT PRIMARY KEY, -- EAN-13; UPC-A дополняется ведущим нулем
+    name TEXT NOT NULL, -- название для рецептов: "молоко"
+    full_name TEXT NOT NULL DEFAULT '', -- название с упаковки
+    brand TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+-- Ограничения питания пользователя, учитываемые при генерации рецептов
+CREATE TABLE IF NOT EXISTS recipe_bot.user_preferences (
+    user_id INT PRIMARY KEY REFERENCES recipe_bot.users(id) ON DELETE CASCADE,
+    diets TEXT[] NOT NULL DEFAULT '{}', -- vegetarian, vegan, halal, lactose_free, gluten_free
+    allergens TEXT[] NOT NULL DEFAULT '{}', -- nuts, peanuts, milk, eggs, fish, shellfish, soy, sesame
+    max_calories INT NOT NULL DEFAULT 0, -- ккал на порцию; 0 - без ограничения
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
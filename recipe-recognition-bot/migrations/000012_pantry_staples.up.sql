@@ -0,0 +1,7 @@
+-- Запасы, которые всегда есть у пользователя и доступны для любого рецепта: соль, масло, вода
+CREATE TABLE IF NOT EXISTS recipe_bot.pantry_staples (
+    user_id INT NOT NULL REFERENCES recipe_bot.users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL, -- нормализованное название: "масло растительное"
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, name)
+);
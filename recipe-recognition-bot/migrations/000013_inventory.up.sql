@@ -0,0 +1,10 @@
+-- Продукты, которые есть у пользователя дома: пополняются подтвержденными распознаваниями фото
+CREATE TABLE IF NOT EXISTS recipe_bot.inventory_items (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES recipe_bot.users(id) ON DELETE CASCADE,
+    item_key TEXT NOT NULL, -- ключ сравнения: одинаковый для "яйца" и "яйцо"
+    name TEXT NOT NULL,
+    quantity TEXT NOT NULL DEFAULT '', -- оценка количества: "6 шт", "около 500 г"
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, item_key)
+);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS recipe_bot.expiry_reminders;
+
+DROP INDEX IF EXISTS recipe_bot.idx_inventory_items_expires_at;
+
+ALTER TABLE recipe_bot.inventory_items
+    DROP COLUMN IF EXISTS expiry_estimated,
+    DROP COLUMN IF EXISTS expires_at;
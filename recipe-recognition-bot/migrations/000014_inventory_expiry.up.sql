@@ -0,0 +1,13 @@
+-- Срок годности продуктов, которые есть дома
+ALTER TABLE recipe_bot.inventory_items
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE, -- NULL - срок неизвестен
+    ADD COLUMN IF NOT EXISTS expiry_estimated BOOLEAN NOT NULL DEFAULT FALSE; -- срок оценен по категории продукта, а не указан пользователем
+
+CREATE INDEX IF NOT EXISTS idx_inventory_items_expires_at ON recipe_bot.inventory_items(expires_at);
+
+-- Когда пользователю последний раз напоминали о продуктах с истекающим сроком:
+-- напоминание отправляется не чаще раза в день, в том числе после перезапуска бота
+CREATE TABLE IF NOT EXISTS recipe_bot.expiry_reminders (
+    user_id INT PRIMARY KEY REFERENCES recipe_bot.users(id) ON DELETE CASCADE,
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,11 @@
+-- Список покупок пользователя: недостающие продукты из сохраненных рецептов
+CREATE TABLE IF NOT EXISTS recipe_bot.shopping_items (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES recipe_bot.users(id) ON DELETE CASCADE,
+    item_key TEXT NOT NULL, -- ключ сравнения: одинаковый для "яйца" и "яйцо"
+    name TEXT NOT NULL,
+    quantity TEXT NOT NULL DEFAULT '', -- сумма количеств из рецептов: "1,5 л", "2 шт + 200 г"
+    checked BOOLEAN NOT NULL DEFAULT FALSE, -- продукт куплен
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, item_key)
+);
@@ -0,0 +1,8 @@
+-- Рецепты, добавленные в текущий список покупок: повторное нажатие кнопки не удваивает количества.
+-- Когда в списке не остается некупленных продуктов, отметки сбрасываются.
+CREATE TABLE IF NOT EXISTS recipe_bot.shopping_recipes (
+    user_id INT NOT NULL REFERENCES recipe_bot.users(id) ON DELETE CASCADE,
+    recipe_id INT NOT NULL REFERENCES recipe_bot.recipes(id) ON DELETE CASCADE,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, recipe_id)
+);